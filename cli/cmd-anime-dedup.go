@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"path"
@@ -13,7 +14,7 @@ import (
 	"github.com/katbyte/go-ingest-media/lib/ktio"
 )
 
-func FindAndCombineAnime(animeLib, stdLib *content.Library, libType content.LibraryType) error {
+func FindAndCombineAnime(ctx context.Context, animeLib, stdLib *content.Library, libType content.LibraryType) error {
 	f := GetFlags()
 
 	// Load Anime Folders
@@ -122,12 +123,12 @@ func FindAndCombineAnime(animeLib, stdLib *content.Library, libType content.Libr
 			}
 
 			if selection == 'c' {
-				stdVideos, errA := content.VideosInPath(dup.std.Path())
+				stdVideos, errA := content.VideosInPath(ctx, dup.std.Path())
 				if errA != nil {
 					c.Printf("   <red>Error loading videos A:</> %v\n", errA)
 					continue
 				}
-				animeVideos, errB := content.VideosInPath(dup.anime.Path())
+				animeVideos, errB := content.VideosInPath(ctx, dup.anime.Path())
 				if errB != nil {
 					c.Printf("   <red>Error loading videos B:</> %v\n", errB)
 					continue
@@ -146,7 +147,7 @@ func FindAndCombineAnime(animeLib, stdLib *content.Library, libType content.Libr
 				}
 
 				if len(videos) > 0 {
-					RenderVideoComparisonTable(4, headers, videos)
+					RenderVideoComparisonTable(ctx, 4, headers, videos, animeLib.Languages)
 				} else {
 					c.Printf("   <red>No videos found to compare.</>\n")
 				}
@@ -160,7 +161,7 @@ func FindAndCombineAnime(animeLib, stdLib *content.Library, libType content.Libr
 					break
 				}
 				destPath := filepath.Join(animeLib.Path, dup.anime.Folder)
-				if err := ktio.RunCommand(4, f.Prompt, "mv", "-v", dup.std.Path(), destPath); err != nil {
+				if err := ktio.Move(4, f.Prompt, dup.std.Path(), destPath); err != nil {
 					c.Printf("  <red>ERROR:</> moving standard folder: %s\n", err)
 				}
 				break
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	c "github.com/gookit/color"
+	"github.com/katbyte/go-ingest-media/lib/content"
+	"github.com/katbyte/go-ingest-media/lib/ktio"
+)
+
+// AuditLanguages lists the videos in a library that are missing any of its required audio or subtitle languages
+func AuditLanguages(ctx context.Context, libName string) error {
+	lib, err := content.LibraryFor(libName)
+	if err != nil {
+		return err
+	}
+	if lib.Languages.IsEmpty() {
+		return fmt.Errorf("library %q has no language requirements in the config", libName)
+	}
+
+	c.Printf("<white>%s</> <darkGray>(%s)</>\n", lib.Path, lib.Languages)
+
+	sb := ktio.NewStatusBar()
+	defer sb.Close()
+	defer showProbeProgress(sb)()
+
+	contents, err := lib.Contents(func(folder string, err error) {
+		c.Printf("  <red>ERROR:</> %s: %s\n", folder, err)
+	})
+	if err != nil {
+		return err
+	}
+
+	checked, failed := 0, 0
+	for _, item := range contents {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var folder string
+		var videos []content.VideoFile
+		switch i := item.(type) {
+		case *content.Movie:
+			folder = i.Folder
+			if err := i.LoadVideos(ctx); err != nil {
+				c.Printf("  <red>ERROR:</> %s: %s\n", folder, err)
+				continue
+			}
+			videos = i.Videos
+		case *content.Series:
+			folder = i.Folder
+			if err := i.LoadSeasons(ctx); err != nil {
+				c.Printf("  <red>ERROR:</> %s: %s\n", folder, err)
+				continue
+			}
+			videos = seriesVideos(i)
+		}
+
+		printed := false
+		for _, v := range videos {
+			checked++
+			check := lib.Languages.Check(v)
+			if check.Passed() {
+				continue
+			}
+
+			failed++
+			if !printed {
+				c.Printf("  <yellow>%s</>\n", folder)
+				printed = true
+			}
+			c.Printf("    %s <red>%s</> <darkGray>(score %s)</>\n", filepath.Base(v.Path), check, v.Score())
+		}
+	}
+
+	c.Printf("checked <cyan>%d</> videos, <red>%d</> failed\n", checked, failed)
+	return nil
+}
+
+// seriesVideos returns all episode videos of a series ordered by season and episode
+func seriesVideos(s *content.Series) []content.VideoFile {
+	seasonNums := make([]int, 0, len(s.Seasons))
+	for n := range s.Seasons {
+		seasonNums = append(seasonNums, n)
+	}
+	sort.Ints(seasonNums)
+
+	var videos []content.VideoFile
+	for _, sn := range seasonNums {
+		season := s.Seasons[sn]
+
+		// multi episode files are in the map once per episode
+		seen := map[*content.Episode]bool{}
+		epNums := make([]int, 0, len(season.Episodes))
+		for n := range season.Episodes {
+			epNums = append(epNums, n)
+		}
+		sort.Ints(epNums)
+
+		for _, en := range epNums {
+			ep := season.Episodes[en]
+			if seen[ep] {
+				continue
+			}
+			seen[ep] = true
+			videos = append(videos, ep.Videos...)
+		}
+	}
+	return videos
+}
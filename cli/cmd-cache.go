@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"time"
+
+	c "github.com/gookit/color"
+	"github.com/katbyte/go-ingest-media/lib/content"
+	"github.com/katbyte/go-ingest-media/lib/ktio"
+	"github.com/katbyte/go-ingest-media/lib/probecache"
+)
+
+// probeCacheDB is the open ffprobe cache, nil when disabled or it could not be opened
+var probeCacheDB *probecache.Cache
+
+// probeCachePath returns the configured cache path or the default one
+func probeCachePath() (string, error) {
+	if p := GetFlags().ProbeCache; p != "" {
+		return p, nil
+	}
+	return probecache.DefaultPath()
+}
+
+// startProbeCache sets up the ffprobe cache so unchanged videos are not probed again. The database is only opened when
+// something is probed, a failure to open it only disables caching
+func startProbeCache() {
+	if GetFlags().NoProbeCache {
+		return
+	}
+
+	path, err := probeCachePath()
+	if err != nil {
+		c.Printf("<yellow>WARNING:</> ffprobe cache unavailable, every video will be probed: %s\n", err)
+		return
+	}
+
+	probeCacheDB = probecache.New(path)
+	content.SetProbeCache(probeCacheDB)
+}
+
+// closeProbeCache closes the ffprobe cache if it was opened
+func closeProbeCache() {
+	if probeCacheDB != nil {
+		content.SetProbeCache(nil)
+		probeCacheDB.Close()
+		probeCacheDB = nil
+	}
+}
+
+func requireProbeCache() (*probecache.Cache, error) {
+	if probeCacheDB == nil {
+		return nil, errors.New("ffprobe cache is disabled")
+	}
+	if err := probeCacheDB.Open(); err != nil {
+		return nil, err
+	}
+	return probeCacheDB, nil
+}
+
+// CacheStats prints a summary of the ffprobe cache
+func CacheStats() error {
+	pc, err := requireProbeCache()
+	if err != nil {
+		return err
+	}
+
+	s, err := pc.Stats()
+	if err != nil {
+		return err
+	}
+
+	c.Printf("<white>%s</>\n", s.Path)
+	c.Printf("  entries:  <cyan>%d</>\n", s.Entries)
+	c.Printf("  videos:   <cyan>%s</>\n", ktio.FormatBytes(s.FileBytes))
+	c.Printf("  database: <cyan>%s</>\n", ktio.FormatBytes(s.DBBytes))
+	if s.Entries > 0 {
+		c.Printf("  oldest:   <darkGray>%s</>\n", s.Oldest.Local().Format("2006-01-02 15:04"))
+		c.Printf("  newest:   <darkGray>%s</>\n", s.Newest.Local().Format("2006-01-02 15:04"))
+	}
+
+	return nil
+}
+
+// PruneCache removes cache entries for videos that were deleted or have changed
+func PruneCache() error {
+	pc, err := requireProbeCache()
+	if err != nil {
+		return err
+	}
+
+	n, err := pc.Prune()
+	if err != nil {
+		return err
+	}
+
+	c.Printf("pruned <cyan>%d</> stale entries\n", n)
+	return nil
+}
+
+// WarmCache probes every video in a library so later runs are answered from the cache
+func WarmCache(ctx context.Context, libName string, workers int) error {
+	pc, err := requireProbeCache()
+	if err != nil {
+		return err
+	}
+
+	lib, err := content.LibraryFor(libName)
+	if err != nil {
+		return err
+	}
+
+	var videos []string
+	err = filepath.WalkDir(lib.Path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			c.Printf("  <red>ERROR:</> %s\n", err)
+			return nil
+		}
+		if !d.IsDir() && content.IsVideoFile(path) {
+			videos = append(videos, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error scanning %s: %w", lib.Path, err)
+	}
+
+	c.Printf("<white>%s</> <darkGray>(%d videos)</>\n", lib.Path, len(videos))
+
+	// --workers overrides the probe workers for this run, the probe-limits for slow mounts still apply
+	if workers > 0 {
+		prev := content.Scheduler()
+		content.SetProbeScheduler(prev.WithWorkers(workers))
+		defer content.SetProbeScheduler(prev)
+	}
+
+	sb := ktio.NewStatusBar()
+	defer sb.Close()
+	defer showProbeProgress(sb)()
+
+	hits, misses := pc.Hits(), pc.Misses()
+	start := time.Now()
+
+	// an unreadable video is reported and skipped rather than stopping the warm
+	failed := 0
+	_, errs := content.Scheduler().ProbeEach(ctx, videos)
+	for i, err := range errs {
+		if err != nil {
+			failed++
+			c.Printf("  <red>ERROR:</> %s: %s\n", videos[i], err)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	c.Printf("  <green>%d</> already cached, <cyan>%d</> probed in %s\n", pc.Hits()-hits, pc.Misses()-misses, time.Since(start).Truncate(time.Second))
+	if failed > 0 {
+		return fmt.Errorf("%d of %d videos could not be read", failed, len(videos))
+	}
+	return nil
+}
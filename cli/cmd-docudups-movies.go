@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"path"
@@ -16,7 +17,7 @@ import (
 // FindAndCombineDocu scans the documentary library and checks if each documentary
 // also exists in the movies library. For duplicates, it compares videos and asks
 // the user to choose which to keep, or to move the movie copy to the documentary folder.
-func FindAndCombineDocu(docuLibrary, movieLibrary *content.Library) error {
+func FindAndCombineDocu(ctx context.Context, docuLibrary, movieLibrary *content.Library) error {
 	f := GetFlags()
 
 	// Get documentaries using Movies() helper
@@ -69,12 +70,12 @@ func FindAndCombineDocu(docuLibrary, movieLibrary *content.Library) error {
 		c.Printf("  <magenta>MOVIE:</> %s\n", movieEntry.Path())
 
 		// Load video info for both
-		if err := docuEntry.LoadVideos(); err != nil {
+		if err := docuEntry.LoadVideos(ctx); err != nil {
 			c.Printf("  <red>ERROR:</> loading docu videos: %s\n", err)
 			continue
 		}
 
-		if err := movieEntry.LoadVideos(); err != nil {
+		if err := movieEntry.LoadVideos(ctx); err != nil {
 			c.Printf("  <red>ERROR:</> loading movie videos: %s\n", err)
 			continue
 		}
@@ -110,7 +111,7 @@ func FindAndCombineDocu(docuLibrary, movieLibrary *content.Library) error {
 			}
 
 			if len(videos) > 0 {
-				RenderVideoComparisonTable(4, headers, videos)
+				RenderVideoComparisonTable(ctx, 4, headers, videos, docuLibrary.Languages)
 			}
 		}
 
@@ -141,7 +142,7 @@ func FindAndCombineDocu(docuLibrary, movieLibrary *content.Library) error {
 			// Move movie to documentary folder
 			destPath := filepath.Join(docuLibrary.Path, docuName)
 			c.Printf("  <magenta>Moving movie to documentary folder...</>\n")
-			if err := ktio.RunCommand(4, f.Prompt, "mv", "-v", movieEntry.Path(), destPath); err != nil {
+			if err := ktio.Move(4, f.Prompt, movieEntry.Path(), destPath); err != nil {
 				c.Printf("  <red>ERROR:</> moving movie folder: %s\n", err)
 			}
 
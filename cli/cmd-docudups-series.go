@@ -1,9 +1,9 @@
 package cli
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"os"
 	"path"
 	"sort"
 	"strings"
@@ -58,7 +58,7 @@ func printSeriesPaths(srcPath, destPath, srcColor, destColor string) {
 // FindAndCombineDocuSeries scans the docuseries library and checks if each series
 // also exists in the TV library. For duplicates, it compares seasons/episodes and
 // processes episode-by-episode to determine which to keep.
-func FindAndCombineDocuSeries(docuseriesLibrary, tvLibrary *content.Library) error {
+func FindAndCombineDocuSeries(ctx context.Context, docuseriesLibrary, tvLibrary *content.Library) error {
 	// Get docuseries using Series() helper
 	docuSeriesList, err := docuseriesLibrary.Series(func(folder string, err error) {
 		c.Printf("  %s --> <red>ERROR:</>: %s\n", path.Base(folder), err)
@@ -108,12 +108,12 @@ func FindAndCombineDocuSeries(docuseriesLibrary, tvLibrary *content.Library) err
 		printSeriesPaths(tvEntry.Path(), docuEntry.Path(), "cyan", "magenta")
 
 		// Load seasons for both
-		if err := docuEntry.LoadSeasons(); err != nil {
+		if err := docuEntry.LoadSeasons(ctx); err != nil {
 			c.Printf("  <red>ERROR:</> loading docuseries seasons: %s\n", err)
 			continue
 		}
 
-		if err := tvEntry.LoadSeasons(); err != nil {
+		if err := tvEntry.LoadSeasons(ctx); err != nil {
 			c.Printf("  <red>ERROR:</> loading tv seasons: %s\n", err)
 			continue
 		}
@@ -125,7 +125,7 @@ func FindAndCombineDocuSeries(docuseriesLibrary, tvLibrary *content.Library) err
 		c.Printf("  <darkGray>Docuseries: %d seasons, TV: %d seasons</>\n", docuSeasonCount, tvSeasonCount)
 
 		// Process episode by episode
-		if processSeriesEpisodes(docuEntry, tvEntry) {
+		if processSeriesEpisodes(ctx, docuEntry, tvEntry) {
 			return errors.New("exiting")
 		}
 
@@ -144,7 +144,7 @@ func FindAndCombineDocuSeries(docuseriesLibrary, tvLibrary *content.Library) err
 
 // processSeriesEpisodes goes through episodes one by one and handles duplicates
 // Returns true if user chose to quit
-func processSeriesEpisodes(docu, tv *content.Series) bool {
+func processSeriesEpisodes(ctx context.Context, docu, tv *content.Series) bool {
 	f := GetFlags()
 
 	// Get all unique season numbers from both
@@ -170,7 +170,7 @@ func processSeriesEpisodes(docu, tv *content.Series) bool {
 		if !docuExists && tvExists {
 			c.Printf("    season <magenta>%d</> - only in TV (%d eps) - moving to docuseries\n", seasonNum, len(tvSeason.Episodes))
 			destPath := docu.Path() + "/"
-			if err := ktio.RunCommand(6, f.Prompt, "mv", "-v", tvSeason.Path, destPath); err != nil {
+			if err := ktio.Move(6, f.Prompt, tvSeason.Path, destPath); err != nil {
 				c.Printf("      <red>ERROR:</> moving TV season: %s\n", err)
 			}
 			continue
@@ -212,13 +212,13 @@ func processSeriesEpisodes(docu, tv *content.Series) bool {
 			if !docuEpExists {
 				c.Printf("      S%02dE%02d: <magenta>TV only</> - moving to docuseries\n", seasonNum, epNum)
 				// Ensure the destination season folder exists
-				if err := os.MkdirAll(docuSeason.Path, 0o750); err != nil {
+				if err := ktio.MkdirAll(docuSeason.Path, 0o750); err != nil {
 					c.Printf("        <red>ERROR:</> creating season folder: %s\n", err)
 					continue
 				}
 				for _, v := range tvEp.Videos {
 					destPath := docuSeason.Path + "/"
-					if err := ktio.RunCommand(8, f.Prompt, "mv", "-v", v.Path, destPath); err != nil {
+					if err := ktio.Move(8, f.Prompt, v.Path, destPath); err != nil {
 						c.Printf("        <red>ERROR:</> moving TV video: %s\n", err)
 					}
 				}
@@ -236,7 +236,7 @@ func processSeriesEpisodes(docu, tv *content.Series) bool {
 				if docuEp.Videos[0].IsBasicallyTheSameTo(tvEp.Videos[0]) {
 					c.Printf("      S%02dE%02d: <green>SAME</> - deleting TV version\n", seasonNum, epNum)
 					for _, v := range tvEp.Videos {
-						if err := ktio.RunCommand(8, f.Prompt, "rm", "-v", v.Path); err != nil {
+						if err := ktio.Remove(8, f.Prompt, v.Path); err != nil {
 							c.Printf("        <red>ERROR:</> deleting TV video: %s\n", err)
 						}
 					}
@@ -260,7 +260,7 @@ func processSeriesEpisodes(docu, tv *content.Series) bool {
 			}
 
 			if len(videos) > 0 {
-				RenderVideoComparisonTable(8, headers, videos)
+				RenderVideoComparisonTable(ctx, 8, headers, videos, docu.Library.Languages)
 			}
 
 			// Ask what to do
@@ -276,20 +276,20 @@ func processSeriesEpisodes(docu, tv *content.Series) bool {
 			case 'd':
 				// Delete TV version
 				for _, v := range tvEp.Videos {
-					if err := ktio.RunCommand(8, f.Prompt, "rm", "-v", v.Path); err != nil {
+					if err := ktio.Remove(8, f.Prompt, v.Path); err != nil {
 						c.Printf("        <red>ERROR:</> deleting TV video: %s\n", err)
 					}
 				}
 			case 't':
 				// Delete docuseries version and move TV to docu
 				for _, v := range docuEp.Videos {
-					if err := ktio.RunCommand(8, f.Prompt, "rm", "-v", v.Path); err != nil {
+					if err := ktio.Remove(8, f.Prompt, v.Path); err != nil {
 						c.Printf("        <red>ERROR:</> deleting docu video: %s\n", err)
 					}
 				}
 				for _, v := range tvEp.Videos {
 					destPath := docuSeason.Path + "/"
-					if err := ktio.RunCommand(8, f.Prompt, "mv", "-v", v.Path, destPath); err != nil {
+					if err := ktio.Move(8, f.Prompt, v.Path, destPath); err != nil {
 						c.Printf("        <red>ERROR:</> moving TV video: %s\n", err)
 					}
 				}
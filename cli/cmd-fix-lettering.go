@@ -3,8 +3,10 @@ package cli
 import (
 	"fmt"
 	"path/filepath"
+	"sort"
 	"sync"
 	"sync/atomic"
+	"unicode/utf8"
 
 	c "github.com/gookit/color"
 	"github.com/katbyte/go-ingest-media/lib/content"
@@ -29,9 +31,15 @@ func findMislettered(lib *content.Library, sb *ktio.StatusBar) []wrongItem {
 		return nil
 	}
 
+	// letter folders are single letters or the library's digits and symbols folders
+	buckets := map[string]bool{}
+	for _, b := range lib.LetterBuckets() {
+		buckets[b] = true
+	}
+
 	var letterFolders []string
 	for _, f := range folders {
-		if len(filepath.Base(f)) <= 1 {
+		if name := filepath.Base(f); utf8.RuneCountInString(name) <= 1 || buckets[name] {
 			letterFolders = append(letterFolders, f)
 		}
 	}
@@ -57,7 +65,7 @@ func findMislettered(lib *content.Library, sb *ktio.StatusBar) []wrongItem {
 
 				for _, lf := range subFolders {
 					folderName := filepath.Base(lf)
-					expectedLetter := content.GetLetter(folderName)
+					expectedLetter := lib.LetterFor(folderName)
 
 					if expectedLetter != letter {
 						foundCount.Add(1)
@@ -89,6 +97,11 @@ func findMislettered(lib *content.Library, sb *ktio.StatusBar) []wrongItem {
 		items = append(items, item)
 	}
 
+	// the workers finish in any order, sorted so the prompts are always in the same order
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].actualPath < items[j].actualPath
+	})
+
 	sb.UpdateScan(c.Sprintf("<green>scan complete</> <darkGray>(found %d misplaced folders)</>", len(items)))
 	return items
 }
@@ -116,10 +129,11 @@ func FixLettering(sourceLib, destLib *content.Library, sb *ktio.StatusBar) error
 		c.Printf("<darkGray>[%d/%d]</> <yellow>%s</> is in <red>%s</> but should be in <green>%s</>\n", i+1, totalItems, item.folderName, item.actualLetter, item.expectedLetter)
 
 		destPath := filepath.Join(destLib.Path, item.folderName)
-		c.Printf("    moving to <lightBlue>%s</>\n", destPath)
+		reletterPath := filepath.Join(sourceLib.Path, item.expectedLetter, item.folderName)
+		c.Printf("    moving to <lightBlue>%s</> or re-lettering to <lightBlue>%s</>\n", destPath, reletterPath)
 
-		c.Printf("    [m]ove/[a]ccept | [s]kip | e[x]it: ")
-		selection, err := ktio.GetSelection('m', 'a', 's', 'x')
+		c.Printf("    [m]ove/[a]ccept | [r]e-letter | [s]kip | e[x]it: ")
+		selection, err := ktio.GetSelection('m', 'a', 'r', 's', 'x')
 		fmt.Println()
 		if err != nil {
 			c.Printf("    <red>ERROR:</> %s\n", err)
@@ -127,6 +141,13 @@ func FixLettering(sourceLib, destLib *content.Library, sb *ktio.StatusBar) error
 		}
 
 		switch selection {
+		case 'r':
+			if err := ktio.MkdirAll(filepath.Dir(reletterPath), 0o750); err != nil {
+				c.Printf("    <red>ERROR:</> creating %s: %s\n", filepath.Dir(reletterPath), err)
+				continue
+			}
+			destPath = reletterPath
+			fallthrough
 		case 'm', 'a':
 			pendingMoves++
 			moveQueueChan <- moveAction{
@@ -1,10 +1,13 @@
 package cli
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"path"
+	"path/filepath"
 	"sort"
+	"strings"
 
 	c "github.com/gookit/color"
 	"github.com/katbyte/go-ingest-media/lib/content"
@@ -12,7 +15,7 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 )
 
-func ProcessMovies(id string, mapping content.LibraryMapping) error {
+func ProcessMovies(ctx context.Context, id string, mapping content.LibraryMapping) error {
 	f := GetFlags()
 
 	srcLib := mapping.Source
@@ -69,13 +72,13 @@ func ProcessMovies(id string, mapping content.LibraryMapping) error {
 		}
 
 		// load source videos
-		if err = m.LoadVideos(); err != nil {
+		if err = m.LoadVideos(ctx); err != nil {
 			c.Printf(" <red>ERROR:</> loading source videos: %s\n\n", err)
 			continue
 		}
 
 		// load destination videos
-		dstVideos, err := content.VideosInPath(destPath)
+		dstVideos, err := content.VideosInPath(ctx, destPath)
 		if err != nil {
 			c.Printf(" <red>ERROR:</> loading dest videos: %s\n\n", err)
 			continue
@@ -104,7 +107,7 @@ func ProcessMovies(id string, mapping content.LibraryMapping) error {
 				headers = append(headers, fmt.Sprintf("Source %d", i+1))
 			}
 
-			RenderVideoComparisonTable(2, headers, m.Videos)
+			RenderVideoComparisonTable(ctx, 2, headers, m.Videos, dstLib.Languages)
 			c.Printf(" pick source to keep (1-%d) skip (s) e[x]it: ", len(m.Videos))
 
 			options := []rune{'s', 'x'}
@@ -130,7 +133,7 @@ func ProcessMovies(id string, mapping content.LibraryMapping) error {
 
 			for idx, v := range m.Videos {
 				if idx != keepIdx {
-					if err := ktio.RunCommand(4, f.Prompt, "rm", "-v", v.Path); err != nil {
+					if err := ktio.Remove(4, f.Prompt, v.Path); err != nil {
 						c.Printf("   <red>ERROR:</> deleting source video: %s\n", err)
 					}
 				}
@@ -169,36 +172,62 @@ func ProcessMovies(id string, mapping content.LibraryMapping) error {
 			continue
 		}
 
-		// output video comparison table
-		headers := []string{"Source"}
-		if len(dstVideos) == 1 {
-			headers = append(headers, "Destination")
-		} else {
-			for i := range dstVideos {
-				headers = append(headers, fmt.Sprintf("Dest %d", i+1))
+		// a different edition or length is likely another cut rather than a better copy so is never decided by policy
+		var cutDiffs []string
+		for _, dstVideo := range dstVideos {
+			if diff := content.CutDifference(srcVideo, dstVideo); diff != "" {
+				cutDiffs = append(cutDiffs, diff)
 			}
 		}
-		RenderVideoComparisonTable(2, headers, append([]content.VideoFile{srcVideo}, dstVideos...))
-		c.Printf(" overwrite (y/a?) delete src (d?) skip (s?) pick dest (1-%d) e[x]it: ", len(dstVideos))
-		options := []rune{'a', 'y', 'd', 's', 'x'}
-		for k := 1; k <= len(dstVideos) && k <= 9; k++ {
-			options = append(options, rune('0'+k))
+
+		var s rune
+		if len(cutDiffs) > 0 {
+			c.Printf("  <lightMagenta>DIFFERENT CUT</> - %s\n", strings.Join(cutDiffs, "; "))
+		} else {
+			s = policySelection(dstLib, 2, destPath, srcVideo, dstVideos)
 		}
-		s, err := ktio.GetSelection(options...)
-		fmt.Println()
-		fmt.Println()
-		if err != nil {
-			c.Printf(" <red>ERROR:</>%s\n", err)
-			continue
+		if s == 0 {
+			// output video comparison table
+			headers := []string{"Source"}
+			if len(dstVideos) == 1 {
+				headers = append(headers, "Destination")
+			} else {
+				for i := range dstVideos {
+					headers = append(headers, fmt.Sprintf("Dest %d", i+1))
+				}
+			}
+			RenderVideoComparisonTable(ctx, 2, headers, append([]content.VideoFile{srcVideo}, dstVideos...), dstLib.Languages)
+			options := []rune{'a', 'y', 'd', 's', 'x'}
+			if len(dstVideos) == 1 {
+				c.Printf(" overwrite (y/a?) delete src (d?) skip (s?) keep both as versions (k?) pick dest (1) e[x]it: ")
+				options = append(options, 'k')
+			} else {
+				c.Printf(" overwrite (y/a?) delete src (d?) skip (s?) pick dest (1-%d) e[x]it: ", len(dstVideos))
+			}
+			for k := 1; k <= len(dstVideos) && k <= 9; k++ {
+				options = append(options, rune('0'+k))
+			}
+			s, err = ktio.GetSelection(options...)
+			fmt.Println()
+			fmt.Println()
+			if err != nil {
+				c.Printf(" <red>ERROR:</>%s\n", err)
+				continue
+			}
 		}
 
 		switch s {
 		case 'a':
 			fallthrough
 		case 'y':
+			// make sure the source fits before deleting anything it replaces
+			if !replaceFits(3, m.Folder, destPath, m.Path()) {
+				break
+			}
+
 			// delete destination video files first
 			for _, v := range dstVideos {
-				if err := ktio.RunCommand(4, f.Prompt, "rm", "-v", v.Path); err != nil {
+				if err := ktio.Remove(4, f.Prompt, v.Path); err != nil {
 					c.Printf("   <red>ERROR:</> deleting destination video: %s\n", err)
 				}
 			}
@@ -206,6 +235,10 @@ func ProcessMovies(id string, mapping content.LibraryMapping) error {
 			if err := m.MoveFilesTo(destPath, f.Prompt, 4); err != nil {
 				c.Printf("   <red>ERROR:</> moving files: %s\n", err)
 			}
+		case 'k':
+			if err := keepBothVersions(&m, destPath, dstVideos[0]); err != nil {
+				c.Printf("   <red>ERROR:</> keeping both versions: %s\n", err)
+			}
 		case 's':
 		case '1', '2', '3', '4', '5', '6', '7', '8', '9':
 			keepIdx := int(s-'0') - 1
@@ -213,7 +246,7 @@ func ProcessMovies(id string, mapping content.LibraryMapping) error {
 			// delete destination video files except the selected one
 			for idx, v := range dstVideos {
 				if idx != keepIdx {
-					if err := ktio.RunCommand(4, f.Prompt, "rm", "-v", v.Path); err != nil {
+					if err := ktio.Remove(4, f.Prompt, v.Path); err != nil {
 						c.Printf("   <red>ERROR:</> deleting destination video: %s\n", err)
 					}
 				}
@@ -243,7 +276,7 @@ func ProcessMovies(id string, mapping content.LibraryMapping) error {
 
 		if y {
 			for _, path := range srcPathsToDelete {
-				if err := ktio.RunCommand(4, f.Prompt, "rm", "-rfv", path); err != nil {
+				if err := ktio.RemoveAll(4, f.Prompt, path); err != nil {
 					c.Printf("   <red>ERROR:</> deleting source folder: %s\n", err)
 				}
 			}
@@ -251,3 +284,72 @@ func ProcessMovies(id string, mapping content.LibraryMapping) error {
 	}
 	return nil
 }
+
+// keepBothVersions renames the source and destination videos after their editions and moves the source in next to
+// the destination so both are kept as versions of the movie. If the source can't be moved both renames are undone
+func keepBothVersions(m *content.Movie, destPath string, dstVideo content.VideoFile) error {
+	f := GetFlags()
+
+	srcName, dstName := content.VersionNames(path.Base(destPath), m.Videos[0], dstVideo)
+	dst, err := renameVersion(f.Prompt, dstVideo, dstName)
+	if err != nil {
+		return fmt.Errorf("renaming destination video: %w", err)
+	}
+
+	src, err := renameVersion(f.Prompt, m.Videos[0], srcName)
+	if err != nil {
+		return errors.Join(fmt.Errorf("renaming source video: %w", err), undoRenameVersion(f.Prompt, dst, dstVideo))
+	}
+
+	srcVideo := m.Videos[0]
+	m.Videos[0] = src
+	if err := m.MoveFilesTo(destPath, f.Prompt, 4); err != nil {
+		m.Videos[0] = srcVideo
+		return errors.Join(err, undoRenameVersion(f.Prompt, src, srcVideo), undoRenameVersion(f.Prompt, dst, dstVideo))
+	}
+
+	return nil
+}
+
+// renameVersion renames a video and the subtitle files named after it within its folder, returning the renamed video
+func renameVersion(prompt bool, v content.VideoFile, name string) (content.VideoFile, error) {
+	dir := filepath.Dir(v.Path)
+	newPath := filepath.Join(dir, name)
+	if v.Path == newPath {
+		return v, nil
+	}
+
+	if err := ktio.Move(4, prompt, v.Path, newPath); err != nil {
+		return v, err
+	}
+
+	renamed := v
+	renamed.Path = newPath
+	renamed.SidecarSubtitles = nil
+
+	oldStem := strings.TrimSuffix(filepath.Base(v.Path), v.Ext)
+	newStem := strings.TrimSuffix(name, filepath.Ext(name))
+	for _, sub := range v.SidecarSubtitles {
+		rest, ok := strings.CutPrefix(filepath.Base(sub), oldStem)
+		if !ok {
+			renamed.SidecarSubtitles = append(renamed.SidecarSubtitles, sub)
+			continue
+		}
+
+		newSub := filepath.Join(dir, newStem+rest)
+		if err := ktio.Move(4, prompt, sub, newSub); err != nil {
+			return renamed, err
+		}
+		renamed.SidecarSubtitles = append(renamed.SidecarSubtitles, newSub)
+	}
+
+	return renamed, nil
+}
+
+// undoRenameVersion puts a video renamed by renameVersion back to its original name
+func undoRenameVersion(prompt bool, renamed, original content.VideoFile) error {
+	if _, err := renameVersion(prompt, renamed, filepath.Base(original.Path)); err != nil {
+		return fmt.Errorf("restoring %s: %w", filepath.Base(original.Path), err)
+	}
+	return nil
+}
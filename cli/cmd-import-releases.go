@@ -0,0 +1,329 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	c "github.com/gookit/color"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/katbyte/go-ingest-media/lib/content"
+	"github.com/katbyte/go-ingest-media/lib/ktio"
+)
+
+// releaseRename is a release in an import source and the library name it will be renamed to
+type releaseRename struct {
+	entry   string
+	isDir   bool
+	release content.Release
+	samples []string // sample clips and folders in the release that are removed
+}
+
+// RenameReleases renames raw download folders and files in an import mapping's source, ie
+// The.Movie.2019.1080p.BluRay.x265-GRP, to the library convention so they can be processed like everything else. The
+// renames are shown before asking to apply them, series releases without a year are left alone as the series folder
+// can't be named
+func RenameReleases(mapping content.LibraryMapping) error {
+	lib := mapping.Source
+	if lib.LetterFolders {
+		return nil // downloads are never sorted into letters
+	}
+
+	renames, err := planReleaseRenames(lib)
+	if err != nil {
+		return err
+	}
+	if len(renames) == 0 {
+		return nil
+	}
+
+	renderReleaseTable(lib, renames)
+
+	c.Printf("<lightYellow>RENAME %d RELEASES y/n: </>", len(renames))
+	y, err := ktio.Confirm()
+	fmt.Println()
+	if err != nil {
+		return err
+	}
+	if !y {
+		return nil
+	}
+
+	for _, r := range renames {
+		switch lib.Type {
+		case content.LibraryTypeMovies, content.LibraryTypeStandup:
+			renameMovieRelease(lib, r)
+		case content.LibraryTypeSeries:
+			renameSeriesRelease(lib, r)
+		case content.LibraryTypeUnknown:
+		}
+	}
+
+	return nil
+}
+
+// planReleaseRenames finds the release folders and loose release videos in the root of a library
+func planReleaseRenames(lib *content.Library) ([]releaseRename, error) {
+	folders, err := ktio.ListFolders(lib.Path)
+	if err != nil {
+		return nil, err
+	}
+	files, err := ktio.ListFiles(lib.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var renames []releaseRename
+	plan := func(entry, name string, isDir bool) {
+		r := content.ParseRelease(name)
+		if !r.IsRelease() {
+			return
+		}
+		if lib.Type == content.LibraryTypeSeries && r.Year == 0 {
+			c.Printf("  <darkGray>SKIP:</> %s has no year to name the series folder with, rename it by hand\n", filepath.Base(entry))
+			return
+		}
+
+		rr := releaseRename{entry: entry, isDir: isDir, release: r}
+		if isDir {
+			rr.samples = releaseSamples(entry)
+		}
+		renames = append(renames, rr)
+	}
+
+	for _, folder := range folders {
+		plan(folder, filepath.Base(folder), true)
+	}
+	for _, file := range files {
+		if content.IsVideoFile(file) {
+			plan(file, strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)), false)
+		}
+	}
+
+	return renames, nil
+}
+
+// renderReleaseTable prints each release and the library folder it is renamed to
+func renderReleaseTable(lib *content.Library, renames []releaseRename) {
+	var buf bytes.Buffer
+	t := table.NewWriter()
+	t.SetOutputMirror(&buf)
+	t.SetStyle(tablestyle)
+
+	t.AppendHeader(table.Row{"Release", "To"})
+	t.AppendSeparator()
+
+	for _, r := range renames {
+		to := r.release.MovieFolder()
+		if lib.Type == content.LibraryTypeSeries {
+			to = r.release.SeriesFolder()
+		}
+
+		to = c.Sprintf("<green>%s</>", to)
+		if len(r.samples) > 0 {
+			to += c.Sprintf(" <darkGray>(removing samples: %d)</>", len(r.samples))
+		}
+		t.AppendRow(table.Row{c.Sprintf("<white>%s</>", filepath.Base(r.entry)), to})
+	}
+
+	t.Render()
+	_, _ = ktio.IndentWriter{W: os.Stdout, Indent: strings.Repeat(" ", 2)}.Write(buf.Bytes())
+}
+
+// renameMovieRelease renames a release folder (or puts a loose video in a new folder) and its video to the movie's
+// library names
+func renameMovieRelease(lib *content.Library, rr releaseRename) {
+	f := GetFlags()
+	entry, r := rr.entry, rr.release
+
+	folder := filepath.Join(lib.Path, r.MovieFolder())
+	c.Printf("  <white>%s</> --> <bgMagenta;white;op=bold> RELEASE </> <green>%s</>\n", filepath.Base(entry), r.MovieFolder())
+
+	if !rr.isDir {
+		if err := ktio.MkdirAll(folder, 0o750); err != nil {
+			c.Printf("    <red>ERROR:</> creating %s: %s\n", folder, err)
+			return
+		}
+		if err := moveReleaseFile(f.Prompt, entry, filepath.Join(folder, r.MovieFile(filepath.Ext(entry)))); err != nil {
+			c.Printf("    <red>ERROR:</> moving video: %s\n", err)
+		}
+		return
+	}
+
+	if entry != folder {
+		if ktio.PathExists(folder) {
+			c.Printf("    <yellow>WARNING:</> %s already exists, skipping\n", folder)
+			return
+		}
+		if err := ktio.Move(4, f.Prompt, entry, folder); err != nil {
+			c.Printf("    <red>ERROR:</> renaming folder: %s\n", err)
+			return
+		}
+	}
+	removeReleaseSamples(f.Prompt, entry, folder, rr.samples)
+
+	videos := releaseVideos(folder)
+	if len(videos) != 1 {
+		if len(videos) > 1 {
+			c.Printf("    <yellow>WARNING:</> %d videos, leaving their names\n", len(videos))
+		}
+		return
+	}
+
+	// the folder's name has the year and edition, the video's name is often shortened
+	fr := content.ParseRelease(strings.TrimSuffix(filepath.Base(videos[0]), filepath.Ext(videos[0])))
+	if r.Edition == "" {
+		r.Edition = fr.Edition
+	}
+	dst := filepath.Join(folder, r.MovieFile(filepath.Ext(videos[0])))
+	if dst != videos[0] {
+		if err := moveReleaseFile(f.Prompt, videos[0], dst); err != nil {
+			c.Printf("    <red>ERROR:</> renaming video: %s\n", err)
+		}
+	}
+}
+
+// renameSeriesRelease moves every episode in a release folder (or a loose episode) into the series and season
+// folders, then removes the release folder if nothing but junk is left
+func renameSeriesRelease(lib *content.Library, rr releaseRename) {
+	f := GetFlags()
+	entry, r := rr.entry, rr.release
+
+	videos := []string{entry}
+	if rr.isDir {
+		removeReleaseSamples(f.Prompt, entry, entry, rr.samples)
+		videos = releaseVideos(entry)
+	}
+
+	c.Printf("  <white>%s</> --> <bgMagenta;white;op=bold> RELEASE </> <green>%s</>\n", filepath.Base(entry), r.SeriesFolder())
+
+	for _, v := range videos {
+		er := content.ParseRelease(strings.TrimSuffix(filepath.Base(v), filepath.Ext(v)))
+		if !er.IsEpisode() {
+			c.Printf("    <yellow>WARNING:</> no season and episode in %s, skipping\n", filepath.Base(v))
+			continue
+		}
+
+		// the release folder is named consistently, its files not always
+		er.Title, er.Year = r.Title, r.Year
+		seasonPath := filepath.Join(lib.Path, er.SeriesFolder(), er.SeasonFolder())
+		if err := ktio.MkdirAll(seasonPath, 0o750); err != nil {
+			c.Printf("    <red>ERROR:</> creating %s: %s\n", seasonPath, err)
+			continue
+		}
+		if err := moveReleaseFile(f.Prompt, v, filepath.Join(seasonPath, er.EpisodeFile(filepath.Ext(v)))); err != nil {
+			c.Printf("    <red>ERROR:</> moving episode: %s\n", err)
+		}
+	}
+
+	if !rr.isDir {
+		return
+	}
+	if err := ktio.DeleteIfEmptyOrOnlyJunk(entry, f.Prompt, 4); err != nil {
+		c.Printf("    <red>ERROR:</> deleting release folder: %s\n", err)
+	}
+	if ktio.PathExists(entry) {
+		c.Printf("    <yellow>WARNING:</> release folder is not empty, leaving it\n")
+	}
+}
+
+// releaseSamples returns the sample folders and the sample videos outside of them in a release folder and its
+// immediate sub folders
+func releaseSamples(folder string) []string {
+	var samples []string
+	paths := []string{folder}
+	if subs, err := ktio.ListFolders(folder); err == nil {
+		for _, sub := range subs {
+			if content.IsSample(filepath.Base(sub)) {
+				samples = append(samples, sub)
+			} else {
+				paths = append(paths, sub)
+			}
+		}
+	}
+
+	for _, p := range paths {
+		files, err := ktio.ListFiles(p)
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			if content.IsVideoFile(file) && content.IsSample(filepath.Base(file)) {
+				samples = append(samples, file)
+			}
+		}
+	}
+	return samples
+}
+
+// removeReleaseSamples deletes (or trashes) the samples found in a release folder before it was renamed to folder so
+// they aren't moved into the library
+func removeReleaseSamples(prompt bool, entry, folder string, samples []string) {
+	for _, sample := range samples {
+		path := filepath.Join(folder, strings.TrimPrefix(sample, entry))
+		if err := ktio.RemoveAll(4, prompt, path); err != nil {
+			c.Printf("    <red>ERROR:</> removing sample %s: %s\n", filepath.Base(path), err)
+		}
+	}
+}
+
+// releaseVideos returns the videos in a release folder and its immediate sub folders, skipping samples
+func releaseVideos(folder string) []string {
+	paths := []string{folder}
+	if subs, err := ktio.ListFolders(folder); err == nil {
+		paths = append(paths, subs...)
+	}
+
+	var videos []string
+	for _, p := range paths {
+		files, err := ktio.ListFiles(p)
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			if content.IsVideoFile(file) && !content.IsSample(filepath.Base(file)) {
+				videos = append(videos, file)
+			}
+		}
+	}
+	return videos
+}
+
+// moveReleaseFile moves a video and the subtitles named after it, keeping anything after the video's name ie .en.srt.
+// Nothing already at a destination is replaced, those files are skipped
+func moveReleaseFile(prompt bool, src, dst string) error {
+	if src == dst {
+		return nil
+	}
+	if ktio.PathExists(dst) {
+		c.Printf("    <yellow>WARNING:</> %s already exists, skipping\n", dst)
+		return nil
+	}
+	if err := ktio.Move(4, prompt, src, dst); err != nil {
+		return err
+	}
+
+	oldStem := strings.TrimSuffix(filepath.Base(src), filepath.Ext(src))
+	newStem := strings.TrimSuffix(filepath.Base(dst), filepath.Ext(dst))
+	files, err := ktio.ListFiles(filepath.Dir(src))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		rest, ok := strings.CutPrefix(filepath.Base(file), oldStem+".")
+		if !ok || !content.IsSubtitleFile(file) {
+			continue
+		}
+		subDst := filepath.Join(filepath.Dir(dst), newStem+"."+rest)
+		if ktio.PathExists(subDst) {
+			c.Printf("    <yellow>WARNING:</> %s already exists, skipping\n", subDst)
+			continue
+		}
+		if err := ktio.Move(4, prompt, file, subDst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
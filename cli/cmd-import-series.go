@@ -1,9 +1,9 @@
 package cli
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"os"
 	"path"
 	"sort"
 	"strconv"
@@ -14,7 +14,7 @@ import (
 	"github.com/katbyte/go-ingest-media/lib/ktio"
 )
 
-func ProcessSeries(id string, mapping content.LibraryMapping) error {
+func ProcessSeries(ctx context.Context, id string, mapping content.LibraryMapping) error {
 	f := GetFlags()
 
 	srcLib := mapping.Source
@@ -60,13 +60,13 @@ func ProcessSeries(id string, mapping content.LibraryMapping) error {
 		c.Printf("<darkGray>%d/%d</>  <white>%s</> --> <yellow>%s</>\n", i, nSeries, s.Folder, path.Base(destPath))
 
 		// load source seasons
-		if err = s.LoadSeasons(); err != nil {
+		if err = s.LoadSeasons(ctx); err != nil {
 			c.Printf(" <red>ERROR:</> loading source seasons: %s\n\n", err)
 			continue
 		}
 
 		// load destination seasons
-		if err = s.LoadDestSeasons(destPath); err != nil {
+		if err = s.LoadDestSeasons(ctx, destPath); err != nil {
 			c.Printf(" <red>ERROR:</> loading dest seasons: %s\n\n", err)
 			continue
 		}
@@ -131,7 +131,7 @@ func ProcessSeries(id string, mapping content.LibraryMapping) error {
 					for i := range se.Videos {
 						headers = append(headers, fmt.Sprintf("Source %d", i+1))
 					}
-					RenderVideoComparisonTable(indent+6, headers, se.Videos)
+					RenderVideoComparisonTable(ctx, indent+6, headers, se.Videos, dstLib.Languages)
 
 					c.Printf("%s     pick source to keep (1-%d) skip (s) e[x]it: ", intentStr, len(se.Videos))
 					options := []rune{'s', 'x'}
@@ -157,7 +157,7 @@ func ProcessSeries(id string, mapping content.LibraryMapping) error {
 
 					for idx, v := range se.Videos {
 						if idx != keepIdx {
-							if err := ktio.RunCommand(indent+6, f.Prompt, "rm", "-v", v.Path); err != nil {
+							if err := ktio.Remove(indent+6, f.Prompt, v.Path); err != nil {
 								c.Printf("      <red>ERROR:</> deleting source video: %s\n", err)
 							}
 						}
@@ -185,7 +185,7 @@ func ProcessSeries(id string, mapping content.LibraryMapping) error {
 					for _, file := range se.OtherFiles {
 						if strings.HasSuffix(file, ".nfo") {
 							c.Printf("%s           --> nfo, deleting\n", intentStr)
-							if err := ktio.RunCommand(indent+10, f.Prompt, "rm", "-v", file); err != nil {
+							if err := ktio.Remove(indent+10, f.Prompt, file); err != nil {
 								c.Printf("          <red>ERROR:</> deleting nfo: %s\n", err)
 							}
 						} else {
@@ -196,7 +196,7 @@ func ProcessSeries(id string, mapping content.LibraryMapping) error {
 								c.Printf(" <red>ERROR:</>%s\n", err)
 								continue
 							} else if yes {
-								if err := ktio.RunCommand(indent+10, f.Prompt, "mv", "-v", file, ds.Path+"/"); err != nil {
+								if err := ktio.Move(indent+10, f.Prompt, file, ds.Path+"/"); err != nil {
 									c.Printf("          <red>ERROR:</> moving file: %s\n", err)
 								}
 							} else {
@@ -230,7 +230,7 @@ func ProcessSeries(id string, mapping content.LibraryMapping) error {
 
 				if isSame {
 					c.Printf("%s     <green>%dx%d</> --> SAME - deleting source and syncing extras\n", intentStr, seasonNum, episodeNum)
-					if err := ktio.RunCommand(indent+10, f.Prompt, "rm", "-v", srcVideo.Path); err != nil {
+					if err := ktio.Remove(indent+10, f.Prompt, srcVideo.Path); err != nil {
 						c.Printf("      <red>ERROR:</> deleting source video: %s\n", err)
 					}
 					// move extras
@@ -247,15 +247,18 @@ func ProcessSeries(id string, mapping content.LibraryMapping) error {
 
 				c.Printf("%s     <yellow>%dx%d</> --> <darkGray>%s</>\n", intentStr, seasonNum, episodeNum, ds.Path)
 
-				// output video comparison table
-				headers := []string{"Source"}
-				for i := range de.Videos {
-					headers = append(headers, fmt.Sprintf("Dest %d", i+1))
+				s := policySelection(dstLib, indent+10, srcVideo.Path, srcVideo, de.Videos)
+				if s == 0 {
+					// output video comparison table
+					headers := []string{"Source"}
+					for i := range de.Videos {
+						headers = append(headers, fmt.Sprintf("Dest %d", i+1))
+					}
+					RenderVideoComparisonTable(ctx, 2, headers, append([]content.VideoFile{srcVideo}, de.Videos...), dstLib.Languages)
 				}
-				RenderVideoComparisonTable(2, headers, append([]content.VideoFile{srcVideo}, de.Videos...))
 
-				var s rune
 				switch {
+				case s != 0:
 				case moveAll:
 					s = 'A'
 				case deleteAll:
@@ -283,10 +286,15 @@ func ProcessSeries(id string, mapping content.LibraryMapping) error {
 					skipAll = false
 					fallthrough
 				case 'a', 'y':
-					// delete de files
+					// make sure the source fits before deleting anything it replaces
 					fmt.Println()
+					if !replaceFits(4, path.Base(srcVideo.Path), ds.Path, append([]string{srcVideo.Path}, se.OtherFiles...)...) {
+						break
+					}
+
+					// delete de files
 					for _, v := range de.Videos {
-						if err := ktio.RunCommand(4, f.Prompt, "rm", "-v", v.Path); err != nil {
+						if err := ktio.Remove(4, f.Prompt, v.Path); err != nil {
 							c.Printf("    <red>ERROR:</> deleting destination video: %s\n", err)
 						}
 					}
@@ -306,7 +314,7 @@ func ProcessSeries(id string, mapping content.LibraryMapping) error {
 						if idx == keepIdx {
 							newDst = append(newDst, v)
 						} else {
-							if err := ktio.RunCommand(4, f.Prompt, "rm", "-v", v.Path); err != nil {
+							if err := ktio.Remove(4, f.Prompt, v.Path); err != nil {
 								c.Printf("    <red>ERROR:</> deleting destination video: %s\n", err)
 							}
 						}
@@ -316,7 +324,7 @@ func ProcessSeries(id string, mapping content.LibraryMapping) error {
 					ds.Episodes[episodeNum] = de
 
 					// delete the source video
-					if err := ktio.RunCommand(4, f.Prompt, "rm", "-v", srcVideo.Path); err != nil {
+					if err := ktio.Remove(4, f.Prompt, srcVideo.Path); err != nil {
 						c.Printf("    <red>ERROR:</> deleting source video: %s\n", err)
 					}
 
@@ -326,7 +334,7 @@ func ProcessSeries(id string, mapping content.LibraryMapping) error {
 					skipAll = false
 					fallthrough
 				case 'd':
-					if err := ktio.RunCommand(4, f.Prompt, "rm", "-v", srcVideo.Path); err != nil {
+					if err := ktio.Remove(4, f.Prompt, srcVideo.Path); err != nil {
 						c.Printf("    <red>ERROR:</> deleting source video: %s\n", err)
 					}
 				case 'S':
@@ -360,9 +368,9 @@ func ProcessSeries(id string, mapping content.LibraryMapping) error {
 		}
 
 		// cleanup empty specials/extras first (and any remaining nfo files)
-		for _, sub := range []string{"specials", "extras"} {
-			subPath := fmt.Sprintf("%s/%s", s.Path(), sub)
-			if ktio.PathExists(subPath) {
+		for _, subPath := range []string{s.SpecialsPath, s.Path() + "/extras"} {
+			sub := path.Base(subPath)
+			if subPath != "" && ktio.PathExists(subPath) {
 				if err := ktio.DeleteIfEmptyOrOnlyNfo(subPath, f.Prompt, indent); err != nil {
 					c.Printf(" <red>ERROR:</> deleting %s folder: %s\n", sub, err)
 				}
@@ -391,7 +399,7 @@ func ProcessSeries(id string, mapping content.LibraryMapping) error {
 
 		if y {
 			for _, path := range pathsToDelete {
-				if err := ktio.RunCommand(4, f.Prompt, "rm", "-rfv", path); err != nil {
+				if err := ktio.RemoveAll(4, f.Prompt, path); err != nil {
 					c.Printf("    <red>ERROR:</> deleting path: %s\n", err)
 				}
 			}
@@ -430,7 +438,7 @@ func ProcessSeries(id string, mapping content.LibraryMapping) error {
 				continue
 			}
 			if empty {
-				if err := ktio.RunCommand(4, f.Prompt, "rmdir", "-v", ss.Path); err != nil {
+				if err := ktio.RemoveDir(4, f.Prompt, ss.Path); err != nil {
 					c.Printf("    <red>ERROR:</> deleting season folder: %s\n", err)
 				}
 			}
@@ -441,7 +449,7 @@ func ProcessSeries(id string, mapping content.LibraryMapping) error {
 			continue
 		}
 		if empty {
-			if err := ktio.RunCommand(4, f.Prompt, "rmdir", "-v", s.Path()); err != nil {
+			if err := ktio.RemoveDir(4, f.Prompt, s.Path()); err != nil {
 				c.Printf("    <red>ERROR:</> deleting source folder: %s\n", err)
 			}
 			fmt.Println()
@@ -456,13 +464,18 @@ func ProcessSpecialFiles(indent int, s content.Series, seriesDestPath, folder st
 
 	dstPath := path.Join(seriesDestPath, folder)
 	if !ktio.PathExists(dstPath) {
-		if err := os.MkdirAll(dstPath, 0o750); err != nil {
+		if err := ktio.MkdirAll(dstPath, 0o750); err != nil {
 			return fmt.Errorf("error creating specials directory: %w", err)
 		}
 	}
 
 	moveAll := false
 	for _, file := range files {
+		// already moved with the season 0 folder
+		if !ktio.PathExists(file) {
+			continue
+		}
+
 		shouldMove := moveAll
 		if !moveAll {
 			c.Printf("%s       --> <white>%s</> move (y/n/a)? ", strings.Repeat(" ", indent), path.Base(file))
@@ -483,7 +496,7 @@ func ProcessSpecialFiles(indent int, s content.Series, seriesDestPath, folder st
 		}
 
 		if shouldMove {
-			if err := ktio.RunCommand(indent+6, f.Prompt, "mv", "-v", file, dstPath+"/"); err != nil {
+			if err := ktio.Move(indent+6, f.Prompt, file, dstPath+"/"); err != nil {
 				return fmt.Errorf("error moving file: %w", err)
 			}
 		}
@@ -495,7 +508,7 @@ func ProcessSpecialFiles(indent int, s content.Series, seriesDestPath, folder st
 	}
 	if empty {
 		c.Printf("%s   <green>EMPTY</> - removing directory: ", strings.Repeat(" ", indent))
-		if err := ktio.RunCommand(indent+4, f.Prompt, "rmdir", "-v", dstPath); err != nil {
+		if err := ktio.RemoveDir(indent+4, f.Prompt, dstPath); err != nil {
 			c.Printf("    <red>ERROR:</> deleting empty destination: %s\n", err)
 		}
 		fmt.Println()
@@ -1,26 +1,39 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"sort"
 	"strconv"
+	"strings"
 
 	c "github.com/gookit/color"
 	"github.com/katbyte/go-ingest-media/lib/content"
+	"github.com/katbyte/go-ingest-media/lib/ktio"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/spf13/cobra"
 )
 
 func ImportDownloadedContent(cmd *cobra.Command, args []string) error {
+	f := GetFlags()
+
+	if len(content.ImportMappings) == 0 {
+		return errors.New("no import mappings defined in the config")
+	}
+
 	// Sort keys for consistent ordering
-	keys := make([]string, 0, len(content.LibraryMappingSortedTorrentsImport))
-	for k := range content.LibraryMappingSortedTorrentsImport {
+	keys := make([]string, 0, len(content.ImportMappings))
+	for k := range content.ImportMappings {
 		keys = append(keys, k)
 	}
 	sort.Strings(keys)
 
+	sb := ktio.NewStatusBar()
+	defer sb.Close()
+	defer showProbeProgress(sb)()
+
 	for _, id := range keys {
-		mapping := content.LibraryMappingSortedTorrentsImport[id]
+		mapping := content.ImportMappings[id]
 		src := mapping.Source
 		dst := mapping.Dest
 
@@ -41,14 +54,22 @@ func ImportDownloadedContent(cmd *cobra.Command, args []string) error {
 		}
 		fmt.Println()
 
+		if !f.NoReleaseRename {
+			if err := RenameReleases(mapping); err != nil {
+				return fmt.Errorf("renaming releases: %w", err)
+			}
+		}
+
+		preflightSpace(src, dst)
+
 		switch src.Type {
 		case content.LibraryTypeMovies, content.LibraryTypeStandup:
-			err := ProcessMovies(id, mapping)
+			err := ProcessMovies(cmd.Context(), id, mapping)
 			if err != nil {
 				return err
 			}
 		case content.LibraryTypeSeries:
-			err := ProcessSeries(id, mapping)
+			err := ProcessSeries(cmd.Context(), id, mapping)
 			if err != nil {
 				return err
 			}
@@ -61,3 +82,33 @@ func ImportDownloadedContent(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// policySelection applies the destination library's conflict policy and returns the matching prompt selection,
+// or 0 if no rule decided and the user should be asked
+func policySelection(lib *content.Library, indent int, item string, src content.VideoFile, dsts []content.VideoFile) rune {
+	decision, rule := lib.Policy.Decide(src, dsts)
+	if decision == content.PolicyUndecided {
+		return 0
+	}
+
+	srcScore, dstScore := src.Score(), content.QualityScore{}
+	if best := content.BestScore(dsts); best >= 0 {
+		dstScore = dsts[best].Score()
+	}
+
+	c.Printf("%s<cyan>POLICY:</> %s <darkGray>(rule: %s, score %s vs %s)</>\n", strings.Repeat(" ", indent), decision, rule, srcScore, dstScore)
+	recordDecision(item, decision, rule, srcScore, dstScore)
+
+	switch decision {
+	case content.PolicyReplace:
+		return 'y'
+	case content.PolicyKeep:
+		return 'd'
+	case content.PolicySkip:
+		return 's'
+	case content.PolicyUndecided:
+		fallthrough
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	c "github.com/gookit/color"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/katbyte/go-ingest-media/lib/content"
+	"github.com/katbyte/go-ingest-media/lib/ktio"
+)
+
+// normalizeSeriesFor returns the series to normalize: every series in the named library, the series folder at a path
+// (it has season folders) or every series in a folder of series
+func normalizeSeriesFor(target string) ([]*content.Series, error) {
+	lib, ok := content.Libraries[target]
+	if !ok {
+		path, err := filepath.Abs(target)
+		if err != nil {
+			return nil, err
+		}
+		if !ktio.PathExists(path) {
+			return nil, fmt.Errorf("%q is not a library or a path", target)
+		}
+
+		folders, err := ktio.ListFolders(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range folders {
+			if _, _, ok := content.MatchSeason(filepath.Base(f)); ok {
+				s, err := content.SeriesFor(&content.Library{Name: target, Path: filepath.Dir(path), Type: content.LibraryTypeSeries}, path)
+				if err != nil {
+					return nil, err
+				}
+				return []*content.Series{s}, nil
+			}
+		}
+
+		lib = &content.Library{Name: target, Path: path, Type: content.LibraryTypeSeries}
+	} else if lib.Type != content.LibraryTypeSeries {
+		return nil, fmt.Errorf("library %q is %s, only series can be normalized", target, lib.Type)
+	}
+
+	series, err := lib.Series(func(folder string, err error) {
+		c.Printf("  <red>ERROR:</> %s: %s\n", folder, err)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	all := make([]*content.Series, 0, len(series))
+	for i := range series {
+		all = append(all, &series[i])
+	}
+	return all, nil
+}
+
+// Normalize renames season folders and episode files in a series library, a folder of series or a single series to
+// the library convention, showing every rename before asking to apply them
+func Normalize(ctx context.Context, target string) error {
+	f := GetFlags()
+
+	series, err := normalizeSeriesFor(target)
+	if err != nil {
+		return err
+	}
+
+	var renames []content.NormalizeRename
+	root := ""
+	for _, s := range series {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if root == "" {
+			root = s.Library.Path
+		}
+
+		if err := s.LoadSeasons(ctx); err != nil {
+			c.Printf("  <red>ERROR:</> %s: %s\n", s.Folder, err)
+			continue
+		}
+		renames = append(renames, s.NormalizeRenames(ktio.PathExists)...)
+	}
+
+	if len(renames) == 0 {
+		c.Printf("<green>nothing to rename</>\n")
+		return nil
+	}
+
+	renderNormalizeTable(root, renames)
+
+	toRename := 0
+	for _, r := range renames {
+		if !r.Conflict && r.Unrenameable == "" {
+			toRename++
+		}
+	}
+	if toRename == 0 {
+		c.Printf("<green>nothing to rename</>\n")
+		return nil
+	}
+
+	c.Printf("<lightYellow>RENAME %d y/n: </>", toRename)
+	y, err := ktio.Confirm()
+	fmt.Println()
+	if err != nil {
+		return err
+	}
+	if !y {
+		return nil
+	}
+
+	failed := 0
+	for _, r := range renames {
+		if r.Conflict || r.Unrenameable != "" {
+			continue
+		}
+		if err := ktio.Move(2, f.Prompt, r.From, r.To); err != nil {
+			c.Printf("  <red>ERROR:</> renaming %s: %s\n", r.From, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d renames failed", failed, toRename)
+	}
+	return nil
+}
+
+// renderNormalizeTable prints the renames relative to the library, those that would overwrite something or can't be
+// named are skipped
+func renderNormalizeTable(root string, renames []content.NormalizeRename) {
+	rel := func(path string) string {
+		if r, err := filepath.Rel(root, path); err == nil {
+			return r
+		}
+		return path
+	}
+
+	var buf bytes.Buffer
+	t := table.NewWriter()
+	t.SetOutputMirror(&buf)
+	t.SetStyle(tablestyle)
+
+	t.AppendHeader(table.Row{"From", "To"})
+	t.AppendSeparator()
+
+	for _, r := range renames {
+		to := c.Sprintf("<green>%s</>", filepath.Base(r.To))
+		switch {
+		case r.Unrenameable != "":
+			to = c.Sprintf("<red>can't be renamed</> <darkGray>(%s, skipping)</>", r.Unrenameable)
+		case r.Conflict:
+			to = c.Sprintf("<red>%s</> <darkGray>(exists, skipping)</>", filepath.Base(r.To))
+		}
+		t.AppendRow(table.Row{c.Sprintf("<white>%s</>", rel(r.From)), to})
+	}
+
+	t.Render()
+	_, _ = ktio.IndentWriter{W: os.Stdout, Indent: strings.Repeat(" ", 2)}.Write(buf.Bytes())
+}
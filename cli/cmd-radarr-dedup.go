@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"path/filepath"
@@ -44,7 +45,7 @@ type unmappedEntry struct {
 }
 
 // DedupRadarr connects to Radarr and identifies folders on disk that are duplicates of existing movies
-func DedupRadarr(radarrUrl, apiKey, basePath string, pathMaps []string) error {
+func DedupRadarr(ctx context.Context, radarrUrl, apiKey, basePath string, pathMaps []string) error {
 	if radarrUrl == "" || apiKey == "" {
 		return fmt.Errorf("radarr url and api key are required (--radarr-url / --radarr-api-key or RADARR_URL / RADARR_API_KEY)")
 	}
@@ -259,7 +260,7 @@ func DedupRadarr(radarrUrl, apiKey, basePath string, pathMaps []string) error {
 			case 'a':
 				// Keep A, delete B
 				c.Printf("  <cyan>Keeping A, deleting B: %s...</>\n", dup.matchedPath)
-				if err := ktio.RunCommand(4, f.Prompt, "rm", "-rfv", dup.matchedPath); err != nil {
+				if err := ktio.RemoveAll(4, f.Prompt, dup.matchedPath); err != nil {
 					c.Printf("  <red>ERROR:</> %s\n", err)
 				} else {
 					deleted++
@@ -268,7 +269,7 @@ func DedupRadarr(radarrUrl, apiKey, basePath string, pathMaps []string) error {
 			case 'b':
 				// Keep B, delete A
 				c.Printf("  <magenta>Keeping B, deleting A: %s...</>\n", dup.unmappedPath)
-				if err := ktio.RunCommand(4, f.Prompt, "rm", "-rfv", dup.unmappedPath); err != nil {
+				if err := ktio.RemoveAll(4, f.Prompt, dup.unmappedPath); err != nil {
 					c.Printf("  <red>ERROR:</> %s\n", err)
 				} else {
 					deleted++
@@ -277,8 +278,8 @@ func DedupRadarr(radarrUrl, apiKey, basePath string, pathMaps []string) error {
 			case 'c':
 				c.Printf("  <darkGray>Loading video details (ffprobe)...</>\n")
 
-				videosA, errA := content.VideosInPath(dup.unmappedPath)
-				videosB, errB := content.VideosInPath(dup.matchedPath)
+				videosA, errA := content.VideosInPath(ctx, dup.unmappedPath)
+				videosB, errB := content.VideosInPath(ctx, dup.matchedPath)
 
 				if errA != nil {
 					c.Printf("  <red>ERROR loading A videos:</> %s\n", errA)
@@ -300,7 +301,7 @@ func DedupRadarr(radarrUrl, apiKey, basePath string, pathMaps []string) error {
 						allVideos = append(allVideos, videosB[j])
 					}
 
-					RenderVideoComparisonTable(4, headers, allVideos)
+					RenderVideoComparisonTable(ctx, 4, headers, allVideos, content.LanguageRequirements{})
 					fmt.Println()
 				} else if len(videosA) == 0 && len(videosB) == 0 {
 					c.Printf("  <yellow>No video files found in either folder.</>\n")
@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"fmt"
+
+	c "github.com/gookit/color"
+	"github.com/katbyte/go-ingest-media/lib/content"
+)
+
+// TestRename prints what a folder would be renamed to and the rule that matched
+func TestRename(typeName, folder string) error {
+	libType, err := content.LibraryTypeFor(typeName)
+	if err != nil {
+		return err
+	}
+
+	newFolder, rule, err := content.MatchFolderRename(libType, folder)
+	if err != nil {
+		return fmt.Errorf("error matching rename rules: %w", err)
+	}
+
+	c.Printf("<white>%s</> <cyan>(%s)</>\n", folder, libType)
+	if newFolder == nil {
+		c.Printf("  --> <darkGray>no rule matched, folder unchanged</>\n")
+		return nil
+	}
+
+	c.Printf("  --> <green>%s</>\n", *newFolder)
+	c.Printf("  <darkGray>rule:</> %s\n", rule)
+	return nil
+}
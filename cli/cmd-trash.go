@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	c "github.com/gookit/color"
+	"github.com/katbyte/go-ingest-media/lib/ktio"
+)
+
+// startTrash makes deletes go to the configured trash folders unless disabled with --no-trash
+func startTrash() {
+	f := GetFlags()
+	if f.NoTrash || len(f.Trash) == 0 {
+		return
+	}
+
+	ktio.EnableTrash(f.Trash, sessionID)
+}
+
+// errTrashDisabled is returned by the trash commands when deletes are permanent
+var errTrashDisabled = errors.New("the trash is not configured (set trash in the config or pass --trash) or is disabled with --no-trash")
+
+// ListTrash prints every item in the trash folders
+func ListTrash() error {
+	if !ktio.TrashEnabled() {
+		return errTrashDisabled
+	}
+
+	items, err := ktio.ListTrash()
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		c.Println("<darkGray>trash is empty</>")
+		return nil
+	}
+
+	for _, item := range items {
+		age := time.Since(item.Deleted).Truncate(time.Minute)
+		c.Printf("<white>%s</>  %s <darkGray>(%s ago)</>\n", item.ID, item.Deleted.Local().Format("2006-01-02 15:04"), age)
+		c.Printf("  <lightBlue>%s</>\n", item.Original)
+	}
+
+	return nil
+}
+
+// RestoreTrash moves trashed items back to where they were deleted from
+func RestoreTrash(ids []string) error {
+	f := GetFlags()
+
+	if !ktio.TrashEnabled() {
+		return errTrashDisabled
+	}
+
+	for _, id := range ids {
+		item, err := ktio.FindTrash(id)
+		if err != nil {
+			return err
+		}
+
+		c.Printf("<white>%s</> --> <lightBlue>%s</>\n", item.ID, item.Original)
+		if err := ktio.RestoreFromTrash(2, f.Prompt, item.Path, item.Original); err != nil {
+			c.Printf("  <red>ERROR:</> restoring %s: %s\n", item.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// PurgeTrash permanently deletes trashed items older than the given age
+func PurgeTrash(olderThan string) error {
+	f := GetFlags()
+
+	if !ktio.TrashEnabled() {
+		return errTrashDisabled
+	}
+
+	age, err := parseAge(olderThan)
+	if err != nil {
+		return fmt.Errorf("invalid --older-than %q: %w", olderThan, err)
+	}
+
+	items, err := ktio.ListTrash()
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-age)
+	var purge []ktio.TrashInfo
+	for _, item := range items {
+		if item.Deleted.Before(cutoff) {
+			purge = append(purge, item)
+		}
+	}
+
+	if len(purge) == 0 {
+		c.Printf("<darkGray>nothing in the trash older than %s</>\n", olderThan)
+		return nil
+	}
+
+	for _, item := range purge {
+		c.Printf("<white>%s</> <darkGray>%s</>\n", item.ID, item.Original)
+	}
+	c.Printf("<lightYellow>PERMANENTLY DELETE %d ITEMS deleted before %s y/n: </>", len(purge), cutoff.Local().Format("2006-01-02 15:04"))
+	y, err := ktio.Confirm()
+	fmt.Println()
+	if err != nil {
+		return err
+	}
+	if !y {
+		return nil
+	}
+
+	for _, item := range purge {
+		c.Printf("<white>%s</> <darkGray>%s</>\n", item.ID, item.Original)
+		if err := ktio.PurgeTrash(2, f.Prompt, item); err != nil {
+			c.Printf("  <red>ERROR:</> purging %s: %s\n", item.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// parseAge parses a duration that also accepts days and weeks ie 30d, 2w, 12h
+func parseAge(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			v, err := strconv.ParseFloat(n, 64)
+			if err != nil {
+				return 0, err
+			}
+			return time.Duration(v * float64(unit)), nil
+		}
+	}
+
+	return time.ParseDuration(s)
+}
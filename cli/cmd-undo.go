@@ -0,0 +1,212 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+
+	c "github.com/gookit/color"
+	"github.com/katbyte/go-ingest-media/lib/journal"
+	"github.com/katbyte/go-ingest-media/lib/ktio"
+)
+
+// ListJournal prints the recent sessions in the journal, or the operations of a single session
+func ListJournal(session string, limit int) error {
+	j, err := openJournal()
+	if err != nil {
+		return err
+	}
+	defer j.Close()
+
+	if session != "" {
+		entries, err := j.Entries(session)
+		if err != nil {
+			return err
+		}
+		decisions, err := j.Decisions(session)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 && len(decisions) == 0 {
+			return fmt.Errorf("no operations found for session %q", session)
+		}
+
+		c.Printf("<white>%s</>\n", session)
+		for _, e := range entries {
+			printJournalEntry(e)
+		}
+		if len(decisions) > 0 {
+			c.Printf("<white>policy decisions:</>\n")
+			for _, d := range decisions {
+				c.Printf("  <darkGray>%s</> <cyan>%-7s</> %s <darkGray>(rule: %s, score %.1f vs %.1f)</>\n", d.Time.Local().Format("15:04:05"), d.Decision, d.Item, d.Rule, d.SourceScore, d.DestScore)
+			}
+		}
+		return nil
+	}
+
+	sessions, err := j.Sessions(limit)
+	if err != nil {
+		return err
+	}
+	if len(sessions) == 0 {
+		c.Println("<darkGray>journal is empty</>")
+		return nil
+	}
+
+	for _, s := range sessions {
+		undone := ""
+		if s.Undone > 0 {
+			undone = c.Sprintf(" <yellow>(%d undone)</>", s.Undone)
+		}
+		decisions := ""
+		if s.Decisions > 0 {
+			decisions = c.Sprintf(" <cyan>%d</> decisions", s.Decisions)
+		}
+		c.Printf("<white>%s</>  %s  <lightBlue>%d</> ops%s%s  <darkGray>%s</>\n", s.ID, s.Started.Local().Format("2006-01-02 15:04:05"), s.Operations, undone, decisions, s.Command)
+	}
+
+	return nil
+}
+
+func printJournalEntry(e journal.Entry) {
+	status := ""
+	if e.Undone {
+		status = c.Sprintf(" <yellow>(undone)</>")
+	}
+
+	switch {
+	case e.Dst != "":
+		c.Printf("  <darkGray>%s</> %-6s %s <darkGray>--></> %s%s\n", e.Time.Local().Format("15:04:05"), e.Op, e.Src, e.Dst, status)
+	case e.Trash != "":
+		c.Printf("  <darkGray>%s</> %-6s %s <darkGray>(trash: %s)</>%s\n", e.Time.Local().Format("15:04:05"), e.Op, e.Src, e.Trash, status)
+	default:
+		c.Printf("  <darkGray>%s</> %-6s %s%s\n", e.Time.Local().Format("15:04:05"), e.Op, e.Src, status)
+	}
+}
+
+// Undo reverses the operations of a session (the last one if empty) in reverse order
+func Undo(session string) error {
+	f := GetFlags()
+
+	j, err := openJournal()
+	if err != nil {
+		return err
+	}
+	defer j.Close()
+
+	if session == "" {
+		if session, err = j.LastUndoable(sessionID); err != nil {
+			return err
+		}
+	}
+
+	entries, err := j.Entries(session)
+	if err != nil {
+		return err
+	}
+
+	var todo []journal.Entry
+	for i := len(entries) - 1; i >= 0; i-- {
+		if !entries[i].Undone {
+			todo = append(todo, entries[i])
+		}
+	}
+	if len(todo) == 0 {
+		if decisions, err := j.Decisions(session); err == nil && len(decisions) > 0 && len(entries) == 0 {
+			return fmt.Errorf("session %q only recorded %d policy decisions, nothing to undo", session, len(decisions))
+		}
+		return fmt.Errorf("nothing to undo for session %q", session)
+	}
+
+	c.Printf("undo <white>%d</> operations from session <white>%s</>:\n", len(todo), session)
+	for _, e := range todo {
+		printJournalEntry(e)
+	}
+	c.Printf("<lightYellow>CONFIRM UNDO y/n: </>")
+	y, err := ktio.Confirm()
+	fmt.Println()
+	if err != nil {
+		return err
+	}
+	if !y {
+		return nil
+	}
+
+	failed := 0
+	for _, e := range todo {
+		done, err := undoEntry(e, f.Prompt)
+		if err != nil {
+			failed++
+			c.Printf("  <red>ERROR:</> undoing %s %s: %s\n", e.Op, e.Src, err)
+			continue
+		}
+		if !done || ktio.DryRun() {
+			continue
+		}
+
+		if err := j.MarkUndone(e.ID); err != nil {
+			c.Printf("  <red>ERROR:</> %s\n", err)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d operations could not be undone", failed)
+	}
+
+	return nil
+}
+
+// undoEntry reverses a single operation, returns false if it was skipped
+func undoEntry(e journal.Entry, prompt bool) (bool, error) {
+	switch ktio.OpType(e.Op) {
+	case ktio.OpMove, ktio.OpRename:
+		if !ktio.PathExists(e.Dst) {
+			return false, fmt.Errorf("%s no longer exists", e.Dst)
+		}
+		if ktio.PathExists(e.Src) {
+			return false, fmt.Errorf("%s already exists", e.Src)
+		}
+		if err := ktio.MkdirAll(filepath.Dir(e.Src), 0o750); err != nil {
+			return false, fmt.Errorf("error recreating folder: %w", err)
+		}
+		if err := ktio.Move(2, prompt, e.Dst, e.Src); err != nil {
+			return false, err
+		}
+		return ktio.PathExists(e.Src), nil
+
+	case ktio.OpRemove, ktio.OpRemoveAll:
+		if e.Trash == "" {
+			c.Printf("  <yellow>SKIP:</> %s was permanently deleted and cannot be restored\n", e.Src)
+			return false, nil
+		}
+		if err := ktio.RestoreFromTrash(2, prompt, e.Trash, e.Src); err != nil {
+			return false, err
+		}
+		return ktio.PathExists(e.Src), nil
+
+	case ktio.OpRemoveDir:
+		if err := ktio.MkdirAll(e.Src, 0o750); err != nil {
+			return false, err
+		}
+		return true, nil
+
+	case ktio.OpMkdir:
+		if !ktio.PathExists(e.Src) {
+			return true, nil
+		}
+		if err := ktio.DeleteIfEmpty(e.Src, prompt, 2); err != nil {
+			return false, err
+		}
+		if ktio.PathExists(e.Src) {
+			c.Printf("  <yellow>SKIP:</> %s is not empty\n", e.Src)
+			return false, nil
+		}
+		return true, nil
+
+	case ktio.OpWrite:
+		c.Printf("  <yellow>SKIP:</> file contents of %s cannot be restored\n", e.Src)
+		return false, nil
+
+	default:
+		return false, fmt.Errorf("unknown operation %q", e.Op)
+	}
+}
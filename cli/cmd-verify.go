@@ -0,0 +1,237 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	c "github.com/gookit/color"
+	"github.com/katbyte/go-ingest-media/lib/content"
+	"github.com/katbyte/go-ingest-media/lib/ktio"
+)
+
+// verifyLibraryFor returns the named library, or the source library of the named import mapping
+func verifyLibraryFor(name string) (*content.Library, error) {
+	if m, ok := content.ImportMappings[name]; ok {
+		return m.Source, nil
+	}
+	return content.LibraryFor(name)
+}
+
+// VerifyLibrary reports videos in a library that failed to probe, are far shorter than expected or whose end does
+// not decode, optionally moving them into a quarantine folder
+func VerifyLibrary(ctx context.Context, name, quarantine string, decodeTail bool) error {
+	f := GetFlags()
+
+	lib, err := verifyLibraryFor(name)
+	if err != nil {
+		return err
+	}
+
+	c.Printf("<white>%s</>\n", lib.Path)
+	if decodeTail {
+		if err := content.TailDecoderAvailable(); err != nil {
+			c.Printf("<yellow>WARNING:</> not decoding the end of videos: %s\n", err)
+			decodeTail = false
+		}
+	}
+
+	sb := ktio.NewStatusBar()
+	defer sb.Close()
+	defer showProbeProgress(sb)()
+
+	contents, err := lib.Contents(func(folder string, err error) {
+		c.Printf("  <red>ERROR:</> %s: %s\n", folder, err)
+	})
+	if err != nil {
+		return err
+	}
+
+	checked := 0
+	var issues []content.VerifyIssue
+	for _, item := range contents {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var folder string
+		var videos []content.VideoFile
+		var found []content.VerifyIssue
+		switch i := item.(type) {
+		case *content.Movie:
+			folder = i.Folder
+			if err := i.LoadVideos(ctx); err != nil {
+				c.Printf("  <red>ERROR:</> %s: %s\n", folder, err)
+				continue
+			}
+
+			runtime := 0.0
+			if nfoPath, err := content.FindNfoFile(i.Path()); err == nil && nfoPath != "" {
+				if nfo, err := content.ReadNfo(nfoPath); err == nil {
+					runtime = nfo.RuntimeSeconds()
+				}
+			}
+
+			videos = i.Videos
+			for _, v := range i.Videos {
+				sb.UpdateScan("verifying " + filepath.Base(v.Path))
+				checked++
+				found = append(found, content.VerifyVideo(ctx, v, runtime, decodeTail)...)
+			}
+		case *content.Series:
+			folder = i.Folder
+			if err := i.LoadSeasons(ctx); err != nil {
+				c.Printf("  <red>ERROR:</> %s: %s\n", folder, err)
+				continue
+			}
+
+			videos = seriesVideos(i)
+			n, seasonIssues := verifySeasons(ctx, sb, i, decodeTail)
+			checked += n
+			found = append(found, seasonIssues...)
+		}
+
+		if len(found) == 0 {
+			continue
+		}
+
+		scores := map[string]content.QualityScore{}
+		for _, v := range videos {
+			scores[v.Path] = v.Score()
+		}
+
+		c.Printf("  <yellow>%s</>\n", folder)
+		for _, issue := range found {
+			c.Printf("    %s <red>%s</> <darkGray>(score %s)</>\n", filepath.Base(issue.Path), issue, scores[issue.Path])
+		}
+		issues = append(issues, found...)
+	}
+
+	printVerifySummary(checked, issues)
+
+	if quarantine == "" || len(issues) == 0 {
+		return nil
+	}
+
+	// a video can have more than one problem but is only moved once, keeping its path within the library
+	c.Printf("quarantining to <white>%s</>\n", quarantine)
+	moved := map[string]bool{}
+	for _, issue := range issues {
+		if moved[issue.Path] {
+			continue
+		}
+		moved[issue.Path] = true
+
+		rel, err := filepath.Rel(lib.Path, issue.Path)
+		if err != nil {
+			rel = filepath.Base(issue.Path)
+		}
+		dst := filepath.Join(quarantine, rel)
+		if err := ktio.MkdirAll(filepath.Dir(dst), 0o750); err != nil {
+			c.Printf("  <red>ERROR:</> creating %s: %s\n", filepath.Dir(dst), err)
+			continue
+		}
+		if err := ktio.Move(2, f.Prompt, issue.Path, dst); err != nil {
+			c.Printf("  <red>ERROR:</> quarantining %s: %s\n", issue.Path, err)
+			continue
+		}
+
+		// the subtitles and nfo named after the video go with it
+		for _, sidecar := range namedAfter(issue.Path) {
+			if err := ktio.Move(2, f.Prompt, sidecar, filepath.Join(filepath.Dir(dst), filepath.Base(sidecar))); err != nil {
+				c.Printf("  <red>ERROR:</> quarantining %s: %s\n", sidecar, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// namedAfter returns the other files in a video's folder named after it ie "Movie.en.srt" and "Movie.nfo" for "Movie.mkv"
+func namedAfter(video string) []string {
+	files, err := ktio.ListFiles(filepath.Dir(video))
+	if err != nil {
+		return nil
+	}
+
+	prefix := strings.TrimSuffix(filepath.Base(video), filepath.Ext(video)) + "."
+	var sidecars []string
+	for _, file := range files {
+		if file != video && strings.HasPrefix(filepath.Base(file), prefix) {
+			sidecars = append(sidecars, file)
+		}
+	}
+	return sidecars
+}
+
+// verifySeasons checks every episode of a series against its nfo runtime and the other episodes of its season
+func verifySeasons(ctx context.Context, sb *ktio.StatusBar, s *content.Series, decodeTail bool) (int, []content.VerifyIssue) {
+	seasonNums := make([]int, 0, len(s.Seasons))
+	for n := range s.Seasons {
+		seasonNums = append(seasonNums, n)
+	}
+	sort.Ints(seasonNums)
+
+	checked := 0
+	var issues []content.VerifyIssue
+	for _, sn := range seasonNums {
+		season := s.Seasons[sn]
+
+		epNums := make([]int, 0, len(season.Episodes))
+		for n := range season.Episodes {
+			epNums = append(epNums, n)
+		}
+		sort.Ints(epNums)
+
+		// multi episode files are in the map once per episode
+		seen := map[*content.Episode]bool{}
+		var videos []content.VideoFile
+		for _, en := range epNums {
+			ep := season.Episodes[en]
+			if seen[ep] {
+				continue
+			}
+			seen[ep] = true
+
+			runtime := ep.EpisodeNfoRuntime()
+			for _, v := range ep.Videos {
+				sb.UpdateScan("verifying " + filepath.Base(v.Path))
+				checked++
+				issues = append(issues, content.VerifyVideo(ctx, v, runtime, decodeTail)...)
+			}
+			videos = append(videos, ep.Videos...)
+		}
+
+		issues = append(issues, content.VerifySiblings(videos)...)
+	}
+
+	return checked, issues
+}
+
+func printVerifySummary(checked int, issues []content.VerifyIssue) {
+	if len(issues) == 0 {
+		c.Printf("verified <cyan>%d</> videos, <green>no problems found</>\n", checked)
+		return
+	}
+
+	counts := map[content.VerifyProblem]int{}
+	videos := map[string]bool{}
+	for _, issue := range issues {
+		counts[issue.Problem]++
+		videos[issue.Path] = true
+	}
+
+	c.Printf("verified <cyan>%d</> videos, <red>%d</> with problems\n", checked, len(videos))
+	for _, p := range []content.VerifyProblem{
+		content.ProblemProbeFailed,
+		content.ProblemNoVideo,
+		content.ProblemShortRuntime,
+		content.ProblemShortEpisode,
+		content.ProblemBadTail,
+	} {
+		if counts[p] > 0 {
+			c.Printf("  %s: <red>%d</>\n", p, counts[p])
+		}
+	}
+}
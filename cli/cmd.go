@@ -45,7 +45,27 @@ func Make(cmdName string) (*cobra.Command, error) {
 		Long:          `A CLI tool to intelligently go-ingest-media media into my specific folder structure taking into account existing media and video format/quality.`,
 		SilenceErrors: true,
 		RunE:          ImportDownloadedContent,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := loadConfig(); err != nil {
+				return err
+			}
+
+			if err := startFileOps(); err != nil {
+				return err
+			}
+
+			if err := startProbing(cmd); err != nil {
+				return err
+			}
+
+			startDryRun()
+			startTrash()
+			startJournal(cmd, args)
+			startProbeCache()
+			return nil
+		},
 	}
+	cobra.OnFinalize(finishDryRun, closeJournal, closeProbeCache)
 
 	// check fo duco duplicates between docu folders and movie/tv folders
 	root.AddCommand(&cobra.Command{
@@ -55,25 +75,36 @@ func Make(cmdName string) (*cobra.Command, error) {
 		SilenceErrors: true,
 		// PreRunE:       ValidateParams([]string{"cache"}),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// Movie documentary duplicates
-			docuLib := content.Libraries["video-documentary"]
-			moviesLib := content.Libraries["video-movies"]
+			docuLib, err := content.LibraryFor("video-documentary")
+			if err != nil {
+				return err
+			}
+			moviesLib, err := content.LibraryFor("video-movies")
+			if err != nil {
+				return err
+			}
+			docuseriesLib, err := content.LibraryFor("video-docuseries")
+			if err != nil {
+				return err
+			}
+			tvLib, err := content.LibraryFor("video-tv")
+			if err != nil {
+				return err
+			}
 
+			// Movie documentary duplicates
 			c.Printf("%s <-- %s ", docuLib.Path, moviesLib.Path)
 			fmt.Println()
-			err := FindAndCombineDocu(docuLib, moviesLib)
+			err = FindAndCombineDocu(cmd.Context(), docuLib, moviesLib)
 			if err != nil {
 				return err
 			}
 
 			// Series documentary duplicates
-			docuseriesLib := content.Libraries["video-docuseries"]
-			tvLib := content.Libraries["video-tv"]
-
 			fmt.Println()
 			c.Printf("%s <-- %s ", docuseriesLib.Path, tvLib.Path)
 			fmt.Println()
-			err = FindAndCombineDocuSeries(docuseriesLib, tvLib)
+			err = FindAndCombineDocuSeries(cmd.Context(), docuseriesLib, tvLib)
 			if err != nil {
 				return err
 			}
@@ -89,13 +120,27 @@ func Make(cmdName string) (*cobra.Command, error) {
 		Long:          `Scan movie and TV libraries for content marked as documentary via NFO genre files and move them to the m.docu/s.docu torrent-sorted import folders`,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			moviesLib, err := content.LibraryFor("video-movies")
+			if err != nil {
+				return err
+			}
+			docuImportLib, err := content.LibraryFor("torrent-documentary")
+			if err != nil {
+				return err
+			}
+			tvLib, err := content.LibraryFor("video-tv")
+			if err != nil {
+				return err
+			}
+			docuseriesImportLib, err := content.LibraryFor("torrent-docuseries")
+			if err != nil {
+				return err
+			}
+
 			sb := ktio.NewStatusBar()
 			defer sb.Close()
 
 			// Extract documentary movies: video-movies --> torrent-documentary (m.docu)
-			moviesLib := content.Libraries["video-movies"]
-			docuImportLib := content.Libraries["torrent-documentary"]
-
 			c.Printf("<white>%s</> --> <lightBlue>%s</> ", moviesLib.Path, docuImportLib.Path)
 			fmt.Println()
 			if err := ExtractDocuMovies(moviesLib, docuImportLib, sb); err != nil {
@@ -103,9 +148,6 @@ func Make(cmdName string) (*cobra.Command, error) {
 			}
 
 			// Extract documentary series: video-tv --> torrent-docuseries (s.docu)
-			tvLib := content.Libraries["video-tv"]
-			docuseriesImportLib := content.Libraries["torrent-docuseries"]
-
 			fmt.Println()
 			c.Printf("<white>%s</> --> <lightBlue>%s</> ", tvLib.Path, docuseriesImportLib.Path)
 			fmt.Println()
@@ -125,7 +167,7 @@ func Make(cmdName string) (*cobra.Command, error) {
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			flags := GetFlags()
-			return DedupRadarr(flags.RadarrUrl, flags.RadarrApiKey, flags.RadarrBasePath, flags.RadarrPathMaps)
+			return DedupRadarr(cmd.Context(), flags.RadarrUrl, flags.RadarrApiKey, flags.RadarrBasePath, flags.RadarrPathMaps)
 		},
 	})
 
@@ -133,25 +175,39 @@ func Make(cmdName string) (*cobra.Command, error) {
 	root.AddCommand(&cobra.Command{
 		Use:           "fix-lettering",
 		Short:         cmdName + " find movies/tv in wrong letter folders and move to import folders",
-		Long:          `Scan movie and TV libraries for folders that are placed in the wrong letter directory and move them to the s.tv and m.movies torrent-sorted import folders to be re-processed.`,
+		Long:          `Scan movie and TV libraries for folders that are placed in the wrong letter directory and move them to the s.tv and m.movies torrent-sorted import folders to be re-processed, or re-letter them into the right letter directory. Letters follow each library's lettering config: leading articles are skipped and accents are folded (Élite is e, Ørnen is o).`,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			// library --> import library pairs: movies, tv, anime series
+			pairs := [][2]string{
+				{"video-movies", "torrent-movies"},
+				{"video-tv", "torrent-tv"},
+				{"video-anime-series", "torrent-anime-series"},
+			}
+
+			libs := make([][2]*content.Library, 0, len(pairs))
+			for _, p := range pairs {
+				src, err := content.LibraryFor(p[0])
+				if err != nil {
+					return err
+				}
+				dst, err := content.LibraryFor(p[1])
+				if err != nil {
+					return err
+				}
+				libs = append(libs, [2]*content.Library{src, dst})
+			}
+
 			sb := ktio.NewStatusBar()
 			defer sb.Close()
 
-			// Movies
-			if err := FixLettering(content.Libraries["video-movies"], content.Libraries["torrent-movies"], sb); err != nil {
-				return err
-			}
-			fmt.Println()
-			// TV
-			if err := FixLettering(content.Libraries["video-tv"], content.Libraries["torrent-tv"], sb); err != nil {
-				return err
-			}
-			// Anime Series
-			fmt.Println()
-			if err := FixLettering(content.Libraries["video-anime-series"], content.Libraries["torrent-anime-series"], sb); err != nil {
-				return err
+			for i, l := range libs {
+				if i > 0 {
+					fmt.Println()
+				}
+				if err := FixLettering(l[0], l[1], sb); err != nil {
+					return err
+				}
 			}
 			return nil
 		},
@@ -163,15 +219,32 @@ func Make(cmdName string) (*cobra.Command, error) {
 		Long:          `Locally compares folders in anime/movies vs movies, and anime/tv vs tv. It flags any identical (or renamed) folders as duplicates and allows you to keep one. If you keep the standard version, it will be moved to the anime library.`,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			animeMoviesLib, err := content.LibraryFor("video-anime-movies")
+			if err != nil {
+				return err
+			}
+			moviesLib, err := content.LibraryFor("video-movies")
+			if err != nil {
+				return err
+			}
+			animeSeriesLib, err := content.LibraryFor("video-anime-series")
+			if err != nil {
+				return err
+			}
+			tvLib, err := content.LibraryFor("video-tv")
+			if err != nil {
+				return err
+			}
+
 			c.Printf("<yellow>Checking Anime Movies vs Movies</>\n")
-			err := FindAndCombineAnime(content.Libraries["video-anime-movies"], content.Libraries["video-movies"], content.LibraryTypeMovies)
+			err = FindAndCombineAnime(cmd.Context(), animeMoviesLib, moviesLib, content.LibraryTypeMovies)
 			if err != nil {
 				return err
 			}
 
 			fmt.Println()
 			c.Printf("<yellow>Checking Anime Series vs TV</>\n")
-			err = FindAndCombineAnime(content.Libraries["video-anime-series"], content.Libraries["video-tv"], content.LibraryTypeSeries)
+			err = FindAndCombineAnime(cmd.Context(), animeSeriesLib, tvLib, content.LibraryTypeSeries)
 			if err != nil {
 				return err
 			}
@@ -180,6 +253,198 @@ func Make(cmdName string) (*cobra.Command, error) {
 		},
 	})
 
+	// test folder rename rules
+	renames := &cobra.Command{
+		Use:           "renames",
+		Short:         cmdName + " work with the folder rename rules",
+		SilenceErrors: true,
+	}
+	renamesTest := &cobra.Command{
+		Use:           "test <folder>",
+		Short:         cmdName + " show what a folder would be renamed to",
+		Long:          `Runs a folder name through the rename rules for a library type and prints the resulting folder name and which rule matched.`,
+		Args:          cobra.ExactArgs(1),
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			libType, err := cmd.Flags().GetString("type")
+			if err != nil {
+				return err
+			}
+			return TestRename(libType, args[0])
+		},
+	}
+	renamesTest.Flags().StringP("type", "t", "movies", "library type rules to test against (movies, series, standup)")
+	renames.AddCommand(renamesTest)
+	root.AddCommand(renames)
+
+	// list and undo journaled operations
+	journalCmd := &cobra.Command{
+		Use:           "journal [session]",
+		Short:         cmdName + " list recent sessions or the operations of a session from the journal",
+		Long:          `Lists the most recent sessions recorded in the operation journal, or every move, rename and delete performed by a single session.`,
+		Args:          cobra.MaximumNArgs(1),
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			limit, err := cmd.Flags().GetInt("limit")
+			if err != nil {
+				return err
+			}
+			session := ""
+			if len(args) > 0 {
+				session = args[0]
+			}
+			return ListJournal(session, limit)
+		},
+	}
+	journalCmd.Flags().Int("limit", 20, "number of sessions to list")
+	root.AddCommand(journalCmd)
+
+	root.AddCommand(&cobra.Command{
+		Use:           "undo [session]",
+		Short:         cmdName + " reverse the operations of a session (default the last one)",
+		Long:          `Replays the moves and renames of a journaled session in reverse and restores deleted items that went to the trash. Permanently deleted items are reported and skipped.`,
+		Args:          cobra.MaximumNArgs(1),
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			session := ""
+			if len(args) > 0 {
+				session = args[0]
+			}
+			return Undo(session)
+		},
+	})
+
+	// manage items that were deleted or replaced
+	trash := &cobra.Command{
+		Use:           "trash",
+		Short:         cmdName + " list, restore and purge deleted items in the trash folders",
+		SilenceErrors: true,
+	}
+	trash.AddCommand(&cobra.Command{
+		Use:           "list",
+		Short:         cmdName + " list items in the trash",
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return ListTrash()
+		},
+	})
+	trash.AddCommand(&cobra.Command{
+		Use:           "restore <id>...",
+		Short:         cmdName + " move items in the trash back to where they were deleted from",
+		Args:          cobra.MinimumNArgs(1),
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RestoreTrash(args)
+		},
+	})
+	trashPurge := &cobra.Command{
+		Use:           "purge",
+		Short:         cmdName + " permanently delete items from the trash",
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			olderThan, err := cmd.Flags().GetString("older-than")
+			if err != nil {
+				return err
+			}
+			return PurgeTrash(olderThan)
+		},
+	}
+	trashPurge.Flags().String("older-than", "30d", "only purge items deleted longer ago than this (ie 30d, 2w, 12h)")
+	trash.AddCommand(trashPurge)
+	root.AddCommand(trash)
+
+	// manage the ffprobe result cache
+	cache := &cobra.Command{
+		Use:           "cache",
+		Short:         cmdName + " show, prune and warm the ffprobe result cache",
+		SilenceErrors: true,
+	}
+	cache.AddCommand(&cobra.Command{
+		Use:           "stats",
+		Short:         cmdName + " show the number of cached results and the size of the cache",
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return CacheStats()
+		},
+	})
+	cache.AddCommand(&cobra.Command{
+		Use:           "prune",
+		Short:         cmdName + " remove cached results for videos that were deleted or have changed",
+		Long:          `Removes cached ffprobe results for videos that no longer exist or whose size, modification time or inode has changed. Make sure all libraries are mounted first or their entries will be pruned.`,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return PruneCache()
+		},
+	})
+	cacheWarm := &cobra.Command{
+		Use:           "warm <library>",
+		Short:         cmdName + " probe every video in a library so later runs are answered from the cache",
+		Args:          cobra.ExactArgs(1),
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			workers, err := cmd.Flags().GetInt("workers")
+			if err != nil {
+				return err
+			}
+			return WarmCache(cmd.Context(), args[0], workers)
+		},
+	}
+	cacheWarm.Flags().Int("workers", 0, "number of videos to probe at once on each filesystem (default --probe-workers)")
+	cache.AddCommand(cacheWarm)
+	root.AddCommand(cache)
+
+	// check libraries against their config
+	audit := &cobra.Command{
+		Use:           "audit",
+		Short:         cmdName + " check the videos in a library against its requirements",
+		SilenceErrors: true,
+	}
+	audit.AddCommand(&cobra.Command{
+		Use:           "languages <library>",
+		Short:         cmdName + " list videos missing any of the library's required audio or subtitle languages",
+		Long:          `Lists every video in the library that is missing one of the audio or subtitle languages set with languages in its config. Subtitle files next to a video (ie Show - 01x01 - Title.en.srt) count as well as embedded ones.`,
+		Args:          cobra.ExactArgs(1),
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return AuditLanguages(cmd.Context(), args[0])
+		},
+	})
+	root.AddCommand(audit)
+
+	verify := &cobra.Command{
+		Use:           "verify <library|import>",
+		Aliases:       []string{"check-integrity"},
+		Short:         cmdName + " report corrupt or truncated videos in a library or an import mapping's source",
+		Long:          `Flags videos that fail to probe or have no video stream, are far shorter than the runtime in their nfo or the other episodes of their season, or whose last seconds fail to decode with ffmpeg. With --quarantine the flagged videos are moved into that folder keeping their path within the library.`,
+		Args:          cobra.ExactArgs(1),
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			quarantine, err := cmd.Flags().GetString("quarantine")
+			if err != nil {
+				return err
+			}
+			noDecode, err := cmd.Flags().GetBool("no-decode")
+			if err != nil {
+				return err
+			}
+			return VerifyLibrary(cmd.Context(), args[0], quarantine, !noDecode)
+		},
+	}
+	verify.Flags().String("quarantine", "", "move flagged videos into this folder")
+	verify.Flags().Bool("no-decode", false, "skip decoding the end of every video (faster on network mounts)")
+	root.AddCommand(verify)
+
+	root.AddCommand(&cobra.Command{
+		Use:           "normalize <library|path>",
+		Short:         cmdName + " rename season folders and episode files to the library convention",
+		Long:          `Renames season folders to "Show - s01 (2019)" and episode files to "Show - 01x02 - Title", including multi episode files (01x01-02) and the subtitles and nfo named after them. Titles come from the existing file names or episode nfo files. The renames are shown in a table before being applied.`,
+		Args:          cobra.ExactArgs(1),
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return Normalize(cmd.Context(), args[0])
+		},
+	})
+
 	if err := configureFlags(root); err != nil {
 		return nil, fmt.Errorf("unable to configure flags: %w", err)
 	}
@@ -0,0 +1,191 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/katbyte/go-ingest-media/lib/content"
+	"github.com/spf13/viper"
+)
+
+// LibraryConfig is a single library definition in the config file
+type LibraryConfig struct {
+	Path          string             `mapstructure:"path"`
+	Type          string             `mapstructure:"type"`
+	LetterFolders bool               `mapstructure:"letter-folders"`
+	Policy        []PolicyRuleConfig `mapstructure:"policy"`
+	Languages     LanguagesConfig    `mapstructure:"languages"`
+	Lettering     LetteringConfig    `mapstructure:"lettering"`
+}
+
+// LanguagesConfig lists the audio and subtitle languages every video in a library must have
+type LanguagesConfig struct {
+	Audio     []string `mapstructure:"audio"`
+	Subtitles []string `mapstructure:"subtitles"`
+}
+
+// LetteringConfig sets how titles are sorted into letter folders, anything unset uses the default
+type LetteringConfig struct {
+	Articles []string `mapstructure:"articles"`
+	Digits   string   `mapstructure:"digits"`
+	Symbols  string   `mapstructure:"symbols"`
+}
+
+// PolicyRuleConfig is a single conflict resolution rule for a library, all conditions must hold for the decision to apply
+type PolicyRuleConfig struct {
+	Name     string            `mapstructure:"name"`
+	When     map[string]string `mapstructure:"when"`
+	Decision string            `mapstructure:"decision"`
+}
+
+// ImportConfig maps a source library to a destination library by name
+type ImportConfig struct {
+	Source string `mapstructure:"source"`
+	Dest   string `mapstructure:"dest"`
+}
+
+// ProfileConfig is a named set of libraries and import mappings
+type ProfileConfig struct {
+	Libraries map[string]LibraryConfig `mapstructure:"libraries"`
+	Imports   map[string]ImportConfig  `mapstructure:"imports"`
+}
+
+// loadConfig reads the config file (if any) and populates the content libraries from the selected profile
+func loadConfig() error {
+	f := GetFlags()
+
+	if f.Config != "" {
+		viper.SetConfigFile(f.Config)
+	} else {
+		viper.SetConfigName("config")
+		if home, err := os.UserHomeDir(); err == nil {
+			viper.AddConfigPath(filepath.Join(home, ".config", "go-ingest-media"))
+		}
+		viper.AddConfigPath(".")
+	}
+
+	if err := viper.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if f.Config == "" && errors.As(err, &notFound) {
+			// no config is fine until a command needs a library
+			return loadRenameRules()
+		}
+		return fmt.Errorf("error reading config file: %w", err)
+	}
+
+	if err := loadRenameRules(); err != nil {
+		return err
+	}
+	if err := loadScoreWeights(); err != nil {
+		return err
+	}
+
+	// the profile can come from the flag, env or the config file itself
+	profile := viper.GetString("profile")
+	key := "profiles." + profile
+	if !viper.IsSet(key) {
+		return fmt.Errorf("profile %q not found in config file %s", profile, viper.ConfigFileUsed())
+	}
+
+	var p ProfileConfig
+	if err := viper.UnmarshalKey(key, &p); err != nil {
+		return fmt.Errorf("error parsing profile %q: %w", profile, err)
+	}
+
+	return applyProfile(p)
+}
+
+// applyProfile validates a profile and replaces the known content libraries and import mappings
+func applyProfile(p ProfileConfig) error {
+	libraries := map[string]*content.Library{}
+	for name, lc := range p.Libraries {
+		if lc.Path == "" {
+			return fmt.Errorf("library %q: path is required", name)
+		}
+
+		t, err := content.LibraryTypeFor(lc.Type)
+		if err != nil {
+			return fmt.Errorf("library %q: %w", name, err)
+		}
+
+		policy := make(content.Policy, 0, len(lc.Policy))
+		for i, rc := range lc.Policy {
+			r, err := content.NewPolicyRule(rc.Name, rc.When, rc.Decision)
+			if err != nil {
+				return fmt.Errorf("library %q policy rule %d: %w", name, i+1, err)
+			}
+			policy = append(policy, *r)
+		}
+
+		languages, err := content.NewLanguageRequirements(lc.Languages.Audio, lc.Languages.Subtitles)
+		if err != nil {
+			return fmt.Errorf("library %q languages: %w", name, err)
+		}
+
+		lettering, err := content.NewLettering(lc.Lettering.Articles, lc.Lettering.Digits, lc.Lettering.Symbols)
+		if err != nil {
+			return fmt.Errorf("library %q lettering: %w", name, err)
+		}
+
+		libraries[name] = &content.Library{
+			Name:          name,
+			Path:          lc.Path,
+			Type:          t,
+			LetterFolders: lc.LetterFolders,
+			Policy:        policy,
+			Languages:     languages,
+			Lettering:     lettering,
+		}
+	}
+
+	mappings := map[string]content.LibraryMapping{}
+	for name, ic := range p.Imports {
+		src, ok := libraries[ic.Source]
+		if !ok {
+			return fmt.Errorf("import %q: source library %q is not defined", name, ic.Source)
+		}
+		dst, ok := libraries[ic.Dest]
+		if !ok {
+			return fmt.Errorf("import %q: dest library %q is not defined", name, ic.Dest)
+		}
+		if src.Type != dst.Type {
+			return fmt.Errorf("import %q: source type %s does not match dest type %s", name, src.Type, dst.Type)
+		}
+
+		mappings[name] = content.LibraryMapping{Source: src, Dest: dst}
+	}
+
+	content.Libraries = libraries
+	content.ImportMappings = mappings
+
+	return nil
+}
+
+// loadRenameRules replaces the built in folder rename rules if a rules file has been configured
+func loadRenameRules() error {
+	path := viper.GetString("renames")
+	if path == "" {
+		return nil
+	}
+
+	return content.LoadRenameRules(path)
+}
+
+// loadScoreWeights replaces the default quality score weights with any set in the config, unset weights keep their default
+func loadScoreWeights() error {
+	if !viper.IsSet("score-weights") {
+		return nil
+	}
+
+	w := content.DefaultScoreWeights
+	if err := viper.UnmarshalKey("score-weights", &w); err != nil {
+		return fmt.Errorf("error parsing score-weights: %w", err)
+	}
+	if err := content.SetScoreWeights(w); err != nil {
+		return fmt.Errorf("score-weights: %w", err)
+	}
+
+	return nil
+}
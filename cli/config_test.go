@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/katbyte/go-ingest-media/lib/content"
+	"github.com/spf13/viper"
+)
+
+const profilesConfig = `
+profiles:
+  default:
+    libraries:
+      default-movies: { path: /mnt/default/movies, type: movies }
+  home:
+    libraries:
+      home-movies: { path: /mnt/home/movies, type: movies }
+  work:
+    libraries:
+      work-movies:    { path: /mnt/work/movies, type: movies }
+      work-downloads: { path: /mnt/work/downloads, type: movies }
+    imports:
+      work: { source: work-downloads, dest: work-movies }
+`
+
+// loadTestConfig writes the config to a temporary file and loads it as the root command would, with the flags given
+// as name=value
+func loadTestConfig(t *testing.T, config string, flags ...string) error {
+	t.Helper()
+
+	viper.Reset()
+	t.Cleanup(func() {
+		viper.Reset()
+		content.Libraries = map[string]*content.Library{}
+		content.ImportMappings = map[string]content.LibraryMapping{}
+	})
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	root, err := Make("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	flags = append(flags, "config="+path)
+	for _, f := range flags {
+		name, value, _ := strings.Cut(f, "=")
+		if err := root.PersistentFlags().Set(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return loadConfig()
+}
+
+func TestLoadConfigProfile(t *testing.T) {
+	cases := []struct {
+		name    string
+		config  string // added to the top of the config file
+		env     string
+		flags   []string
+		library string
+	}{
+		{name: "default", library: "default-movies"},
+		{name: "config key", config: "profile: home\n", library: "home-movies"},
+		{name: "env over config key", config: "profile: home\n", env: "work", library: "work-movies"},
+		{name: "flag over env", config: "profile: home\n", env: "work", flags: []string{"profile=home"}, library: "home-movies"},
+		{name: "flag over config key", config: "profile: work\n", flags: []string{"profile=default"}, library: "default-movies"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.env != "" {
+				t.Setenv("INGEST_PROFILE", tc.env)
+			}
+
+			if err := loadTestConfig(t, tc.config+profilesConfig, tc.flags...); err != nil {
+				t.Fatal(err)
+			}
+			if len(content.Libraries) == 0 || content.Libraries[tc.library] == nil {
+				t.Errorf("got libraries %v, want %s", content.Libraries, tc.library)
+			}
+		})
+	}
+}
+
+func TestLoadConfigImports(t *testing.T) {
+	if err := loadTestConfig(t, profilesConfig, "profile=work"); err != nil {
+		t.Fatal(err)
+	}
+
+	m, ok := content.ImportMappings["work"]
+	if !ok {
+		t.Fatalf("got mappings %v, want work", content.ImportMappings)
+	}
+	if m.Source.Name != "work-downloads" || m.Dest.Name != "work-movies" {
+		t.Errorf("got %s -> %s, want work-downloads -> work-movies", m.Source.Name, m.Dest.Name)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		config string
+		flags  []string
+		want   string
+	}{
+		{
+			name:   "unknown profile",
+			config: profilesConfig,
+			flags:  []string{"profile=nope"},
+			want:   `profile "nope" not found`,
+		},
+		{
+			name: "unknown source library",
+			config: `
+profiles:
+  default:
+    libraries:
+      movies: { path: /mnt/movies, type: movies }
+    imports:
+      movies: { source: downloads, dest: movies }
+`,
+			want: `import "movies": source library "downloads" is not defined`,
+		},
+		{
+			name: "unknown dest library",
+			config: `
+profiles:
+  default:
+    libraries:
+      downloads: { path: /mnt/downloads, type: movies }
+    imports:
+      movies: { source: downloads, dest: movies }
+`,
+			want: `import "movies": dest library "movies" is not defined`,
+		},
+		{
+			name: "mismatched library types",
+			config: `
+profiles:
+  default:
+    libraries:
+      downloads: { path: /mnt/downloads, type: series }
+      movies:    { path: /mnt/movies, type: movies }
+    imports:
+      movies: { source: downloads, dest: movies }
+`,
+			want: `import "movies": source type`,
+		},
+		{
+			name: "library without a path",
+			config: `
+profiles:
+  default:
+    libraries:
+      movies: { type: movies }
+`,
+			want: `library "movies": path is required`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := loadTestConfig(t, tc.config, tc.flags...)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("got %v, want an error containing %q", err, tc.want)
+			}
+		})
+	}
+}
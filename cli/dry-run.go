@@ -0,0 +1,34 @@
+package cli
+
+import (
+	c "github.com/gookit/color"
+	"github.com/katbyte/go-ingest-media/lib/ktio"
+)
+
+// startDryRun switches all file operations over to the planner if dry-run has been requested
+func startDryRun() {
+	if !GetFlags().DryRun {
+		return
+	}
+
+	ktio.EnableDryRun()
+	c.Println("<yellow>DRY RUN:</> no files will be moved or deleted, all actions are only planned")
+	c.Println()
+}
+
+// finishDryRun prints the planned actions and writes them to the plan file if one was given
+func finishDryRun() {
+	if !ktio.DryRun() {
+		return
+	}
+
+	ktio.PrintPlan()
+
+	if f := GetFlags(); f.PlanFile != "" {
+		if err := ktio.WritePlan(f.PlanFile); err != nil {
+			c.Printf("  <red>ERROR:</> %s\n", err)
+			return
+		}
+		c.Printf("  plan written to <lightBlue>%s</>\n", f.PlanFile)
+	}
+}
@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	c "github.com/gookit/color"
+	"github.com/katbyte/go-ingest-media/lib/content"
+	"github.com/katbyte/go-ingest-media/lib/ktio"
+)
+
+// startFileOps configures verification and the free space margin for all file operations
+func startFileOps() error {
+	f := GetFlags()
+
+	ktio.SetVerify(f.Verify)
+	if err := ktio.SetVerifyHash(f.VerifyHash); err != nil {
+		return fmt.Errorf("invalid --verify-hash: %w", err)
+	}
+
+	margin, err := parseSize(f.SpaceMargin)
+	if err != nil {
+		return fmt.Errorf("invalid --space-margin %q: %w", f.SpaceMargin, err)
+	}
+	ktio.SetSpaceMargin(margin)
+	ktio.SetProgress(copyProgress())
+
+	return nil
+}
+
+// copyProgress returns a function that shows the percentage copied of a cross device move on a single line, the line
+// is cleared when the copy is done
+func copyProgress() ktio.ProgressFunc {
+	lastPct := -1
+	return func(path string, done, total int64) {
+		if total == 0 {
+			return
+		}
+
+		pct := int(done * 100 / total)
+		if pct == lastPct {
+			return
+		}
+		lastPct = pct
+
+		if done >= total {
+			lastPct = -1
+			fmt.Print("\r\033[K")
+			return
+		}
+		c.Printf("\r\033[K    <yellow>copying %s %d%%</>", filepath.Base(path), pct)
+	}
+}
+
+// parseSize parses a byte size with an optional K, M, G or T suffix (powers of 1024)
+func parseSize(s string) (int64, error) {
+	s = strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(s)), "B")
+	if s == "" {
+		return 0, nil
+	}
+
+	mult := int64(1)
+	switch s[len(s)-1] {
+	case 'K':
+		mult = 1 << 10
+	case 'M':
+		mult = 1 << 20
+	case 'G':
+		mult = 1 << 30
+	case 'T':
+		mult = 1 << 40
+	}
+	if mult > 1 {
+		s = s[:len(s)-1]
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	if v < 0 {
+		return 0, errors.New("size can't be negative")
+	}
+
+	return int64(v * float64(mult)), nil
+}
+
+// preflightSpace reports how much data a mapping will move onto the destination filesystem and warns if it won't all fit,
+// each move is still checked on its own and skipped if it doesn't fit
+func preflightSpace(src, dst *content.Library) {
+	folders, err := ktio.ListFolders(src.Path)
+	if err != nil {
+		return // the import itself will report this
+	}
+
+	var need int64
+	for _, folder := range folders {
+		cost, err := ktio.MoveCost(folder, dst.Path)
+		if err != nil {
+			c.Printf("  <red>ERROR:</> %s\n", err)
+			continue
+		}
+		need += cost
+	}
+
+	if need == 0 {
+		return
+	}
+
+	if err := ktio.CheckFree(dst.Path, need); err != nil {
+		c.Printf("  <yellow>WARNING:</> %s, items that don't fit will be skipped\n", err)
+		return
+	}
+
+	free, _ := ktio.FreeSpace(dst.Path)
+	c.Printf("  <darkGray>%s to copy, %s free</>\n", ktio.FormatBytes(need), ktio.FormatBytes(free))
+}
+
+// replaceFits checks the source paths fit on the destination filesystem before the videos they replace are deleted,
+// printing why not so a replace that can't complete leaves the destination as it was
+func replaceFits(indent int, name, dst string, paths ...string) bool {
+	var need int64
+	for _, p := range paths {
+		cost, err := ktio.MoveCost(p, dst)
+		if err != nil {
+			c.Printf("%s<red>ERROR:</> %s\n", strings.Repeat(" ", indent), err)
+			return false
+		}
+		need += cost
+	}
+
+	err := ktio.CheckFree(dst, need)
+	switch {
+	case errors.Is(err, ktio.ErrInsufficientSpace):
+		c.Printf("%s<yellow>SKIPPED:</> %s does not fit: %s\n", strings.Repeat(" ", indent), name, err)
+	case err != nil:
+		c.Printf("%s<red>ERROR:</> checking space for %s: %s\n", strings.Repeat(" ", indent), name, err)
+	}
+	return err == nil
+}
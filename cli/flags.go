@@ -2,26 +2,61 @@ package cli
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 type FlagData struct {
-	Prompt         bool
-	IgnoreExisting bool
-	RadarrUrl      string
-	RadarrApiKey   string
-	RadarrBasePath string
-	RadarrPathMaps []string
+	Config          string
+	Profile         string
+	Renames         string
+	Prompt          bool
+	DryRun          bool
+	PlanFile        string
+	Journal         string
+	Trash           []string
+	NoTrash         bool
+	Verify          bool
+	VerifyHash      string
+	SpaceMargin     string
+	ProbeCache      string
+	NoProbeCache    bool
+	ProbeWorkers    int
+	Prober          string
+	CompareFrames   bool
+	IgnoreExisting  bool
+	NoReleaseRename bool
+	RadarrUrl       string
+	RadarrApiKey    string
+	RadarrBasePath  string
+	RadarrPathMaps  []string
 }
 
 func configureFlags(root *cobra.Command) error {
 	flags := FlagData{}
 	pflags := root.PersistentFlags()
 
+	pflags.StringVarP(&flags.Config, "config", "c", "", "path to the config file (default $HOME/.config/go-ingest-media/config.yaml or ./config.yaml)")
+	pflags.StringVar(&flags.Profile, "profile", "default", "config profile to load libraries and import mappings from")
+	pflags.StringVar(&flags.Renames, "renames", "", "path to a folder rename rules file (replaces the built in rules)")
 	pflags.BoolVarP(&flags.Prompt, "prompt", "p", false, "prompt for confirmation before each file operation")
+	pflags.BoolVarP(&flags.DryRun, "dry-run", "n", false, "plan all file operations without touching the filesystem and print the plan at the end")
+	pflags.StringVar(&flags.PlanFile, "plan-file", "", "write the dry-run plan as json to this file (implies --dry-run)")
+	pflags.StringVar(&flags.Journal, "journal", "", "path to the operation journal database (default $HOME/.config/go-ingest-media/journal.db)")
+	pflags.StringArrayVar(&flags.Trash, "trash", nil, "trash folder deleted and replaced items are moved into, one per filesystem, repeatable, deletes are permanent when none are set")
+	pflags.BoolVar(&flags.NoTrash, "no-trash", false, "permanently delete items instead of moving them to the trash")
+	pflags.BoolVar(&flags.Verify, "verify", false, "checksum cross filesystem moves before removing the source, the source is kept on a mismatch")
+	pflags.StringVar(&flags.VerifyHash, "verify-hash", "sha256", "hash used by --verify: sha256 or xxhash (much faster, not cryptographic)")
+	pflags.StringVar(&flags.SpaceMargin, "space-margin", "1G", "free space to leave on the destination filesystem, moves that would go below it are skipped (ie 500M, 10G)")
+	pflags.StringVar(&flags.ProbeCache, "probe-cache", "", "path to the ffprobe result cache database (default $HOME/.cache/go-ingest-media/ffprobe.db)")
+	pflags.BoolVar(&flags.NoProbeCache, "no-probe-cache", false, "always run ffprobe instead of using cached results")
+	pflags.IntVar(&flags.ProbeWorkers, "probe-workers", 4, "number of videos to probe at once on each filesystem (see probe-limits in the config for per mount limits)")
+	pflags.StringVar(&flags.Prober, "prober", "ffprobe", "how video details are read: ffprobe (falling back to reading mkv/mp4 headers) or native (headers first, ffprobe for anything else)")
+	pflags.BoolVar(&flags.CompareFrames, "compare-frames", false, "sample frames with ffmpeg to check compared videos are the same content, a different cut or different content")
 	pflags.BoolVarP(&flags.IgnoreExisting, "ignore-existing", "i", false, "skip items that already exist at the destination")
+	pflags.BoolVar(&flags.NoReleaseRename, "no-release-rename", false, "leave raw release names (ie The.Movie.2019.1080p.BluRay.x265-GRP) in import sources instead of offering to rename them")
 	pflags.StringVar(&flags.RadarrUrl, "radarr-url", "", "Radarr API URL (e.g. http://localhost:7878)")
 	pflags.StringVar(&flags.RadarrApiKey, "radarr-api-key", "", "Radarr API Key")
 	pflags.StringVar(&flags.RadarrBasePath, "radarr-base-path", "", "Base path for Radarr (e.g. /mnt/video)")
@@ -29,12 +64,29 @@ func configureFlags(root *cobra.Command) error {
 
 	// binding map for viper/pflag -> env
 	m := map[string]string{
-		"prompt":           "INGEST_PROMPT",
-		"ignore-existing":  "INGEST_IGNORE_EXISTING",
-		"radarr-url":       "RADARR_URL",
-		"radarr-api-key":   "RADARR_API_KEY",
-		"radarr-base-path": "RADARR_BASE_PATH",
-		"radarr-path-map":  "",
+		"config":            "INGEST_CONFIG",
+		"profile":           "INGEST_PROFILE",
+		"renames":           "INGEST_RENAMES",
+		"prompt":            "INGEST_PROMPT",
+		"dry-run":           "INGEST_DRY_RUN",
+		"plan-file":         "INGEST_PLAN_FILE",
+		"journal":           "INGEST_JOURNAL",
+		"trash":             "INGEST_TRASH",
+		"no-trash":          "INGEST_NO_TRASH",
+		"verify":            "INGEST_VERIFY",
+		"verify-hash":       "INGEST_VERIFY_HASH",
+		"space-margin":      "INGEST_SPACE_MARGIN",
+		"probe-cache":       "INGEST_PROBE_CACHE",
+		"no-probe-cache":    "INGEST_NO_PROBE_CACHE",
+		"probe-workers":     "INGEST_PROBE_WORKERS",
+		"prober":            "INGEST_PROBER",
+		"compare-frames":    "INGEST_COMPARE_FRAMES",
+		"ignore-existing":   "INGEST_IGNORE_EXISTING",
+		"no-release-rename": "INGEST_NO_RELEASE_RENAME",
+		"radarr-url":        "RADARR_URL",
+		"radarr-api-key":    "RADARR_API_KEY",
+		"radarr-base-path":  "RADARR_BASE_PATH",
+		"radarr-path-map":   "",
 	}
 
 	for name, env := range m {
@@ -54,11 +106,41 @@ func configureFlags(root *cobra.Command) error {
 
 func GetFlags() FlagData {
 	return FlagData{
-		Prompt:         viper.GetBool("prompt"),
-		IgnoreExisting: viper.GetBool("ignore-existing"),
-		RadarrUrl:      viper.GetString("radarr-url"),
-		RadarrApiKey:   viper.GetString("radarr-api-key"),
-		RadarrBasePath: viper.GetString("radarr-base-path"),
-		RadarrPathMaps: viper.GetStringSlice("radarr-path-map"),
+		Config:          viper.GetString("config"),
+		Profile:         viper.GetString("profile"),
+		Renames:         viper.GetString("renames"),
+		Prompt:          viper.GetBool("prompt"),
+		DryRun:          viper.GetBool("dry-run") || viper.GetString("plan-file") != "",
+		PlanFile:        viper.GetString("plan-file"),
+		Journal:         viper.GetString("journal"),
+		Trash:           trashDirs(viper.GetStringSlice("trash")),
+		NoTrash:         viper.GetBool("no-trash"),
+		Verify:          viper.GetBool("verify"),
+		VerifyHash:      viper.GetString("verify-hash"),
+		SpaceMargin:     viper.GetString("space-margin"),
+		ProbeCache:      viper.GetString("probe-cache"),
+		NoProbeCache:    viper.GetBool("no-probe-cache"),
+		ProbeWorkers:    viper.GetInt("probe-workers"),
+		Prober:          viper.GetString("prober"),
+		CompareFrames:   viper.GetBool("compare-frames"),
+		IgnoreExisting:  viper.GetBool("ignore-existing"),
+		NoReleaseRename: viper.GetBool("no-release-rename"),
+		RadarrUrl:       viper.GetString("radarr-url"),
+		RadarrApiKey:    viper.GetString("radarr-api-key"),
+		RadarrBasePath:  viper.GetString("radarr-base-path"),
+		RadarrPathMaps:  viper.GetStringSlice("radarr-path-map"),
 	}
 }
+
+// trashDirs splits the trash folders, INGEST_TRASH is a list of paths like PATH ie /mnt/video/.trash:/mnt/ztmp/.trash
+func trashDirs(values []string) []string {
+	var dirs []string
+	for _, v := range values {
+		for _, d := range filepath.SplitList(v) {
+			if d != "" {
+				dirs = append(dirs, d)
+			}
+		}
+	}
+	return dirs
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/katbyte/go-ingest-media/lib/content"
 	"github.com/katbyte/go-ingest-media/lib/ktio"
+	"github.com/spf13/afero"
 )
 
 // FolderInfo holds a quick summary of a movie folder's contents (no ffprobe)
@@ -47,14 +48,14 @@ var extrasSubfolders = []string{
 func ScanFolder(folderPath string) FolderInfo {
 	info := FolderInfo{Path: folderPath}
 
-	stat, err := os.Stat(folderPath)
+	stat, err := ktio.Stat(folderPath)
 	if err != nil {
 		return info
 	}
 	info.Exists = true
 	info.ModTime = stat.ModTime()
 
-	entries, err := os.ReadDir(folderPath)
+	entries, err := afero.ReadDir(ktio.FS(), folderPath)
 	if err != nil {
 		return info
 	}
@@ -76,13 +77,8 @@ func ScanFolder(folderPath string) FolderInfo {
 			continue
 		}
 
-		fi, err := entry.Info()
-		if err != nil {
-			continue
-		}
-
 		ext := strings.ToLower(filepath.Ext(entry.Name()))
-		size := fi.Size()
+		size := entry.Size()
 		info.TotalSize += size
 
 		switch {
@@ -117,7 +113,7 @@ func isExtrasFolder(name string) bool {
 }
 
 func listFilesIn(dir string) []string {
-	entries, err := os.ReadDir(dir)
+	entries, err := afero.ReadDir(ktio.FS(), dir)
 	if err != nil {
 		return nil
 	}
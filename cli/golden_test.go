@@ -0,0 +1,494 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/katbyte/go-ingest-media/lib/content"
+	"github.com/katbyte/go-ingest-media/lib/content/contenttest"
+	"github.com/katbyte/go-ingest-media/lib/ktio"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata/golden")
+
+// videoTree is an in memory filesystem the commands run against end to end
+type videoTree struct {
+	t  *testing.T
+	fs afero.Fs
+}
+
+// newVideoTree builds an in memory filesystem from a contenttest spec, videos are probed as the fixture named in the
+// spec which is also their contents so videos of the same fixture are the same size
+func newVideoTree(t *testing.T, spec string) *videoTree {
+	t.Helper()
+
+	vt := &videoTree{t: t, fs: afero.NewMemMapFs()}
+
+	ktio.SetFS(vt.fs)
+	t.Cleanup(func() {
+		ktio.SetFS(nil)
+		ktio.SetKeyInput(nil)
+	})
+	contenttest.New(t, "/").Spec(spec)
+
+	// policy decisions are journaled, keep them out of the real one
+	viper.Set("journal", filepath.Join(t.TempDir(), "journal.db"))
+	t.Cleanup(func() {
+		closeJournal()
+		viper.Set("journal", "")
+	})
+
+	return vt
+}
+
+// fixtureContext probes videos as the saved fixture named by their contents
+func fixtureContext() context.Context {
+	return content.WithProber(context.Background(), content.FixtureProber{Dir: contenttest.Fixtures})
+}
+
+// keys answers the prompts in order, running out of keys fails the prompt
+func (vt *videoTree) keys(keys string) {
+	ktio.SetKeyInput(strings.NewReader(keys))
+}
+
+// tree renders everything under root in the same format as the spec
+func (vt *videoTree) tree(root string) string {
+	vt.t.Helper()
+
+	var lines []string
+	err := afero.Walk(vt.fs, root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || path == root {
+			return err
+		}
+
+		if info.IsDir() {
+			lines = append(lines, path+"/")
+			return nil
+		}
+
+		b, err := afero.ReadFile(vt.fs, path)
+		if err != nil {
+			return err
+		}
+		if len(b) > 0 {
+			path += " = " + string(b)
+		}
+		lines = append(lines, path)
+		return nil
+	})
+	if err != nil {
+		vt.t.Fatal(err)
+	}
+
+	sort.Strings(lines)
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// assertGolden compares got to testdata/golden/<name>.golden, run with -update to rewrite it
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", "golden", name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%s (run with -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s does not match (run with -update to accept)\n--- got ---\n%s--- want ---\n%s", path, got, want)
+	}
+}
+
+func TestLibraryContentsGolden(t *testing.T) {
+	vt := newVideoTree(t, `
+		/mnt/video/movies/a/Alien (1979)/Alien (1979).mkv = h264-1080p.mkv
+		/mnt/video/movies/a/Alien (1979)/movie.nfo
+		/mnt/video/movies/a/ Avatar (2009) /Avatar (2009).mkv = hevc-2160p.mkv
+		/mnt/video/movies/h/Heat (1995) (2010)/Heat (1995).mkv = hevc-1080p.mkv
+		/mnt/video/movies/m/The Matrix (1999)/
+		/mnt/video/movies/s/Solaris/Solaris.avi = h264-480p.avi
+		/mnt/video/movies/x/Zodiac (2007)/Zodiac (2007).mkv = hevc-1080p.mkv
+	`)
+
+	// zodiac is filed under the wrong letter, its path is where it is not where it should be
+	lib := &content.Library{Name: "video-movies", Path: "/mnt/video/movies", Type: content.LibraryTypeMovies, LetterFolders: true}
+
+	var out strings.Builder
+	contents, err := lib.Contents(func(folder string, err error) {
+		out.WriteString("error: " + filepath.Base(folder) + ": " + err.Error() + "\n")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ci := range contents {
+		m := ci.(*content.Movie)
+		out.WriteString("movie: " + m.Letter + " | " + m.Folder + " | " + m.Path() + "\n")
+	}
+	out.WriteString("\n" + vt.tree("/mnt/video"))
+
+	assertGolden(t, "library-contents", out.String())
+}
+
+func TestFixLetteringGolden(t *testing.T) {
+	vt := newVideoTree(t, `
+		/mnt/video/downloads/movies/
+		/mnt/video/movies/a/Avatar (2009)/Avatar (2009).mkv = hevc-2160p.mkv
+		/mnt/video/movies/b/Alien (1979)/Alien (1979).mkv = h264-1080p.mkv
+		/mnt/video/movies/x/The Matrix (1999)/The Matrix (1999).mkv = hevc-1080p.mkv
+		/mnt/video/movies/z/Zodiac (2007)/Zodiac (2007).mkv = h264-1080p.mkv
+		/mnt/video/movies/z/Heat (1995)/Heat (1995).mkv = hevc-1080p.mkv
+	`)
+
+	src := &content.Library{Name: "video-movies", Path: "/mnt/video/movies", Type: content.LibraryTypeMovies, LetterFolders: true}
+	dst := &content.Library{Name: "import-movies", Path: "/mnt/video/downloads/movies", Type: content.LibraryTypeMovies}
+
+	// move alien, skip the matrix, move heat
+	vt.keys("msm")
+
+	sb := ktio.NewStatusBar()
+	defer sb.Close()
+
+	if err := FixLettering(src, dst, sb); err != nil {
+		t.Fatal(err)
+	}
+
+	assertGolden(t, "fix-lettering", vt.tree("/mnt/video"))
+}
+
+func TestFixLetteringReletterGolden(t *testing.T) {
+	vt := newVideoTree(t, `
+		/mnt/video/downloads/movies/
+		/mnt/video/movies/@/Élite (2018)/Élite (2018).mkv = hevc-1080p.mkv
+		/mnt/video/movies/@/Ørnen (2004)/Ørnen (2004).mkv = h264-1080p.mkv
+		/mnt/video/movies/0/1917 (2019)/1917 (2019).mkv = hevc-2160p.mkv
+		/mnt/video/movies/d/Der Untergang (2004)/Der Untergang (2004).mkv = h264-1080p-dts.mkv
+		/mnt/video/movies/m/The Matrix (1999)/The Matrix (1999).mkv = hevc-2160p-hdr10.mkv
+	`)
+
+	lettering, err := content.NewLettering([]string{"The", "Der"}, "0-9", "#")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := &content.Library{Name: "video-movies", Path: "/mnt/video/movies", Type: content.LibraryTypeMovies, LetterFolders: true, Lettering: lettering}
+	dst := &content.Library{Name: "import-movies", Path: "/mnt/video/downloads/movies", Type: content.LibraryTypeMovies}
+
+	// the old 0 and @ folders are still checked, re-letter élite, ørnen and 1917 and move der untergang to import
+	vt.keys("rrrm")
+
+	sb := ktio.NewStatusBar()
+	defer sb.Close()
+
+	if err := FixLettering(src, dst, sb); err != nil {
+		t.Fatal(err)
+	}
+
+	assertGolden(t, "fix-lettering-reletter", vt.tree("/mnt/video"))
+}
+
+func TestFindAndCombineAnimeGolden(t *testing.T) {
+	vt := newVideoTree(t, `
+		/mnt/video/anime/movies/Akira (1988)/Akira (1988) - 480p.avi = h264-480p.avi
+		/mnt/video/anime/movies/Paprika (2006)/Paprika (2006).mkv = hevc-1080p.mkv
+		/mnt/video/anime/movies/Spirited Away (2001)/Spirited Away (2001).mkv = hevc-2160p.mkv
+		/mnt/video/movies/a/Akira (1988)/Akira (1988).mkv = hevc-1080p.mkv
+		/mnt/video/movies/a/Akira (1988)/movie.nfo
+		/mnt/video/movies/s/Spirited Away (2001)/Spirited Away (2001) - 1080p.mkv = h264-1080p.mkv
+		/mnt/video/movies/s/Solaris (1972)/Solaris (1972).mkv = h264-1080p.mkv
+	`)
+
+	anime := &content.Library{Name: "video-anime-movies", Path: "/mnt/video/anime/movies", Type: content.LibraryTypeMovies}
+	movies := &content.Library{Name: "video-movies", Path: "/mnt/video/movies", Type: content.LibraryTypeMovies, LetterFolders: true}
+
+	// compare then keep the standard akira, keep the anime spirited away
+	vt.keys("cab")
+
+	if err := FindAndCombineAnime(fixtureContext(), anime, movies, content.LibraryTypeMovies); err != nil {
+		t.Fatal(err)
+	}
+
+	assertGolden(t, "anime-dedup", vt.tree("/mnt/video"))
+}
+
+func TestProcessMoviesGolden(t *testing.T) {
+	vt := newVideoTree(t, `
+		/mnt/video/downloads/movies/Alien (1979)/Alien (1979).mkv = h264-1080p.mkv
+		/mnt/video/downloads/movies/Alien (1979)/movie.nfo
+		/mnt/video/downloads/movies/Brand New (2020)/Brand New (2020).mkv = hevc-1080p.mkv
+		/mnt/video/downloads/movies/Heat (1995)/Heat (1995).mkv = hevc-1080p.mkv
+		/mnt/video/downloads/movies/Heat (1995)/Heat (1995).en.srt
+		/mnt/video/downloads/movies/Jaws (1975)/Jaws (1975) - 480p.avi = h264-480p.avi
+		/mnt/video/downloads/movies/Ran (1985)/Ran (1985) - 2160p.mkv = hevc-2160p.mkv
+		/mnt/video/movies/a/Alien (1979)/Alien (1979).mkv = h264-1080p.mkv
+		/mnt/video/movies/b/
+		/mnt/video/movies/h/Heat (1995)/Heat (1995) - 2160p.mkv = hevc-2160p.mkv
+		/mnt/video/movies/j/Jaws (1975)/Jaws (1975).mkv = hevc-2160p.mkv
+		/mnt/video/movies/r/Ran (1985)/Ran (1985).mkv = h264-1080p.mkv
+	`)
+
+	rule, err := content.NewPolicyRule("upgrade resolution", map[string]string{"resolution": ">"}, "replace")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := &content.Library{Name: "import-movies", Path: "/mnt/video/downloads/movies", Type: content.LibraryTypeMovies}
+	dst := &content.Library{Name: "video-movies", Path: "/mnt/video/movies", Type: content.LibraryTypeMovies, LetterFolders: true, Policy: content.Policy{*rule}}
+
+	// alien is the same, brand new is new and ran is replaced by the policy leaving
+	// overwrite heat, delete the jaws source and confirm the deletes
+	vt.keys("ydy")
+
+	if err := ProcessMovies(fixtureContext(), "movies", content.LibraryMapping{Source: src, Dest: dst}); err != nil {
+		t.Fatal(err)
+	}
+
+	assertGolden(t, "import-movies", vt.tree("/mnt/video"))
+}
+
+func TestProcessMoviesDryRunGolden(t *testing.T) {
+	vt := newVideoTree(t, `
+		/mnt/video/downloads/movies/Alien (1979)/Alien (1979).mkv = h264-1080p.mkv
+		/mnt/video/downloads/movies/Alien (1979)/movie.nfo
+		/mnt/video/downloads/movies/Brand New (2020)/Brand New (2020).mkv = hevc-1080p.mkv
+		/mnt/video/downloads/movies/Heat (1995)/Heat (1995).mkv = hevc-1080p.mkv
+		/mnt/video/downloads/movies/Heat (1995)/Heat (1995).en.srt
+		/mnt/video/movies/a/Alien (1979)/Alien (1979).mkv = h264-1080p.mkv
+		/mnt/video/movies/h/Heat (1995)/Heat (1995) - 2160p.mkv = hevc-2160p.mkv
+	`)
+	before := vt.tree("/mnt/video")
+
+	ktio.EnableDryRun()
+	t.Cleanup(ktio.DisableDryRun)
+
+	src := &content.Library{Name: "import-movies", Path: "/mnt/video/downloads/movies", Type: content.LibraryTypeMovies}
+	dst := &content.Library{Name: "video-movies", Path: "/mnt/video/movies", Type: content.LibraryTypeMovies, LetterFolders: true}
+
+	// alien is the same, brand new is new and heat is overwritten once the delete is confirmed, nothing
+	// is touched only planned
+	vt.keys("yy")
+
+	if err := ProcessMovies(fixtureContext(), "movies", content.LibraryMapping{Source: src, Dest: dst}); err != nil {
+		t.Fatal(err)
+	}
+
+	if after := vt.tree("/mnt/video"); after != before {
+		t.Errorf("dry run changed the tree\n--- got ---\n%s--- want ---\n%s", after, before)
+	}
+
+	var plan strings.Builder
+	for _, a := range ktio.PlannedActions() {
+		plan.WriteString(a.String() + "\n")
+	}
+	assertGolden(t, "import-movies-dry-run", plan.String())
+}
+
+func TestProcessMoviesVersionsGolden(t *testing.T) {
+	vt := newVideoTree(t, `
+		/mnt/video/downloads/movies/Aliens (1986)/Aliens (1986).mkv = hevc-2160p.mkv
+		/mnt/video/downloads/movies/Aliens (1986)/Aliens (1986).en.srt
+		/mnt/video/downloads/movies/Brazil (1985)/Brazil (1985) - Final Cut.mkv = hevc-1080p.mkv
+		/mnt/video/movies/a/Aliens (1986)/Aliens.1986.1080p.mkv = h264-1080p.mkv
+		/mnt/video/movies/b/Brazil (1985)/Brazil.1985.1080p.mkv = hevc-1080p-truncated.mkv
+		/mnt/video/movies/b/Brazil (1985)/Brazil.1985.1080p.en.srt
+	`)
+	nfo := []byte("<movie><title>Aliens</title><edition>Special Edition</edition></movie>")
+	if err := afero.WriteFile(vt.fs, "/mnt/video/downloads/movies/Aliens (1986)/Aliens (1986).nfo", nfo, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rule, err := content.NewPolicyRule("upgrade resolution", map[string]string{"resolution": ">"}, "replace")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := &content.Library{Name: "import-movies", Path: "/mnt/video/downloads/movies", Type: content.LibraryTypeMovies}
+	dst := &content.Library{Name: "video-movies", Path: "/mnt/video/movies", Type: content.LibraryTypeMovies, LetterFolders: true, Policy: content.Policy{*rule}}
+
+	// the aliens nfo edition and the brazil final cut skip the policy, both are kept as versions
+	vt.keys("kk")
+
+	if err := ProcessMovies(fixtureContext(), "movies", content.LibraryMapping{Source: src, Dest: dst}); err != nil {
+		t.Fatal(err)
+	}
+
+	assertGolden(t, "import-movies-versions", vt.tree("/mnt/video"))
+}
+
+func TestRenameReleasesGolden(t *testing.T) {
+	vt := newVideoTree(t, `
+		/mnt/video/downloads/movies/Alien (1979)/Alien (1979).mkv = h264-1080p.mkv
+		/mnt/video/downloads/movies/Blade.Runner.1982.Final.Cut.1080p.BluRay.x264-GRP/blade.runner.fc.1080p-grp.mkv = hevc-1080p.mkv
+		/mnt/video/downloads/movies/Blade.Runner.1982.Final.Cut.1080p.BluRay.x264-GRP/blade.runner.fc.1080p-grp.en.srt
+		/mnt/video/downloads/movies/Blade.Runner.1982.Final.Cut.1080p.BluRay.x264-GRP/Sample/sample-grp.mkv = h264-480p.avi
+		/mnt/video/downloads/movies/Heat.1995.2160p.UHD.BluRay.x265-TERMiNAL.mkv = hevc-2160p.mkv
+		/mnt/video/downloads/tv/Show (2010)/Show - s01/Show - 01x01 - Pilot.mkv = hevc-1080p.mkv
+		/mnt/video/downloads/tv/Show.2010.S01E02.Second.1080p.WEB-DL.H.264-NTb.mkv = h264-1080p.mkv
+		/mnt/video/downloads/tv/The.Other.Show.S02.1080p.BluRay.x264-GRP/The.Other.Show.S02E01.1080p.BluRay.x264-GRP.mkv = hevc-2160p.mkv
+		/mnt/video/downloads/tv/The.Other.Show.S02.1080p.BluRay.x264-GRP/The.Other.Show.S02E02E03.Finale.1080p.BluRay.x264-GRP.mkv = hevc-1080p-truncated.mkv
+		/mnt/video/downloads/tv/The.Other.Show.S02.1080p.BluRay.x264-GRP/The.Other.Show.S02.1080p.BluRay.x264-GRP.nfo
+		/mnt/video/downloads/tv/Third.Show.2018.S01.1080p.WEB-DL-GRP/Third.Show.S01E01.Start.1080p.WEB-DL-GRP.mkv = hevc-1080p.mkv
+		/mnt/video/downloads/tv/Third.Show.2018.S01.1080p.WEB-DL-GRP/Third.Show.S01E01.Start.1080p.WEB-DL-GRP.sample.mkv = h264-480p.avi
+	`)
+
+	movies := &content.Library{Name: "import-movies", Path: "/mnt/video/downloads/movies", Type: content.LibraryTypeMovies}
+	series := &content.Library{Name: "import-tv", Path: "/mnt/video/downloads/tv", Type: content.LibraryTypeSeries}
+
+	// alien and show are already named for the library, the rest are renamed before importing except the other show
+	// which has no year for its series folder. The samples are removed rather than imported
+	vt.keys("yy")
+
+	for _, lib := range []*content.Library{movies, series} {
+		if err := RenameReleases(content.LibraryMapping{Source: lib}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	assertGolden(t, "rename-releases", vt.tree("/mnt/video"))
+}
+
+func TestRenameReleasesExistingGolden(t *testing.T) {
+	vt := newVideoTree(t, `
+		/mnt/video/downloads/movies/Alien.1979.1080p.BluRay.x264-GRP/alien.1080p-grp.mkv = h264-1080p.mkv
+		/mnt/video/downloads/movies/Alien.1979.1080p.BluRay.x264-GRP/alien.1080p-grp.en.srt
+		/mnt/video/downloads/movies/Alien.1979.1080p.BluRay.x264-GRP/Alien (1979).en.srt
+		/mnt/video/downloads/movies/Heat (1995)/Heat (1995).mkv = hevc-1080p.mkv
+		/mnt/video/downloads/movies/Heat.1995.2160p.UHD.BluRay.x265-TERMiNAL.mkv = hevc-2160p.mkv
+		/mnt/video/downloads/tv/Show (2010)/Show - s01/Show - 01x01 - Pilot.mkv = hevc-1080p.mkv
+		/mnt/video/downloads/tv/Show.2010.S01E01.Pilot.1080p.WEB-DL-GRP.mkv = h264-1080p.mkv
+		/mnt/video/downloads/tv/Show.2010.S01E02.Second.1080p.WEB-DL-GRP.mkv = h264-1080p.mkv
+	`)
+
+	movies := &content.Library{Name: "import-movies", Path: "/mnt/video/downloads/movies", Type: content.LibraryTypeMovies}
+	series := &content.Library{Name: "import-tv", Path: "/mnt/video/downloads/tv", Type: content.LibraryTypeSeries}
+
+	// nothing already there is replaced, the heat release, the alien subtitle and the pilot are left where they are
+	vt.keys("yy")
+
+	for _, lib := range []*content.Library{movies, series} {
+		if err := RenameReleases(content.LibraryMapping{Source: lib}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	assertGolden(t, "rename-releases-existing", vt.tree("/mnt/video"))
+}
+
+func TestProcessSeriesGolden(t *testing.T) {
+	vt := newVideoTree(t, `
+		/mnt/video/downloads/tv/Brand New Show (2020)/Brand New Show - s01/Brand New Show - 01x01 - Pilot.mkv = hevc-1080p.mkv
+		/mnt/video/downloads/tv/Show (2010)/Show - s01/Show - 01x01 - Pilot.mkv = hevc-1080p.mkv
+		/mnt/video/downloads/tv/Show (2010)/Show - s01/Show - 01x02 - Second.mkv = hevc-1080p.mkv
+		/mnt/video/downloads/tv/Show (2010)/Show - s01/Show - 01x03 - Third.mkv = h264-1080p.mkv
+		/mnt/video/downloads/tv/Show (2010)/Show - s02/Show - 02x01 - Return.mkv = hevc-2160p.mkv
+		/mnt/video/downloads/tv/Show (2010)/extras/Show - Behind the Scenes.mkv
+		/mnt/video/downloads/tv/Show (2010)/tvshow.nfo
+		/mnt/video/tv/b/
+		/mnt/video/tv/s/Show (2010)/Show - s01/Show - 01x01 - Pilot.avi = h264-480p.avi
+		/mnt/video/tv/s/Show (2010)/Show - s01/Show - 01x03 - Third.mkv = h264-1080p.mkv
+	`)
+
+	src := &content.Library{Name: "import-series", Path: "/mnt/video/downloads/tv", Type: content.LibraryTypeSeries}
+	dst := &content.Library{Name: "video-tv", Path: "/mnt/video/tv", Type: content.LibraryTypeSeries, LetterFolders: true}
+
+	// overwrite the pilot and move the extra, the second episode and season 2 are new and the third is the same
+	vt.keys("yy")
+
+	if err := ProcessSeries(fixtureContext(), "series", content.LibraryMapping{Source: src, Dest: dst}); err != nil {
+		t.Fatal(err)
+	}
+
+	assertGolden(t, "import-series", vt.tree("/mnt/video"))
+}
+
+// failingTails fails to decode the end of the named videos
+type failingTails map[string]bool
+
+func (f failingTails) DecodeTail(_ context.Context, path string, _ float64) error {
+	if f[filepath.Base(path)] {
+		return errors.New("Invalid NAL unit size")
+	}
+	return nil
+}
+
+func TestVerifyLibraryGolden(t *testing.T) {
+	vt := newVideoTree(t, `
+		/mnt/video/tv/Show (2010)/Show - s01/Show - 01x01 - Pilot.mkv = hevc-1080p.mkv
+		/mnt/video/tv/Show (2010)/Show - s01/Show - 01x02 - Second.mkv = hevc-1080p.mkv
+		/mnt/video/tv/Show (2010)/Show - s01/Show - 01x03 - Third.mkv = hevc-1080p-truncated.mkv
+		/mnt/video/tv/Show (2010)/Show - s01/Show - 01x03 - Third.en.srt
+		/mnt/video/tv/Show (2010)/Show - s01/Show - 01x03 - Third.nfo
+		/mnt/video/tv/Show (2010)/Show - s01/Show - 01x03 - Thirdly.en.srt
+		/mnt/video/tv/Show (2010)/Show - s01/Show - 01x04 - Fourth.mkv = hevc-1080p.mkv
+		/mnt/video/tv/Show (2010)/Show - s02/Show - 02x01 - Return.mkv = h264-1080p.mkv
+		/mnt/video/tv/Show (2010)/Show - s02/Show - 02x02 - Broken.mkv
+		/mnt/video/tv/Other Show (2015)/Other Show - s01/Other Show - 01x01 - Pilot.mkv = hevc-2160p.mkv
+		/mnt/video/tv/Other Show (2015)/Other Show - s01/Other Show - 01x02 - Damaged.mkv = hevc-2160p.mkv
+	`)
+
+	content.SetTailDecoder(failingTails{"Other Show - 01x02 - Damaged.mkv": true})
+	t.Cleanup(func() { content.SetTailDecoder(nil) })
+
+	content.Libraries = map[string]*content.Library{
+		"video-tv": {Name: "video-tv", Path: "/mnt/video/tv", Type: content.LibraryTypeSeries},
+	}
+	t.Cleanup(func() { content.Libraries = map[string]*content.Library{} })
+
+	// the truncated third episode with its subtitle and nfo, the unprobeable broken one and the damaged one are
+	// quarantined
+	if err := VerifyLibrary(fixtureContext(), "video-tv", "/mnt/video/quarantine", true); err != nil {
+		t.Fatal(err)
+	}
+
+	assertGolden(t, "verify", vt.tree("/mnt/video"))
+}
+
+func TestNormalizeGolden(t *testing.T) {
+	vt := newVideoTree(t, `
+		/mnt/video/tv/Show (2010)/Season 1/Show.S01E01.Pilot.1080p.WEB-DL-GRP.mkv = hevc-1080p.mkv
+		/mnt/video/tv/Show (2010)/Season 1/Show.S01E01.Pilot.1080p.WEB-DL-GRP.en.srt
+		/mnt/video/tv/Show (2010)/Season 1/Show.S01E02E03.1080p.WEB-DL-GRP.mkv = h264-1080p.mkv
+		/mnt/video/tv/Show (2010)/Season 1/Show.S01E04.720p.HDTV-GRP.mkv = hevc-2160p.mkv
+		/mnt/video/tv/Show (2010)/Season 1/Show.S01E05E07E09.720p.HDTV-GRP.mkv = hevc-2160p.mkv
+		/mnt/video/tv/Show (2010)/Show - s02 (2012)/Show - 02x01 - Return.mkv = hevc-2160p-hdr10.mkv
+		/mnt/video/tv/Show (2010)/Specials/Show.S00E01.Christmas.Special.mkv = h264-1080p-dts.mkv
+		/mnt/video/tv/Show (2010)/tvshow.nfo
+		/mnt/video/tv/Other Show (2015)/S01/other.show.1x01.first.mkv = hevc-2160p-dv.mkv
+	`)
+
+	nfo := `<episodedetails><title>Double Trouble</title></episodedetails>`
+	if err := afero.WriteFile(vt.fs, "/mnt/video/tv/Show (2010)/Season 1/Show.S01E02E03.1080p.WEB-DL-GRP.nfo", []byte(nfo), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	content.Libraries = map[string]*content.Library{
+		"video-tv": {Name: "video-tv", Path: "/mnt/video/tv", Type: content.LibraryTypeSeries},
+	}
+	t.Cleanup(func() { content.Libraries = map[string]*content.Library{} })
+
+	// the double episode takes its title from the nfo, the fourth has none, season 2 is already named and 5, 7 and 9
+	// can't be written as a library name so are left alone
+	vt.keys("y")
+
+	if err := Normalize(fixtureContext(), "video-tv"); err != nil {
+		t.Fatal(err)
+	}
+
+	assertGolden(t, "normalize", vt.tree("/mnt/video"))
+}
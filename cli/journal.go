@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"strings"
+	"sync"
+
+	c "github.com/gookit/color"
+	"github.com/katbyte/go-ingest-media/lib/content"
+	"github.com/katbyte/go-ingest-media/lib/journal"
+	"github.com/katbyte/go-ingest-media/lib/ktio"
+	"github.com/spf13/cobra"
+)
+
+// sessionID identifies all operations performed by this run in the journal
+var sessionID = journal.NewSessionID()
+
+// the journal is opened on the first operation so read only commands never create it
+var (
+	journalMu      sync.Mutex
+	journalDB      *journal.Journal
+	journalCommand string
+	journalFailed  bool
+)
+
+// journalPath returns the configured journal path or the default one
+func journalPath() (string, error) {
+	if p := GetFlags().Journal; p != "" {
+		return p, nil
+	}
+	return journal.DefaultPath()
+}
+
+// openJournal opens the journal database
+func openJournal() (*journal.Journal, error) {
+	path, err := journalPath()
+	if err != nil {
+		return nil, err
+	}
+
+	return journal.Open(path)
+}
+
+// startJournal records every completed file operation for this run in the journal
+func startJournal(cmd *cobra.Command, args []string) {
+	if ktio.DryRun() {
+		return
+	}
+
+	journalCommand = strings.TrimSpace(cmd.CommandPath() + " " + strings.Join(args, " "))
+	ktio.SetRecorder(recordAction)
+}
+
+// sessionJournal returns the journal with this session started, opening it on first use. Must be called with journalMu held
+// errors are reported once and then journaling is skipped
+func sessionJournal() *journal.Journal {
+	if journalFailed {
+		return nil
+	}
+
+	if journalDB == nil {
+		j, err := openJournal()
+		if err == nil {
+			err = j.StartSession(sessionID, journalCommand)
+		}
+		if err != nil {
+			journalFailed = true
+			c.Printf("  <red>ERROR:</> journal unavailable, operations will not be recorded: %s\n", err)
+			return nil
+		}
+		journalDB = j
+	}
+
+	return journalDB
+}
+
+// recordAction logs a single completed action
+func recordAction(a ktio.Action) {
+	journalMu.Lock()
+	defer journalMu.Unlock()
+
+	j := sessionJournal()
+	if j == nil {
+		return
+	}
+
+	if err := j.Record(sessionID, string(a.Op), a.Src, a.Dst, a.Trash); err != nil {
+		c.Printf("  <red>ERROR:</> %s\n", err)
+	}
+}
+
+// recordDecision logs an automatic policy decision with the quality scores of the incoming and best existing video
+func recordDecision(item string, decision content.PolicyDecision, rule *content.PolicyRule, srcScore, dstScore content.QualityScore) {
+	if ktio.DryRun() {
+		return
+	}
+
+	journalMu.Lock()
+	defer journalMu.Unlock()
+
+	j := sessionJournal()
+	if j == nil {
+		return
+	}
+
+	if err := j.RecordDecision(sessionID, item, decision.String(), rule.String(), srcScore.Total, dstScore.Total); err != nil {
+		c.Printf("  <red>ERROR:</> %s\n", err)
+	}
+}
+
+// closeJournal closes the journal if it was opened
+func closeJournal() {
+	journalMu.Lock()
+	defer journalMu.Unlock()
+
+	if journalDB != nil {
+		journalDB.Close()
+		journalDB = nil
+	}
+}
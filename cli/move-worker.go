@@ -1,8 +1,8 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
-	"os/exec"
 	"strings"
 
 	c "github.com/gookit/color"
@@ -25,7 +25,16 @@ type moveResult struct {
 
 // printMoveResult displays the output of a completed move
 func printMoveResult(result moveResult) {
-	if result.err != nil {
+	switch {
+	case errors.Is(result.err, ktio.ErrDestinationExists):
+		c.Printf("  <yellow>SKIPPED:</> %s already exists at the destination\n", result.folder)
+	case errors.Is(result.err, ktio.ErrInsufficientSpace):
+		c.Printf("  <yellow>SKIPPED:</> %s does not fit: %s\n", result.folder, result.err)
+	case errors.Is(result.err, ktio.ErrChecksumMismatch):
+		c.Printf("  <red>ERROR:</> moving %s: copy did not match the source, the source has been kept: %s\n", result.folder, result.err)
+	case errors.Is(result.err, ktio.ErrPermissionDenied):
+		c.Printf("  <red>ERROR:</> moving %s: permission denied, check the owner of the source and destination folders\n", result.folder)
+	case result.err != nil:
 		c.Printf("  <red>ERROR:</> moving %s: %s\n", result.folder, result.err)
 	}
 	if result.output != "" {
@@ -70,16 +79,30 @@ func startMoveWorker(queue <-chan moveAction, results chan<- moveResult, sb *kti
 			queued := len(queue) + 1 // +1 for current
 			sb.UpdateMove(c.Sprintf("<yellow>moving (%d) %s...</>", queued, action.folder))
 
-			cmd := exec.Command("mv", "-v", action.srcPath, action.destPath) //nolint:gosec
-			output, cmdErr := cmd.CombinedOutput()
+			// only shown for cross device moves where the data has to be copied
+			lastPct := -1
+			progress := func(_ string, done, total int64) {
+				if total == 0 {
+					return
+				}
+				if pct := int(done * 100 / total); pct != lastPct {
+					lastPct = pct
+					sb.UpdateMove(c.Sprintf("<yellow>copying (%d) %s %d%%</>", queued, action.folder, pct))
+				}
+			}
+
+			output, cmdErr := ktio.MoveOutput(action.srcPath, action.destPath, progress)
 
-			if cmdErr != nil {
+			switch {
+			case errors.Is(cmdErr, ktio.ErrInsufficientSpace):
+				sb.UpdateMove(c.Sprintf("<yellow>skipped %s, not enough space</>", action.folder))
+			case cmdErr != nil:
 				sb.UpdateMove(c.Sprintf("<red>ERROR moving %s</>", action.folder))
-			} else {
+			default:
 				sb.UpdateMove(c.Sprintf("<green>moved %s ✓</>", action.folder))
 			}
 
-			results <- moveResult{folder: action.folder, output: string(output), err: cmdErr}
+			results <- moveResult{folder: action.folder, output: output, err: cmdErr}
 		}
 		close(results)
 	}()
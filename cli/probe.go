@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/katbyte/go-ingest-media/lib/content"
+	"github.com/katbyte/go-ingest-media/lib/ktio"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// ProbeLimitConfig limits how many videos are probed at once under a path in the config file
+type ProbeLimitConfig struct {
+	Path    string `mapstructure:"path"`
+	Workers int    `mapstructure:"workers"`
+}
+
+// startProbing sets the prober on the command's context and configures how many videos are probed at once per filesystem
+func startProbing(cmd *cobra.Command) error {
+	f := GetFlags()
+	var prober content.SystemProber
+	switch f.Prober {
+	case "ffprobe":
+	case "native":
+		prober.PreferNative = true
+	default:
+		return fmt.Errorf("unknown prober %q (use ffprobe or native)", f.Prober)
+	}
+	cmd.SetContext(content.WithProber(cmd.Context(), prober))
+
+	var lc []ProbeLimitConfig
+	if err := viper.UnmarshalKey("probe-limits", &lc); err != nil {
+		return fmt.Errorf("error parsing probe-limits: %w", err)
+	}
+
+	limits := make([]content.MountLimit, 0, len(lc))
+	for i, l := range lc {
+		if l.Path == "" {
+			return fmt.Errorf("probe-limits %d: path is required", i+1)
+		}
+		if l.Workers < 1 {
+			return fmt.Errorf("probe-limits %d (%s): workers must be at least 1", i+1, l.Path)
+		}
+		limits = append(limits, content.MountLimit{Path: l.Path, Workers: l.Workers})
+	}
+
+	content.SetProbeScheduler(content.NewProbeScheduler(f.ProbeWorkers, limits))
+	return nil
+}
+
+// showProbeProgress reports probes on the scan line of the status bar until the returned func is called
+func showProbeProgress(sb *ktio.StatusBar) func() {
+	s := content.Scheduler()
+	s.SetProgress(func(done, total int64, path string) {
+		sb.UpdateScan(fmt.Sprintf("probing (%d/%d) %s", done, total, filepath.Base(path)))
+	})
+
+	return func() {
+		s.SetProgress(nil)
+	}
+}
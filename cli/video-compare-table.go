@@ -2,6 +2,7 @@ package cli
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"math"
 	"os"
@@ -31,11 +32,13 @@ var tablestyle = table.Style{
 	},
 }
 
+// TableRow is a row of the comparison table, rows that are part of the quality score highlight the best video by that
+// part so the highlighting agrees with the recommended video
 type TableRow struct {
-	Name       string
-	Value      func(v content.VideoFile) string
-	Equal      func(v1, v2 content.VideoFile) bool
-	BetterThan func(v1, v2 content.VideoFile) bool
+	Name  string
+	Value func(v content.VideoFile) string
+	Equal func(v1, v2 content.VideoFile) bool
+	Score func(s content.QualityScore) float64 // the part of the quality score the row shows, nil if it isn't scored
 }
 
 var rows = []TableRow{
@@ -43,186 +46,312 @@ var rows = []TableRow{
 		"Ext",
 		func(file content.VideoFile) string { return file.Ext },
 		func(v1, v2 content.VideoFile) bool { return v1.Ext == v2.Ext },
-		func(v1, v2 content.VideoFile) bool {
-			return content.VideoExtensionIndex(v1.Ext) > content.VideoExtensionIndex(v2.Ext)
-		},
+		func(s content.QualityScore) float64 { return s.Extension },
 	},
 	{
 		"Size",
 		func(file content.VideoFile) string { return fmt.Sprintf("%0.2f", file.SizeGb) },
 		func(v1, v2 content.VideoFile) bool { return v1.SizeGb == v2.SizeGb },
-		func(v1, v2 content.VideoFile) bool { return v1.SizeGb < v2.SizeGb },
+		nil,
 	},
 	{
 		"Resolution",
 		func(file content.VideoFile) string { return file.Resolution },
 		func(v1, v2 content.VideoFile) bool { return v1.Resolution == v2.Resolution },
-		func(v1, v2 content.VideoFile) bool {
-			return v1.ResolutionW*v1.ResolutionH > v2.ResolutionW*v2.ResolutionH
-		},
+		func(s content.QualityScore) float64 { return s.Resolution },
 	},
 	{
 		"Aspect",
 		func(file content.VideoFile) string { return file.AspectRatio() },
 		func(v1, v2 content.VideoFile) bool { return v1.AspectRatio() == v2.AspectRatio() },
-		func(v1, v2 content.VideoFile) bool { return v1.IsWidescreen() && !v2.IsWidescreen() },
+		nil,
 	},
 	{
 		"Codec",
 		func(file content.VideoFile) string { return file.VideoStream.CodecName },
 		func(v1, v2 content.VideoFile) bool { return v1.VideoStream.CodecName == v2.VideoStream.CodecName },
-		func(v1, v2 content.VideoFile) bool { return v1.VideoStream.CodecName > v2.VideoStream.CodecName },
+		func(s content.QualityScore) float64 { return s.Codec },
 	},
 	{
 		"Profile",
 		func(file content.VideoFile) string { return file.VideoStream.Profile },
 		func(v1, v2 content.VideoFile) bool { return v1.VideoStream.Profile == v2.VideoStream.Profile },
-		func(v1, v2 content.VideoFile) bool { return v1.VideoStream.Profile > v2.VideoStream.Profile },
+		nil,
+	},
+	{
+		"HDR",
+		func(file content.VideoFile) string { return file.HDRFormat() },
+		func(v1, v2 content.VideoFile) bool { return v1.HDRFormat() == v2.HDRFormat() },
+		func(s content.QualityScore) float64 { return s.HDR },
+	},
+	{
+		"Bit Depth",
+		func(file content.VideoFile) string { return file.BitDepthString() },
+		func(v1, v2 content.VideoFile) bool { return v1.BitDepth == v2.BitDepth },
+		func(s content.QualityScore) float64 { return s.HDR },
+	},
+	{
+		"Scan",
+		func(file content.VideoFile) string { return file.ScanType() },
+		func(v1, v2 content.VideoFile) bool { return v1.Interlaced == v2.Interlaced },
+		func(s content.QualityScore) float64 { return s.Resolution },
 	},
 	{
 		"Duration",
 		func(file content.VideoFile) string { return fmt.Sprintf("%0.2f", file.Duration) },
 		func(v1, v2 content.VideoFile) bool { return v1.Duration == v2.Duration },
-		func(v1, v2 content.VideoFile) bool { return v1.Duration > v2.Duration },
+		nil,
 	},
 	{
 		"Bitrate",
 		func(file content.VideoFile) string { return strconv.Itoa(file.BitRate) },
 		func(v1, v2 content.VideoFile) bool { return v1.BitRate == v2.BitRate },
-		func(v1, v2 content.VideoFile) bool { return v1.BitRate > v2.BitRate },
+		func(s content.QualityScore) float64 { return s.Efficiency },
+	},
+	{
+		"Best Audio",
+		func(file content.VideoFile) string {
+			if d := bestAudioDescription(file); d != "" {
+				return d
+			}
+			return "NONE"
+		},
+		func(v1, v2 content.VideoFile) bool { return bestAudioDescription(v1) == bestAudioDescription(v2) },
+		func(s content.QualityScore) float64 { return s.Audio },
+	},
+	{
+		"Languages",
+		func(file content.VideoFile) string { return strings.Join(file.AudioLanguages(), ", ") },
+		func(v1, v2 content.VideoFile) bool {
+			return strings.Join(v1.AudioLanguages(), ",") == strings.Join(v2.AudioLanguages(), ",")
+		},
+		nil,
+	},
+	{
+		"Tracks",
+		func(file content.VideoFile) string {
+			if n := file.CommentaryTracks(); n > 0 {
+				return fmt.Sprintf("%d (%d commentary)", len(file.AudioStreams), n)
+			}
+			return strconv.Itoa(len(file.AudioStreams))
+		},
+		func(v1, v2 content.VideoFile) bool {
+			return len(v1.AudioStreams) == len(v2.AudioStreams) && v1.CommentaryTracks() == v2.CommentaryTracks()
+		},
+		nil,
+	},
+	{
+		"Score",
+		func(file content.VideoFile) string { return file.Score().String() },
+		func(v1, v2 content.VideoFile) bool { return v1.Score().String() == v2.Score().String() },
+		func(s content.QualityScore) float64 { return s.Total },
 	},
 }
 
-func RenderVideoComparisonTable(indent int, headers []string, videos []content.VideoFile) {
-	var buf bytes.Buffer
-	t := table.NewWriter()
-	t.SetOutputMirror(&buf)
-	t.SetStyle(tablestyle)
+// bestAudioDescription returns the description of the best audio track or "" if there is no audio
+func bestAudioDescription(v content.VideoFile) string {
+	if best := v.BestAudioStream(); best != nil {
+		return best.Description()
+	}
+	return ""
+}
+
+// cellRating is how a value in the comparison table compares to the rest of its row
+type cellRating int
+
+const (
+	ratingWorse    cellRating = iota // worse than the best value
+	ratingBest                       // the best value (or equal to it)
+	ratingSame                       // identical to the source or every video is the same
+	ratingClose                      // within 5% (or a few pixels/seconds) of the source
+	ratingNear                       // within 10% of the source
+	ratingMismatch                   // a difference that needs attention ie 4:3 vs widescreen
+	ratingFailed                     // no value as ffprobe failed
+	ratingUnscored                   // differs but isn't part of the quality score so is neither better nor worse
+)
+
+var ratingStyles = map[cellRating]string{
+	ratingWorse:    "lightRed",
+	ratingBest:     "lightGreen",
+	ratingSame:     "green",
+	ratingClose:    "lightBlue",
+	ratingNear:     "blue",
+	ratingMismatch: "bgRed;white;op=bold",
+	ratingFailed:   "bgRed;white;op=bold",
+	ratingUnscored: "white",
+}
+
+// videosSame returns true if every video is basically the same as the first
+func videosSame(videos []content.VideoFile) bool {
+	if len(videos) < 2 {
+		return false
+	}
 
 	same := true
-	if len(videos) > 1 {
-		for _, v := range videos[1:] {
-			same = same && videos[0].IsBasicallyTheSameTo(v)
+	for _, v := range videos[1:] {
+		same = same && videos[0].IsBasicallyTheSameTo(v)
+	}
+	return same
+}
+
+// rateRow rates each video's value for a row, the first video is the source the others are compared to
+func rateRow(row TableRow, videos []content.VideoFile, same bool) []cellRating {
+	// the row's part of each video's score, the highest is the best
+	var scores []float64
+	if row.Score != nil {
+		scores = make([]float64, len(videos))
+		for i, v := range videos {
+			scores[i] = row.Score(v.Score())
 		}
-	} else {
-		same = false
 	}
 
-	headerRow := table.Row{"", headers[0]}
-	for _, h := range headers[1:] {
-		headerRow = append(headerRow, h)
+	// Check if all values in this row are equal
+	rowAllSame := true
+	for _, v := range videos[1:] {
+		if !row.Equal(v, videos[0]) {
+			rowAllSame = false
+			break
+		}
 	}
 
-	t.AppendHeader(headerRow, table.RowConfig{AutoMerge: true})
-	t.AppendSeparator()
+	ratings := make([]cellRating, len(videos))
+	for i := range videos {
+		ratings[i] = rateCell(row, videos, i, scores, same, rowAllSame)
+	}
+	return ratings
+}
+
+func rateCell(row TableRow, videos []content.VideoFile, vIndex int, scores []float64, same, rowAllSame bool) cellRating {
+	v := videos[vIndex]
 
-	type BestCheck struct {
-		File  content.VideoFile
-		Index int
+	// Show red background for videos where ffprobe failed
+	if v.FFProbeFailed && (row.Name == "Resolution" || row.Name == "Duration" || row.Name == "Bitrate") {
+		return ratingFailed
 	}
 
-	for _, row := range rows {
-		best := BestCheck{File: videos[0], Index: 0}
-		for i, v := range videos {
-			if row.BetterThan(v, best.File) {
-				best = BestCheck{File: v, Index: i}
+	// If all videos are basically the same (global check) or all values for THIS ROW are equal
+	if same || rowAllSame {
+		return ratingSame
+	}
+
+	// Check if destination value equals the source value (identical)
+	// Only check for destinations (vIndex > 0), not the source itself
+	if vIndex > 0 && row.Equal(v, videos[0]) {
+		return ratingSame
+	}
+
+	// Close match colouring based on row type
+	switch row.Name {
+	case "Duration":
+		// far enough apart to be a different cut
+		if content.DurationsDiffer(v, videos[0]) {
+			return ratingMismatch
+		}
+		diff := math.Abs(v.Duration - videos[0].Duration)
+		if diff > 0 && diff < 5 {
+			return ratingClose
+		}
+		if diff >= 5 && diff < 10 {
+			return ratingNear
+		}
+	case "Resolution":
+		diffW := math.Abs(float64(v.ResolutionW - videos[0].ResolutionW))
+		diffH := math.Abs(float64(v.ResolutionH - videos[0].ResolutionH))
+		diff := diffW + diffH
+		if diff > 0 && diff < 5 {
+			return ratingClose
+		}
+		if diff >= 5 && diff < 10 {
+			return ratingNear
+		}
+	case "Bitrate":
+		srcBitrate := float64(videos[0].BitRate)
+		if srcBitrate > 0 {
+			diffPct := math.Abs(float64(v.BitRate)-srcBitrate) / srcBitrate * 100
+			if diffPct > 0 && diffPct < 5 {
+				return ratingClose
+			}
+			if diffPct >= 5 && diffPct < 10 {
+				return ratingNear
 			}
 		}
-
-		// Check if all values in this row are equal
-		rowAllSame := true
-		for _, v := range videos[1:] {
-			if !row.Equal(v, videos[0]) {
-				rowAllSame = false
-				break
+	case "Size":
+		srcSize := videos[0].SizeGb
+		if srcSize > 0 {
+			diffPct := math.Abs(v.SizeGb-srcSize) / srcSize * 100
+			if diffPct > 0 && diffPct < 5 {
+				return ratingClose
+			}
+			if diffPct >= 5 && diffPct < 10 {
+				return ratingNear
 			}
 		}
+	case "Aspect":
+		// a 4:3 vs widescreen mismatch
+		if videos[0].IsWidescreen() != v.IsWidescreen() {
+			return ratingMismatch
+		}
+	}
 
-		colourize := func(v content.VideoFile, vIndex int) string {
-			s := row.Value(v)
-
-			// Show red background for videos where ffprobe failed
-			if v.FFProbeFailed && (row.Name == "Resolution" || row.Name == "Duration" || row.Name == "Bitrate") {
-				return c.Sprintf("<bgRed;white;op=bold>%s</>", s)
-			}
+	if scores == nil {
+		return ratingUnscored
+	}
+	for _, score := range scores {
+		if score > scores[vIndex] {
+			return ratingWorse
+		}
+	}
+	return ratingBest
+}
 
-			// If all videos are basically the same (global check)
-			if same {
-				return c.Sprintf("<green>%s</>", s)
-			}
+// RenderVideoComparisonTable prints the videos side by side, the first is the source the others are compared to. If
+// the library requires languages a pass/fail row is added for them
+func RenderVideoComparisonTable(ctx context.Context, indent int, headers []string, videos []content.VideoFile, langs content.LanguageRequirements) {
+	var buf bytes.Buffer
+	t := table.NewWriter()
+	t.SetOutputMirror(&buf)
+	t.SetStyle(tablestyle)
 
-			// If all values for THIS ROW are equal, show green
-			if rowAllSame {
-				return c.Sprintf("<green>%s</>", s)
-			}
+	same := videosSame(videos)
 
-			// Check if destination value equals the source value (identical) -> green
-			// Only check for destinations (vIndex > 0), not the source itself
-			if vIndex > 0 && row.Equal(v, videos[0]) {
-				return c.Sprintf("<green>%s</>", s)
-			}
+	headerRow := table.Row{"", headers[0]}
+	for _, h := range headers[1:] {
+		headerRow = append(headerRow, h)
+	}
 
-			// Close match colouring based on row type
-			switch row.Name {
-			case "Duration":
-				diff := math.Abs(v.Duration - videos[0].Duration)
-				if diff > 0 && diff < 5 {
-					return c.Sprintf("<lightBlue>%s</>", s)
-				}
-				if diff >= 5 && diff < 10 {
-					return c.Sprintf("<blue>%s</>", s)
-				}
-			case "Resolution":
-				diffW := math.Abs(float64(v.ResolutionW - videos[0].ResolutionW))
-				diffH := math.Abs(float64(v.ResolutionH - videos[0].ResolutionH))
-				diff := diffW + diffH
-				if diff > 0 && diff < 5 {
-					return c.Sprintf("<lightBlue>%s</>", s)
-				}
-				if diff >= 5 && diff < 10 {
-					return c.Sprintf("<blue>%s</>", s)
-				}
-			case "Bitrate":
-				srcBitrate := float64(videos[0].BitRate)
-				if srcBitrate > 0 {
-					diffPct := math.Abs(float64(v.BitRate)-srcBitrate) / srcBitrate * 100
-					if diffPct > 0 && diffPct < 5 {
-						return c.Sprintf("<lightBlue>%s</>", s)
-					}
-					if diffPct >= 5 && diffPct < 10 {
-						return c.Sprintf("<blue>%s</>", s)
-					}
-				}
-			case "Size":
-				srcSize := videos[0].SizeGb
-				if srcSize > 0 {
-					diffPct := math.Abs(v.SizeGb-srcSize) / srcSize * 100
-					if diffPct > 0 && diffPct < 5 {
-						return c.Sprintf("<lightBlue>%s</>", s)
-					}
-					if diffPct >= 5 && diffPct < 10 {
-						return c.Sprintf("<blue>%s</>", s)
-					}
-				}
-			case "Aspect":
-				// Check if there's a 4:3 vs widescreen mismatch
-				srcWidescreen := videos[0].IsWidescreen()
-				vWidescreen := v.IsWidescreen()
-				if srcWidescreen != vWidescreen {
-					// Red background for aspect ratio mismatch
-					return c.Sprintf("<bgRed;white;op=bold>%s</>", s)
-				}
-			}
+	t.AppendHeader(headerRow, table.RowConfig{AutoMerge: true})
+	t.AppendSeparator()
 
-			if best.Index == vIndex || row.Equal(v, best.File) {
-				return c.Sprintf("<lightGreen>%s</>", s)
-			}
-			return c.Sprintf("<lightRed>%s</>", s)
-		}
+	for _, row := range rows {
+		ratings := rateRow(row, videos, same)
 
 		r := table.Row{c.Sprintf("<darkGray>%s</>", row.Name)}
 		for i, v := range videos {
-			r = append(r, colourize(v, i))
+			r = append(r, c.Sprintf("<%s>%s</>", ratingStyles[ratings[i]], row.Value(v)))
+		}
+		t.AppendRow(r)
+	}
+
+	if r, ok := editionRow(videos); ok {
+		t.AppendRow(r)
+	}
+
+	var frameErrs []string
+	if GetFlags().CompareFrames && !same && len(videos) > 1 {
+		var r table.Row
+		r, frameErrs = contentMatchRow(ctx, videos)
+		t.AppendRow(r)
+	}
+
+	if !langs.IsEmpty() {
+		r := table.Row{c.Sprintf("<darkGray>Lang Policy</>")}
+		for _, v := range videos {
+			check := langs.Check(v)
+			style := ratingStyles[ratingBest]
+			if !check.Passed() {
+				style = ratingStyles[ratingMismatch]
+			}
+			r = append(r, c.Sprintf("<%s>%s</>", style, check))
 		}
 		t.AppendRow(r)
 	}
@@ -262,7 +391,7 @@ func RenderVideoComparisonTable(indent int, headers []string, videos []content.V
 		if firstStream != nil {
 			for j, stream := range streams {
 				if stream != nil {
-					if stream.Channels > firstStream.Channels {
+					if stream.BetterThan(*streams[bestStreamIndex]) {
 						bestStreamIndex = j
 					}
 				}
@@ -364,6 +493,17 @@ func RenderVideoComparisonTable(indent int, headers []string, videos []content.V
 
 	t.Render()
 
+	// recommend the highest scoring video unless there is nothing to choose between them
+	if !same && len(videos) > 1 {
+		if best := content.BestScore(videos); best >= 0 && best < len(headers) {
+			buf.WriteString(c.Sprintf(" <darkGray>recommended:</> <lightGreen>%s</> <darkGray>(score %s)</>\n", headers[best], videos[best].Score()))
+		}
+	}
+
+	for _, e := range frameErrs {
+		buf.WriteString(c.Sprintf(" <yellow>WARNING:</> unable to compare frames: %s\n", e))
+	}
+
 	// trim trailing newline and indent
 	output := buf.String()
 	if len(output) > 0 {
@@ -371,3 +511,53 @@ func RenderVideoComparisonTable(indent int, headers []string, videos []content.V
 	}
 	_, _ = ktio.IndentWriter{W: os.Stdout, Indent: strings.Repeat(" ", indent)}.Write([]byte(output))
 }
+
+// editionRow returns the edition of every video, highlighting any that differ from the first, and false if none of the
+// videos has an edition
+func editionRow(videos []content.VideoFile) (table.Row, bool) {
+	found := false
+	editions := make([]string, len(videos))
+	for i, v := range videos {
+		editions[i] = content.EditionOf(v)
+		found = found || editions[i] != ""
+	}
+	if !found {
+		return nil, false
+	}
+
+	r := table.Row{c.Sprintf("<darkGray>Edition</>")}
+	for _, e := range editions {
+		style := ratingStyles[ratingSame]
+		if !strings.EqualFold(e, editions[0]) {
+			style = ratingStyles[ratingMismatch]
+		}
+		if e == "" {
+			e = "-"
+		}
+		r = append(r, c.Sprintf("<%s>%s</>", style, e))
+	}
+	return r, true
+}
+
+var contentMatchStyles = map[content.ContentMatch]string{
+	content.ContentUnknown:      "darkGray",
+	content.ContentSame:         ratingStyles[ratingSame],
+	content.ContentDifferentCut: ratingStyles[ratingNear],
+	content.ContentDifferent:    ratingStyles[ratingMismatch],
+}
+
+// contentMatchRow compares sampled frames of every video to the first and returns the row and any errors
+func contentMatchRow(ctx context.Context, videos []content.VideoFile) (table.Row, []string) {
+	var errs []string
+
+	r := table.Row{c.Sprintf("<darkGray>Content</>"), c.Sprintf("<darkGray>reference</>")}
+	for _, v := range videos[1:] {
+		m, err := content.CompareContent(ctx, videos[0], v)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+		r = append(r, c.Sprintf("<%s>%s</>", contentMatchStyles[m], m))
+	}
+
+	return r, errs
+}
@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/katbyte/go-ingest-media/lib/content"
+	"github.com/katbyte/go-ingest-media/lib/content/contenttest"
+)
+
+// fixtureVideo creates a file of size bytes named after and probed as a fixture (or anything else to fail probing) and
+// loads it
+func fixtureVideo(t *testing.T, name string, size int64) content.VideoFile {
+	t.Helper()
+
+	path := contenttest.New(t, t.TempDir()).Add(name, name, size)
+	v, err := content.VideoFor(fixtureContext(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *v
+}
+
+func rowNamed(t *testing.T, name string) TableRow {
+	t.Helper()
+
+	for _, r := range rows {
+		if r.Name == name {
+			return r
+		}
+	}
+	t.Fatalf("no table row %q", name)
+	return TableRow{}
+}
+
+func TestRateRow(t *testing.T) {
+	type file struct {
+		name string
+		size int64
+	}
+
+	cases := []struct {
+		name   string
+		row    string
+		videos []file
+		want   []cellRating
+	}{
+		{
+			name:   "identical videos",
+			row:    "Codec",
+			videos: []file{{"hevc-1080p.mkv", 1000}, {"hevc-1080p.mkv", 1000}},
+			want:   []cellRating{ratingSame, ratingSame},
+		},
+		{
+			name:   "row equal but videos differ",
+			row:    "Resolution",
+			videos: []file{{"hevc-1080p.mkv", 1000}, {"h264-1080p.mkv", 2000}},
+			want:   []cellRating{ratingSame, ratingSame},
+		},
+		{
+			name:   "higher resolution destination",
+			row:    "Resolution",
+			videos: []file{{"hevc-1080p.mkv", 1000}, {"hevc-2160p.mkv", 1000}},
+			want:   []cellRating{ratingWorse, ratingBest},
+		},
+		{
+			name:   "best of several destinations",
+			row:    "Resolution",
+			videos: []file{{"h264-480p.avi", 1000}, {"hevc-2160p.mkv", 1000}, {"hevc-1080p.mkv", 1000}},
+			want:   []cellRating{ratingWorse, ratingBest, ratingWorse},
+		},
+		{
+			name:   "codec",
+			row:    "Codec",
+			videos: []file{{"hevc-1080p.mkv", 1000}, {"h264-1080p.mkv", 1000}},
+			want:   []cellRating{ratingBest, ratingWorse},
+		},
+		{
+			name:   "codec by rank not name",
+			row:    "Codec",
+			videos: []file{{"mpeg2-480i.mkv", 1000}, {"h264-480p.avi", 1000}},
+			want:   []cellRating{ratingWorse, ratingBest},
+		},
+		{
+			name:   "extension by rank",
+			row:    "Ext",
+			videos: []file{{"h264-480p.avi", 1000}, {"hevc-1080p.mkv", 1000}},
+			want:   []cellRating{ratingWorse, ratingBest},
+		},
+		{
+			name:   "size is not scored",
+			row:    "Size",
+			videos: []file{{"hevc-1080p.mkv", 1000}, {"h264-1080p.mkv", 2000}},
+			want:   []cellRating{ratingUnscored, ratingUnscored},
+		},
+		{
+			name:   "size within 5%",
+			row:    "Size",
+			videos: []file{{"hevc-1080p.mkv", 100000}, {"h264-1080p.mkv", 103000}},
+			want:   []cellRating{ratingUnscored, ratingClose},
+		},
+		{
+			name:   "size within 10%",
+			row:    "Size",
+			videos: []file{{"hevc-1080p.mkv", 100000}, {"h264-1080p.mkv", 107000}},
+			want:   []cellRating{ratingUnscored, ratingNear},
+		},
+		{
+			name:   "highest score",
+			row:    "Score",
+			videos: []file{{"hevc-1080p.mkv", 1000}, {"hevc-2160p-dv.mkv", 1000}, {"h264-480p.avi", 1000}},
+			want:   []cellRating{ratingWorse, ratingBest, ratingWorse},
+		},
+		{
+			name:   "duration within a few seconds",
+			row:    "Duration",
+			videos: []file{{"hevc-1080p.mkv", 1000}, {"h264-480p.avi", 1000}},
+			want:   []cellRating{ratingUnscored, ratingClose},
+		},
+		{
+			name:   "duration of a different cut",
+			row:    "Duration",
+			videos: []file{{"hevc-1080p.mkv", 1000}, {"hevc-1080p-truncated.mkv", 1000}},
+			want:   []cellRating{ratingUnscored, ratingMismatch},
+		},
+		{
+			name:   "4:3 vs widescreen",
+			row:    "Aspect",
+			videos: []file{{"hevc-1080p.mkv", 1000}, {"h264-480p.avi", 1000}},
+			want:   []cellRating{ratingUnscored, ratingMismatch},
+		},
+		{
+			name:   "dolby vision over hdr10 over sdr",
+			row:    "HDR",
+			videos: []file{{"hevc-2160p.mkv", 1000}, {"hevc-2160p-dv.mkv", 1000}, {"hevc-2160p-hdr10.mkv", 1000}},
+			want:   []cellRating{ratingWorse, ratingBest, ratingWorse},
+		},
+		{
+			name:   "10-bit over 8-bit",
+			row:    "Bit Depth",
+			videos: []file{{"h264-1080p.mkv", 1000}, {"hevc-1080p.mkv", 1000}},
+			want:   []cellRating{ratingWorse, ratingBest},
+		},
+		{
+			name:   "progressive over interlaced",
+			row:    "Scan",
+			videos: []file{{"mpeg2-480i.mkv", 1000}, {"h264-480p.avi", 1000}},
+			want:   []cellRating{ratingWorse, ratingBest},
+		},
+		{
+			name:   "lossless audio over lossy",
+			row:    "Best Audio",
+			videos: []file{{"h264-1080p.mkv", 1000}, {"hevc-2160p-dv.mkv", 1000}, {"h264-1080p-dts.mkv", 1000}},
+			want:   []cellRating{ratingWorse, ratingBest, ratingWorse},
+		},
+		{
+			name:   "audio languages are not scored",
+			row:    "Languages",
+			videos: []file{{"h264-1080p.mkv", 1000}, {"h264-1080p-dts.mkv", 1000}},
+			want:   []cellRating{ratingUnscored, ratingUnscored},
+		},
+		{
+			name:   "audio tracks are not scored",
+			row:    "Tracks",
+			videos: []file{{"hevc-1080p.mkv", 1000}, {"h264-1080p-dts.mkv", 1000}, {"h264-1080p.mkv", 1000}},
+			want:   []cellRating{ratingUnscored, ratingUnscored, ratingUnscored},
+		},
+		{
+			name:   "probe failed",
+			row:    "Bitrate",
+			videos: []file{{"unprobed.mkv", 1000}, {"hevc-1080p.mkv", 1000}},
+			want:   []cellRating{ratingFailed, ratingBest},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			videos := make([]content.VideoFile, 0, len(tc.videos))
+			for _, f := range tc.videos {
+				videos = append(videos, fixtureVideo(t, f.name, f.size))
+			}
+
+			got := rateRow(rowNamed(t, tc.row), videos, videosSame(videos))
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
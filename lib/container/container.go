@@ -0,0 +1,101 @@
+// Package container reads stream details straight from Matroska/WebM and MP4/MOV headers so basic
+// video comparisons work without ffprobe
+package container
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ErrUnsupported is returned for files that are not a container this package can read
+var ErrUnsupported = errors.New("unsupported container")
+
+// TrackType is the kind of a track, using the same names as ffprobe's codec_type
+type TrackType string
+
+const (
+	TrackVideo    TrackType = "video"
+	TrackAudio    TrackType = "audio"
+	TrackSubtitle TrackType = "subtitle"
+)
+
+// Info is the container level details of a file
+type Info struct {
+	Format   string  // matroska, webm, mp4 or mov
+	Duration float64 // seconds
+	Size     int64
+	Tracks   []Track
+}
+
+// Track is a single video, audio or subtitle track, codecs use ffprobe's names (hevc, h264, aac, subrip, ...)
+type Track struct {
+	Index      int
+	Type       TrackType
+	Codec      string
+	Name       string
+	Language   string // ISO 639-2 ie eng
+	Default    bool
+	Width      int
+	Height     int
+	Channels   int // 0 when the container doesn't say
+	SampleRate int
+}
+
+// BitRate returns the overall bitrate in bits per second calculated from the file size and duration
+func (i Info) BitRate() int64 {
+	if i.Duration <= 0 {
+		return 0
+	}
+	return int64(float64(i.Size) * 8 / i.Duration)
+}
+
+// Read returns the details of the Matroska or MP4 file at path
+func Read(path string) (*Info, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	st, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	var magic [12]byte
+	if _, err := io.ReadFull(f, magic[:]); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrUnsupported, path, err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	var info *Info
+	switch {
+	case bytes.Equal(magic[:4], []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		info, err = readMatroska(f)
+	case isMP4(magic[4:8]):
+		info, err = readMP4(f, st.Size())
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupported, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", path, err)
+	}
+
+	info.Size = st.Size()
+	return info, nil
+}
+
+// isMP4 returns true if the first box type is one that starts an ISO BMFF file
+func isMP4(boxType []byte) bool {
+	switch string(boxType) {
+	case "ftyp", "moov", "mdat", "free", "skip", "wide", "pnot":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,109 @@
+package container
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRead(t *testing.T) {
+	cases := []struct {
+		name   string
+		data   []byte
+		format string
+		tracks []Track
+	}{
+		{"movie.mkv", mkvSample(), "matroska", mkvSampleTracks},
+		{"movie.mp4", mp4Sample(), "mp4", mp4SampleTracks},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			info, err := Read(writeFile(t, tc.name, tc.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Format != tc.format || info.Size != int64(len(tc.data)) {
+				t.Errorf("got %s of %d bytes, want %s of %d bytes", info.Format, info.Size, tc.format, len(tc.data))
+			}
+			if !reflect.DeepEqual(info.Tracks, tc.tracks) {
+				t.Errorf("tracks:\n got %+v\nwant %+v", info.Tracks, tc.tracks)
+			}
+		})
+	}
+
+	for name, data := range map[string][]byte{
+		"empty.mkv": nil,
+		"short.mkv": {0x1A, 0x45},
+		"text.mp4":  []byte("this is not a video file at all"),
+	} {
+		if _, err := Read(writeFile(t, name, data)); !errors.Is(err, ErrUnsupported) {
+			t.Errorf("%s: got %v, want ErrUnsupported", name, err)
+		}
+	}
+}
+
+// TestReadDamaged checks truncated, corrupted and random input returns an error (or a result) without panicking
+func TestReadDamaged(t *testing.T) {
+	readers := map[string]struct {
+		data []byte
+		read func(data []byte) (*Info, error)
+	}{
+		"matroska": {mkvSample(), func(data []byte) (*Info, error) {
+			return readMatroska(bytes.NewReader(data))
+		}},
+		"mp4": {mp4Sample(), func(data []byte) (*Info, error) {
+			return readMP4(bytes.NewReader(data), int64(len(data)))
+		}},
+	}
+
+	rng := rand.New(rand.NewSource(1))
+
+	for name, r := range readers {
+		t.Run(name, func(t *testing.T) {
+			check := func(desc string, data []byte) {
+				defer func() {
+					if p := recover(); p != nil {
+						t.Fatalf("%s: panic: %v", desc, p)
+					}
+				}()
+				_, _ = r.read(data)
+			}
+
+			for n := range r.data {
+				check("truncated", r.data[:n])
+			}
+
+			for i := 0; i < 2000; i++ {
+				data := bytes.Clone(r.data)
+				for j := rng.Intn(4); j >= 0; j-- {
+					data[rng.Intn(len(data))] = byte(rng.Intn(256))
+				}
+				check("corrupted", data)
+			}
+
+			for i := 0; i < 500; i++ {
+				data := make([]byte, rng.Intn(512))
+				rng.Read(data)
+				check("garbage", data)
+
+				// keep the magic so the parser gets past the first header
+				check("garbage after the magic", append(bytes.Clone(r.data[:12]), data...))
+			}
+		})
+	}
+}
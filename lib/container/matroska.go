@@ -0,0 +1,429 @@
+package container
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// matroska element ids, see https://www.matroska.org/technical/elements.html
+const (
+	mkvEBML          = 0x1A45DFA3
+	mkvDocType       = 0x4282
+	mkvSegment       = 0x18538067
+	mkvCluster       = 0x1F43B675
+	mkvInfo          = 0x1549A966
+	mkvTimecodeScale = 0x2AD7B1
+	mkvDuration      = 0x4489
+	mkvTracks        = 0x1654AE6B
+	mkvTrackEntry    = 0xAE
+	mkvTrackType     = 0x83
+	mkvFlagDefault   = 0x88
+	mkvCodecID       = 0x86
+	mkvLanguage      = 0x22B59C
+	mkvLanguageBCP47 = 0x22B59D
+	mkvName          = 0x536E
+	mkvVideo         = 0xE0
+	mkvPixelWidth    = 0xB0
+	mkvPixelHeight   = 0xBA
+	mkvAudio         = 0xE1
+	mkvSamplingFreq  = 0xB5
+	mkvChannels      = 0x9F
+)
+
+// largest header element read into memory, Info and Tracks are normally a few KB
+const maxMasterSize = 16 << 20
+
+var errUnknownSize = errors.New("unknown element size")
+
+// mkvCodecs maps matroska codec ids (or their prefix) to ffprobe codec names
+var mkvCodecs = map[string]string{
+	"V_MPEGH/ISO/HEVC": "hevc",
+	"V_MPEG4/ISO/AVC":  "h264",
+	"V_MPEG4/ISO":      "mpeg4",
+	"V_AV1":            "av1",
+	"V_VP9":            "vp9",
+	"V_VP8":            "vp8",
+	"V_MPEG2":          "mpeg2video",
+	"V_MPEG1":          "mpeg1video",
+	"V_THEORA":         "theora",
+	"V_MS/VFW/FOURCC":  "vfw",
+	"A_AAC":            "aac",
+	"A_AC3":            "ac3",
+	"A_EAC3":           "eac3",
+	"A_DTS":            "dts",
+	"A_TRUEHD":         "truehd",
+	"A_MLP":            "mlp",
+	"A_FLAC":           "flac",
+	"A_OPUS":           "opus",
+	"A_VORBIS":         "vorbis",
+	"A_MPEG/L3":        "mp3",
+	"A_MPEG/L2":        "mp2",
+	"A_PCM/INT/LIT":    "pcm_s16le",
+	"A_PCM/INT/BIG":    "pcm_s16be",
+	"A_PCM/FLOAT/IEEE": "pcm_f32le",
+	"S_TEXT/UTF8":      "subrip",
+	"S_TEXT/ASS":       "ass",
+	"S_TEXT/SSA":       "ssa",
+	"S_ASS":            "ass",
+	"S_SSA":            "ssa",
+	"S_TEXT/WEBVTT":    "webvtt",
+	"S_HDMV/PGS":       "hdmv_pgs_subtitle",
+	"S_HDMV/TEXTST":    "hdmv_text_subtitle",
+	"S_VOBSUB":         "dvd_subtitle",
+	"S_DVBSUB":         "dvb_subtitle",
+}
+
+// mkvCodec returns the ffprobe name for a matroska codec id, matching the longest known prefix (ie A_AAC/MPEG4/LC)
+func mkvCodec(id string) string {
+	best := ""
+	for prefix := range mkvCodecs {
+		if strings.HasPrefix(id, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return strings.ToLower(id)
+	}
+	return mkvCodecs[best]
+}
+
+func readMatroska(r io.ReadSeeker) (*Info, error) {
+	sr := &streamReader{r: r}
+
+	// EBML header with the doc type
+	id, size, err := sr.header()
+	if err != nil {
+		return nil, err
+	}
+	if id != mkvEBML {
+		return nil, fmt.Errorf("%w: missing EBML header", ErrUnsupported)
+	}
+	head, err := sr.read(size)
+	if err != nil {
+		return nil, err
+	}
+
+	info := Info{Format: "matroska"}
+	err = eachElement(head, func(id uint64, data []byte) error {
+		if id == mkvDocType {
+			info.Format = strings.TrimRight(string(data), "\x00")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if info.Format != "matroska" && info.Format != "webm" {
+		return nil, fmt.Errorf("%w: doc type %q", ErrUnsupported, info.Format)
+	}
+
+	// the segment holds everything else, its size is often unknown when written by a stream
+	id, _, err = sr.header()
+	if err != nil && !errors.Is(err, errUnknownSize) {
+		return nil, err
+	}
+	if id != mkvSegment {
+		return nil, errors.New("missing segment")
+	}
+
+	var haveInfo, haveTracks bool
+	for !haveInfo || !haveTracks {
+		id, size, err := sr.header()
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || (errors.Is(err, errUnknownSize) && id == mkvCluster) {
+			// the headers are before the first cluster, past here it is only frames
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if id == mkvCluster {
+			break
+		}
+
+		switch id {
+		case mkvInfo:
+			data, err := sr.read(size)
+			if err != nil {
+				return nil, err
+			}
+			if err := parseMkvInfo(data, &info); err != nil {
+				return nil, err
+			}
+			haveInfo = true
+		case mkvTracks:
+			data, err := sr.read(size)
+			if err != nil {
+				return nil, err
+			}
+			if err := parseMkvTracks(data, &info); err != nil {
+				return nil, err
+			}
+			haveTracks = true
+		default:
+			if err := sr.skip(size); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if !haveTracks {
+		return nil, errors.New("no tracks found before the first cluster")
+	}
+
+	return &info, nil
+}
+
+func parseMkvInfo(data []byte, info *Info) error {
+	scale := uint64(1000000)
+	var duration float64
+
+	err := eachElement(data, func(id uint64, data []byte) error {
+		switch id {
+		case mkvTimecodeScale:
+			scale = readUint(data)
+		case mkvDuration:
+			duration = readFloat(data)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	info.Duration = duration * float64(scale) / 1e9
+	return nil
+}
+
+func parseMkvTracks(data []byte, info *Info) error {
+	return eachElement(data, func(id uint64, data []byte) error {
+		if id != mkvTrackEntry {
+			return nil
+		}
+
+		t := Track{Index: len(info.Tracks), Language: "eng", Default: true}
+		var kind uint64
+		var bcp47 string
+		err := eachElement(data, func(id uint64, data []byte) error {
+			switch id {
+			case mkvTrackType:
+				kind = readUint(data)
+			case mkvFlagDefault:
+				t.Default = readUint(data) == 1
+			case mkvCodecID:
+				t.Codec = mkvCodec(readString(data))
+			case mkvLanguage:
+				t.Language = readString(data)
+			case mkvLanguageBCP47:
+				bcp47 = readString(data)
+			case mkvName:
+				t.Name = readString(data)
+			case mkvVideo:
+				return eachElement(data, func(id uint64, data []byte) error {
+					switch id {
+					case mkvPixelWidth:
+						t.Width = int(readUint(data)) //nolint:gosec
+					case mkvPixelHeight:
+						t.Height = int(readUint(data)) //nolint:gosec
+					}
+					return nil
+				})
+			case mkvAudio:
+				return eachElement(data, func(id uint64, data []byte) error {
+					switch id {
+					case mkvSamplingFreq:
+						t.SampleRate = int(readFloat(data))
+					case mkvChannels:
+						t.Channels = int(readUint(data)) //nolint:gosec
+					}
+					return nil
+				})
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		// BCP47 replaces the old language element when both are present, it only matters when it is a bare 3 letter code
+		if len(bcp47) == 3 {
+			t.Language = bcp47
+		}
+
+		switch kind {
+		case 1:
+			t.Type = TrackVideo
+		case 2:
+			t.Type = TrackAudio
+			if t.Channels == 0 {
+				t.Channels = 1
+			}
+		case 17:
+			t.Type = TrackSubtitle
+		default:
+			// buttons, logos, metadata and complex tracks aren't reported
+			return nil
+		}
+
+		info.Tracks = append(info.Tracks, t)
+		return nil
+	})
+}
+
+// streamReader reads element headers from the file so large elements can be skipped without reading them
+type streamReader struct {
+	r io.ReadSeeker
+}
+
+// header reads an element id and size, errUnknownSize is returned with the id for elements of unknown size
+func (s *streamReader) header() (uint64, int64, error) {
+	id, err := s.vint(true)
+	if err != nil {
+		return 0, 0, err
+	}
+	size, err := s.vint(false)
+	if errors.Is(err, errUnknownSize) {
+		return id, 0, errUnknownSize
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	if size > math.MaxInt64 {
+		return 0, 0, fmt.Errorf("element %x too large", id)
+	}
+
+	return id, int64(size), nil
+}
+
+func (s *streamReader) vint(keepMarker bool) (uint64, error) {
+	var first [1]byte
+	if _, err := io.ReadFull(s.r, first[:]); err != nil {
+		return 0, err
+	}
+
+	n := vintLength(first[0])
+	if n == 0 {
+		return 0, errors.New("invalid variable length integer")
+	}
+
+	buf := make([]byte, n)
+	buf[0] = first[0]
+	if _, err := io.ReadFull(s.r, buf[1:]); err != nil {
+		return 0, err
+	}
+
+	v, _, err := decodeVint(buf, keepMarker)
+	return v, err
+}
+
+func (s *streamReader) read(size int64) ([]byte, error) {
+	if size > maxMasterSize {
+		return nil, fmt.Errorf("header element of %d bytes is too large", size)
+	}
+
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(s.r, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+func (s *streamReader) skip(size int64) error {
+	_, err := s.r.Seek(size, io.SeekCurrent)
+	return err
+}
+
+// vintLength returns the number of bytes in a variable length integer from its first byte
+func vintLength(b byte) int {
+	for i := 0; i < 8; i++ {
+		if b&(0x80>>i) != 0 {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// decodeVint decodes a variable length integer, ids keep the length marker while sizes do not
+func decodeVint(b []byte, keepMarker bool) (uint64, int, error) {
+	if len(b) == 0 {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	n := vintLength(b[0])
+	if n == 0 {
+		return 0, 0, errors.New("invalid variable length integer")
+	}
+	if len(b) < n {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+
+	v := uint64(b[0])
+	if !keepMarker {
+		v &= uint64(0xFF >> n)
+	}
+	allOnes := v == uint64(0xFF>>n)
+	for _, c := range b[1:n] {
+		v = v<<8 | uint64(c)
+		allOnes = allOnes && c == 0xFF
+	}
+	if !keepMarker && allOnes {
+		return 0, n, errUnknownSize
+	}
+
+	return v, n, nil
+}
+
+// eachElement calls fn for each child element in data, an unknown size extends to the end of data
+func eachElement(data []byte, fn func(id uint64, data []byte) error) error {
+	for len(data) > 0 {
+		id, n, err := decodeVint(data, true)
+		if err != nil {
+			return err
+		}
+		data = data[n:]
+
+		size, n, err := decodeVint(data, false)
+		switch {
+		case errors.Is(err, errUnknownSize):
+			size = uint64(len(data) - n)
+		case err != nil:
+			return err
+		}
+		data = data[n:]
+
+		if size > uint64(len(data)) {
+			return fmt.Errorf("element %x overruns its parent", id)
+		}
+		if err := fn(id, data[:size]); err != nil {
+			return err
+		}
+		data = data[size:]
+	}
+
+	return nil
+}
+
+func readUint(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
+
+func readFloat(b []byte) float64 {
+	switch len(b) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b)))
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(b))
+	default:
+		return 0
+	}
+}
+
+func readString(b []byte) string {
+	return strings.TrimRight(string(b), "\x00")
+}
@@ -0,0 +1,280 @@
+package container
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// mkvID encodes an element id, which already includes its length marker
+func mkvID(id uint64) []byte {
+	var b []byte
+	for ; id > 0; id >>= 8 {
+		b = append([]byte{byte(id)}, b...)
+	}
+	return b
+}
+
+// mkvSize encodes size as a variable length integer of width bytes
+func mkvSize(size uint64, width int) []byte {
+	b := make([]byte, width)
+	for i := width - 1; i >= 0; i-- {
+		b[i] = byte(size)
+		size >>= 8
+	}
+	b[0] |= 0x80 >> (width - 1)
+	return b
+}
+
+// mkvEl builds an element with the smallest size that fits
+func mkvEl(id uint64, children ...[]byte) []byte {
+	data := bytes.Join(children, nil)
+	width := 1
+	for uint64(len(data)) >= 1<<(7*width)-1 {
+		width++
+	}
+	return mkvElWidth(id, width, data)
+}
+
+func mkvElWidth(id uint64, width int, data []byte) []byte {
+	return append(append(mkvID(id), mkvSize(uint64(len(data)), width)...), data...)
+}
+
+// mkvUnknown builds the header of an element of unknown size
+func mkvUnknown(id uint64, width int) []byte {
+	size := bytes.Repeat([]byte{0xFF}, width)
+	size[0] = 0xFF >> (width - 1)
+	return append(mkvID(id), size...)
+}
+
+func mkvUint(id, v uint64) []byte {
+	return mkvEl(id, []byte{byte(v >> 8), byte(v)})
+}
+
+func mkvFloat(id uint64, v float64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, math.Float64bits(v))
+	return mkvEl(id, b)
+}
+
+func mkvString(id uint64, s string) []byte {
+	return mkvEl(id, []byte(s))
+}
+
+func mkvHeader(docType string) []byte {
+	return mkvEl(mkvEBML, mkvString(mkvDocType, docType))
+}
+
+// mkvSample is a segment with a video, two audio and a subtitle track followed by a cluster
+func mkvSample() []byte {
+	info := mkvEl(mkvInfo,
+		mkvUint(mkvTimecodeScale, 1000),
+		mkvFloat(mkvDuration, 5000000),
+	)
+	tracks := mkvEl(mkvTracks,
+		mkvEl(mkvTrackEntry,
+			mkvUint(mkvTrackType, 1),
+			mkvString(mkvCodecID, "V_MPEGH/ISO/HEVC"),
+			mkvEl(mkvVideo, mkvUint(mkvPixelWidth, 3840), mkvUint(mkvPixelHeight, 2160)),
+		),
+		mkvEl(mkvTrackEntry,
+			mkvUint(mkvTrackType, 2),
+			mkvString(mkvCodecID, "A_EAC3"),
+			mkvString(mkvLanguage, "ger"),
+			mkvUint(mkvFlagDefault, 0),
+			mkvEl(mkvAudio, mkvFloat(mkvSamplingFreq, 48000), mkvUint(mkvChannels, 6)),
+		),
+		mkvEl(mkvTrackEntry,
+			mkvUint(mkvTrackType, 2),
+			mkvString(mkvCodecID, "A_AAC/MPEG4/LC"),
+		),
+		mkvEl(mkvTrackEntry,
+			mkvUint(mkvTrackType, 0x21), // metadata, not reported
+			mkvString(mkvCodecID, "B_VOBBTN"),
+		),
+		mkvEl(mkvTrackEntry,
+			mkvUint(mkvTrackType, 17),
+			mkvString(mkvCodecID, "S_TEXT/UTF8"),
+			mkvString(mkvLanguage, "fre"),
+			mkvString(mkvLanguageBCP47, "spa"),
+			mkvString(mkvName, "Forced\x00"),
+		),
+	)
+	void := mkvEl(0xEC, make([]byte, 300))
+
+	return bytes.Join([][]byte{
+		mkvHeader("matroska"),
+		mkvEl(mkvSegment, void, info, tracks, mkvEl(mkvCluster, make([]byte, 10))),
+	}, nil)
+}
+
+var mkvSampleTracks = []Track{
+	{Index: 0, Type: TrackVideo, Codec: "hevc", Language: "eng", Default: true, Width: 3840, Height: 2160},
+	{Index: 1, Type: TrackAudio, Codec: "eac3", Language: "ger", Channels: 6, SampleRate: 48000},
+	{Index: 2, Type: TrackAudio, Codec: "aac", Language: "eng", Default: true, Channels: 1},
+	{Index: 3, Type: TrackSubtitle, Codec: "subrip", Name: "Forced", Language: "spa", Default: true},
+}
+
+func TestDecodeVint(t *testing.T) {
+	cases := []struct {
+		name       string
+		in         []byte
+		keepMarker bool
+		want       uint64
+		n          int
+		err        error
+	}{
+		{"one byte", []byte{0x81}, false, 1, 1, nil},
+		{"two bytes", []byte{0x40, 0x02}, false, 2, 2, nil},
+		{"wide encoding of a small value", []byte{0x10, 0x00, 0x00, 0x05}, false, 5, 4, nil},
+		{"eight bytes", []byte{0x01, 0, 0, 0, 0, 0, 0x01, 0x00}, false, 256, 8, nil},
+		{"id keeps the marker", []byte{0x1A, 0x45, 0xDF, 0xA3}, true, mkvEBML, 4, nil},
+		{"trailing data is ignored", []byte{0x82, 0xFF}, false, 2, 1, nil},
+		{"unknown size", []byte{0xFF}, false, 0, 1, errUnknownSize},
+		{"unknown size of two bytes", []byte{0x7F, 0xFF}, false, 0, 2, errUnknownSize},
+		{"all ones id is not unknown", []byte{0xFF}, true, 0xFF, 1, nil},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			v, n, err := decodeVint(tc.in, tc.keepMarker)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("got error %v, want %v", err, tc.err)
+			}
+			if v != tc.want || n != tc.n {
+				t.Errorf("got %d (%d bytes), want %d (%d bytes)", v, n, tc.want, tc.n)
+			}
+		})
+	}
+
+	for name, in := range map[string][]byte{
+		"empty":     nil,
+		"zero byte": {0x00},
+		"truncated": {0x20, 0x01},
+	} {
+		if _, _, err := decodeVint(in, false); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestReadMatroska(t *testing.T) {
+	info, err := readMatroska(bytes.NewReader(mkvSample()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Format != "matroska" {
+		t.Errorf("format: got %q, want matroska", info.Format)
+	}
+	if info.Duration != 5 {
+		t.Errorf("duration: got %v, want 5", info.Duration)
+	}
+	if !reflect.DeepEqual(info.Tracks, mkvSampleTracks) {
+		t.Errorf("tracks:\n got %+v\nwant %+v", info.Tracks, mkvSampleTracks)
+	}
+}
+
+func TestReadMatroskaLayouts(t *testing.T) {
+	tracks := mkvEl(mkvTracks, mkvEl(mkvTrackEntry,
+		mkvUint(mkvTrackType, 1),
+		mkvString(mkvCodecID, "V_MPEG4/ISO/AVC"),
+		mkvEl(mkvVideo, mkvUint(mkvPixelWidth, 1920), mkvUint(mkvPixelHeight, 1080)),
+	))
+	info := mkvEl(mkvInfo, mkvFloat(mkvDuration, 1500))
+
+	cases := []struct {
+		name     string
+		data     []byte
+		format   string
+		duration float64
+		err      string
+	}{
+		{
+			name:     "streamed segment and cluster of unknown size",
+			data:     bytes.Join([][]byte{mkvHeader("webm"), mkvUnknown(mkvSegment, 8), info, tracks, mkvUnknown(mkvCluster, 1), {0xA3, 0x81, 0x00}}, nil),
+			format:   "webm",
+			duration: 1.5,
+		},
+		{
+			name: "eight byte sizes",
+			data: bytes.Join([][]byte{
+				mkvHeader("matroska"),
+				mkvElWidth(mkvSegment, 8, append(mkvElWidth(mkvInfo, 8, mkvFloat(mkvDuration, 1500)), mkvElWidth(mkvTracks, 8, tracks[len(mkvID(mkvTracks))+1:])...)),
+			}, nil),
+			format:   "matroska",
+			duration: 1.5,
+		},
+		{
+			name:     "tracks before info",
+			data:     bytes.Join([][]byte{mkvHeader("matroska"), mkvEl(mkvSegment, tracks, info)}, nil),
+			format:   "matroska",
+			duration: 1.5,
+		},
+		{
+			name:   "track entry of unknown size",
+			data:   bytes.Join([][]byte{mkvHeader("matroska"), mkvEl(mkvSegment, mkvEl(mkvTracks, mkvUnknown(mkvTrackEntry, 1), mkvUint(mkvTrackType, 1), mkvString(mkvCodecID, "V_MPEG4/ISO/AVC"), mkvEl(mkvVideo, mkvUint(mkvPixelWidth, 1920), mkvUint(mkvPixelHeight, 1080))))}, nil),
+			format: "matroska",
+		},
+		{
+			name: "tracks after the first cluster",
+			data: bytes.Join([][]byte{mkvHeader("matroska"), mkvEl(mkvSegment, info, mkvEl(mkvCluster, []byte{0xA3, 0x81, 0x00}), tracks)}, nil),
+			err:  "no tracks found",
+		},
+		{
+			name: "unsupported doc type",
+			data: bytes.Join([][]byte{mkvHeader("mkv3d"), mkvEl(mkvSegment, info, tracks)}, nil),
+			err:  "unsupported container",
+		},
+		{
+			name: "missing segment",
+			data: bytes.Join([][]byte{mkvHeader("matroska"), info, tracks}, nil),
+			err:  "missing segment",
+		},
+		{
+			name: "child overruns its parent",
+			data: bytes.Join([][]byte{mkvHeader("matroska"), mkvEl(mkvSegment, mkvEl(mkvTracks, []byte{mkvTrackEntry, 0x85, mkvTrackType, 0x81, 0x01}))}, nil),
+			err:  "overruns",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			info, err := readMatroska(bytes.NewReader(tc.data))
+			if tc.err != "" {
+				if err == nil || !bytes.Contains([]byte(err.Error()), []byte(tc.err)) {
+					t.Fatalf("got %v, want an error containing %q", err, tc.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if info.Format != tc.format || info.Duration != tc.duration {
+				t.Errorf("got %s %vs, want %s %vs", info.Format, info.Duration, tc.format, tc.duration)
+			}
+			want := []Track{{Type: TrackVideo, Codec: "h264", Language: "eng", Default: true, Width: 1920, Height: 1080}}
+			if !reflect.DeepEqual(info.Tracks, want) {
+				t.Errorf("tracks:\n got %+v\nwant %+v", info.Tracks, want)
+			}
+		})
+	}
+}
+
+func TestMkvCodec(t *testing.T) {
+	for id, want := range map[string]string{
+		"A_AAC/MPEG4/LC/SBR": "aac",
+		"V_MPEG4/ISO/AVC":    "h264",
+		"V_MPEG4/ISO/ASP":    "mpeg4",
+		"A_DTS/EXPRESS":      "dts",
+		"V_REAL/RV40":        "v_real/rv40",
+	} {
+		if got := mkvCodec(id); got != want {
+			t.Errorf("%s: got %q, want %q", id, got, want)
+		}
+	}
+}
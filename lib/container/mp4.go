@@ -0,0 +1,474 @@
+package container
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// largest moov box read into memory, it holds the sample tables so can be a few MB for long files
+const maxMoovSize = 256 << 20
+
+// mp4Codecs maps sample entry formats to ffprobe codec names
+var mp4Codecs = map[string]string{
+	"avc1": "h264",
+	"avc3": "h264",
+	"hvc1": "hevc",
+	"hev1": "hevc",
+	"dvh1": "hevc",
+	"dvhe": "hevc",
+	"av01": "av1",
+	"vp09": "vp9",
+	"vp08": "vp8",
+	"mp4v": "mpeg4",
+	"mp4a": "aac",
+	"ac-3": "ac3",
+	"ec-3": "eac3",
+	"ac-4": "ac4",
+	"dtsc": "dts",
+	"dtsh": "dts",
+	"dtsl": "dts",
+	"Opus": "opus",
+	"fLaC": "flac",
+	"alac": "alac",
+	".mp3": "mp3",
+	"tx3g": "mov_text",
+	"text": "mov_text",
+	"wvtt": "webvtt",
+	"stpp": "ttml",
+}
+
+func readMP4(r io.ReadSeeker, size int64) (*Info, error) {
+	info := Info{Format: "mp4"}
+
+	// walk the top level boxes to find moov, which is often after mdat
+	var pos int64
+	for pos < size {
+		if _, err := r.Seek(pos, io.SeekStart); err != nil {
+			return nil, err
+		}
+
+		var head [16]byte
+		if _, err := io.ReadFull(r, head[:8]); err != nil {
+			return nil, err
+		}
+		boxSize := int64(binary.BigEndian.Uint32(head[:4]))
+		boxType := string(head[4:8])
+		headSize := int64(8)
+
+		switch boxSize {
+		case 0:
+			boxSize = size - pos
+		case 1:
+			if _, err := io.ReadFull(r, head[8:16]); err != nil {
+				return nil, err
+			}
+			boxSize = int64(binary.BigEndian.Uint64(head[8:16])) //nolint:gosec
+			headSize = 16
+		}
+		if boxSize < headSize {
+			return nil, fmt.Errorf("invalid %q box size %d", boxType, boxSize)
+		}
+
+		switch boxType {
+		case "ftyp":
+			var brand [4]byte
+			if _, err := io.ReadFull(r, brand[:]); err == nil && string(brand[:]) == "qt  " {
+				info.Format = "mov"
+			}
+		case "moov":
+			if boxSize-headSize > maxMoovSize {
+				return nil, fmt.Errorf("moov box of %d bytes is too large", boxSize)
+			}
+			if boxSize > size-pos {
+				return nil, fmt.Errorf("moov box of %d bytes overruns the file", boxSize)
+			}
+			data := make([]byte, boxSize-headSize)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return nil, err
+			}
+			if err := parseMoov(data, &info); err != nil {
+				return nil, err
+			}
+			return &info, nil
+		}
+
+		pos += boxSize
+	}
+
+	return nil, errors.New("no moov box found")
+}
+
+// eachBox calls fn for each child box in data
+func eachBox(data []byte, fn func(boxType string, data []byte) error) error {
+	for len(data) >= 8 {
+		size := uint64(binary.BigEndian.Uint32(data[:4]))
+		boxType := string(data[4:8])
+		head := uint64(8)
+
+		switch size {
+		case 0:
+			size = uint64(len(data))
+		case 1:
+			if len(data) < 16 {
+				return io.ErrUnexpectedEOF
+			}
+			size = binary.BigEndian.Uint64(data[8:16])
+			head = 16
+		}
+		if size < head || size > uint64(len(data)) {
+			return fmt.Errorf("%q box overruns its parent", boxType)
+		}
+
+		if err := fn(boxType, data[head:size]); err != nil {
+			return err
+		}
+		data = data[size:]
+	}
+
+	return nil
+}
+
+func parseMoov(data []byte, info *Info) error {
+	return eachBox(data, func(boxType string, data []byte) error {
+		switch boxType {
+		case "mvhd":
+			timescale, duration, _ := parseTimes(data)
+			if timescale > 0 {
+				info.Duration = float64(duration) / float64(timescale)
+			}
+		case "trak":
+			t, ok, err := parseTrak(data)
+			if err != nil {
+				return err
+			}
+			if ok {
+				t.Index = len(info.Tracks)
+				info.Tracks = append(info.Tracks, t)
+			}
+		}
+		return nil
+	})
+}
+
+// parseTimes returns the timescale and duration from a mvhd or mdhd box along with the offset after them
+func parseTimes(data []byte) (uint32, uint64, int) {
+	if len(data) < 4 {
+		return 0, 0, 0
+	}
+
+	// version and flags, then creation and modification times
+	if data[0] == 1 {
+		if len(data) < 32 {
+			return 0, 0, 0
+		}
+		return binary.BigEndian.Uint32(data[20:24]), binary.BigEndian.Uint64(data[24:32]), 32
+	}
+
+	if len(data) < 20 {
+		return 0, 0, 0
+	}
+	return binary.BigEndian.Uint32(data[12:16]), uint64(binary.BigEndian.Uint32(data[16:20])), 20
+}
+
+func parseTrak(data []byte) (Track, bool, error) {
+	t := Track{Language: "und"}
+	var handler string
+	var stsd []byte
+
+	err := eachBox(data, func(boxType string, data []byte) error {
+		switch boxType {
+		case "tkhd":
+			// enabled tracks are the ones a player picks by default
+			if len(data) >= 4 {
+				t.Default = data[3]&0x1 != 0
+			}
+			// width and height are 16.16 fixed point at the end, used if the sample entry doesn't have them
+			if len(data) >= 8 {
+				t.Width = int(binary.BigEndian.Uint32(data[len(data)-8:]) >> 16)
+				t.Height = int(binary.BigEndian.Uint32(data[len(data)-4:]) >> 16)
+			}
+		case "mdia":
+			return eachBox(data, func(boxType string, data []byte) error {
+				switch boxType {
+				case "mdhd":
+					if _, _, off := parseTimes(data); off > 0 && len(data) >= off+2 {
+						t.Language = unpackLanguage(binary.BigEndian.Uint16(data[off:]))
+					}
+				case "hdlr":
+					if len(data) >= 12 {
+						handler = string(data[8:12])
+					}
+				case "minf":
+					return eachBox(data, func(boxType string, data []byte) error {
+						if boxType != "stbl" {
+							return nil
+						}
+						return eachBox(data, func(boxType string, data []byte) error {
+							if boxType == "stsd" {
+								stsd = data
+							}
+							return nil
+						})
+					})
+				}
+				return nil
+			})
+		case "udta":
+			return eachBox(data, func(boxType string, data []byte) error {
+				if boxType == "name" {
+					t.Name = readString(data)
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return t, false, err
+	}
+
+	switch handler {
+	case "vide":
+		t.Type = TrackVideo
+	case "soun":
+		t.Type = TrackAudio
+		t.Width, t.Height = 0, 0
+	case "sbtl", "subt", "text":
+		t.Type = TrackSubtitle
+		t.Width, t.Height = 0, 0
+	default:
+		// hint, timecode, chapter and metadata tracks aren't reported
+		return t, false, nil
+	}
+	parseStsd(stsd, &t)
+
+	return t, true, nil
+}
+
+// parseStsd reads the codec and dimensions or audio format from the first sample entry
+func parseStsd(data []byte, t *Track) {
+	// version, flags and entry count
+	if len(data) < 8+8 {
+		return
+	}
+	entry := data[8:]
+	size := binary.BigEndian.Uint32(entry[:4])
+	format := string(entry[4:8])
+	if size < 8 || int(size) > len(entry) {
+		return
+	}
+	body := entry[8:size]
+
+	if codec, ok := mp4Codecs[format]; ok {
+		t.Codec = codec
+	} else {
+		t.Codec = format
+	}
+
+	// after the reserved bytes and data reference index come the video or audio specific fields
+	if len(body) < 28 {
+		return
+	}
+	switch t.Type {
+	case TrackVideo:
+		t.Width = int(binary.BigEndian.Uint16(body[24:26]))
+		t.Height = int(binary.BigEndian.Uint16(body[26:28]))
+	case TrackAudio:
+		// version, revision, vendor, channels, sample size, compression, packet size, 16.16 rate. The channel count here
+		// is 2 for most AC-3, E-AC-3 and AAC whatever the layout, so it only comes from the codec's own config box
+		t.SampleRate = int(binary.BigEndian.Uint32(body[24:28]) >> 16)
+
+		// quicktime v1 and v2 sound descriptions have more fields before the child boxes
+		children := body[28:]
+		switch binary.BigEndian.Uint16(body[8:10]) {
+		case 1:
+			children = nil
+			if len(body) >= 44 {
+				children = body[44:]
+			}
+		case 2:
+			children = nil
+			if len(body) >= 64 {
+				children = body[64:]
+			}
+		}
+
+		// a bad child box only loses the channel count
+		_ = eachBox(children, func(boxType string, data []byte) error {
+			if n := audioConfigChannels(boxType, data); n > 0 {
+				t.Channels = n
+			}
+			return nil
+		})
+	case TrackSubtitle:
+	}
+}
+
+// ac3Channels is the number of full range channels for each AC-3 audio coding mode
+var ac3Channels = [8]int{2, 1, 2, 3, 3, 4, 4, 5}
+
+// audioConfigChannels returns the channel count (including LFE) from a codec configuration box, 0 if unknown
+func audioConfigChannels(boxType string, data []byte) int {
+	switch boxType {
+	case "dac3":
+		// fscod, bsid and bsmod then acmod and lfeon
+		if len(data) < 3 {
+			return 0
+		}
+		return ac3Channels[data[1]>>3&0x7] + int(data[1]>>2&0x1)
+	case "dec3":
+		// data rate and substream count, then the first independent substream
+		if len(data) < 5 {
+			return 0
+		}
+		n := ac3Channels[data[3]>>1&0x7] + int(data[3]&0x1)
+
+		// dependent substreams add the channels in chan_loc, ie the back pair for 7.1
+		if data[4]>>1&0xF > 0 && len(data) >= 6 {
+			loc := uint16(data[4]&0x1)<<8 | uint16(data[5])
+			for bit, count := range []int{2, 2, 1, 1, 2, 2, 2, 1, 1} {
+				if loc&(1<<bit) != 0 {
+					n += count
+				}
+			}
+		}
+		return n
+	case "dOps":
+		// version then the output channel count
+		if len(data) < 2 {
+			return 0
+		}
+		return int(data[1])
+	case "esds":
+		return esdsChannels(data)
+	}
+
+	return 0
+}
+
+// esdsChannels returns the channel count from the AAC AudioSpecificConfig inside an esds box, 0 if unknown
+func esdsChannels(data []byte) int {
+	// version and flags
+	if len(data) < 4 {
+		return 0
+	}
+	data = data[4:]
+
+	es, ok := descriptor(data, 0x03)
+	if !ok || len(es) < 3 {
+		return 0
+	}
+	flags := es[2]
+	es = es[3:]
+	if flags&0x80 != 0 { // depends on another stream
+		if len(es) < 2 {
+			return 0
+		}
+		es = es[2:]
+	}
+	if flags&0x40 != 0 { // url
+		if len(es) < 1 || len(es) < 1+int(es[0]) {
+			return 0
+		}
+		es = es[1+int(es[0]):]
+	}
+	if flags&0x20 != 0 { // ocr stream
+		if len(es) < 2 {
+			return 0
+		}
+		es = es[2:]
+	}
+
+	// object type, stream type, buffer size and bitrates come before the decoder specific info
+	dc, ok := descriptor(es, 0x04)
+	if !ok || len(dc) < 13 {
+		return 0
+	}
+	asc, ok := descriptor(dc[13:], 0x05)
+	if !ok {
+		return 0
+	}
+
+	// 5 bit object type (escaped to 6 more bits), 4 bit sample rate index (escaped to 24 bits), 4 bit channel config
+	br := bitReader{data: asc}
+	if br.bits(5) == 31 {
+		br.bits(6)
+	}
+	if br.bits(4) == 15 {
+		br.bits(24)
+	}
+	config := br.bits(4)
+	if br.overrun {
+		return 0
+	}
+
+	switch {
+	case config >= 1 && config <= 6:
+		return int(config)
+	case config == 7:
+		return 8
+	default:
+		// 0 is described by a program config element, the rest are rare layouts
+		return 0
+	}
+}
+
+// descriptor returns the body of an MPEG-4 descriptor with the given tag at the start of data
+func descriptor(data []byte, tag byte) ([]byte, bool) {
+	if len(data) < 2 || data[0] != tag {
+		return nil, false
+	}
+
+	// the size is up to 4 bytes of 7 bits with the high bit set on all but the last
+	size, i := 0, 1
+	for {
+		if i >= len(data) || i > 4 {
+			return nil, false
+		}
+		b := data[i]
+		i++
+		size = size<<7 | int(b&0x7F)
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	if size > len(data)-i {
+		return nil, false
+	}
+
+	return data[i : i+size], true
+}
+
+// bitReader reads big endian bit fields, reading past the end sets overrun and returns 0
+type bitReader struct {
+	data    []byte
+	pos     int
+	overrun bool
+}
+
+func (b *bitReader) bits(n int) uint32 {
+	var v uint32
+	for ; n > 0; n-- {
+		if b.pos >= len(b.data)*8 {
+			b.overrun = true
+			return 0
+		}
+		v = v<<1 | uint32(b.data[b.pos/8]>>(7-b.pos%8)&0x1)
+		b.pos++
+	}
+	return v
+}
+
+// unpackLanguage decodes the ISO 639-2/T code packed as three 5 bit letters
+func unpackLanguage(packed uint16) string {
+	if packed == 0 || packed == 0x7FFF {
+		return "und"
+	}
+	return string([]byte{
+		byte(packed>>10&0x1F) + 0x60,
+		byte(packed>>5&0x1F) + 0x60,
+		byte(packed&0x1F) + 0x60,
+	})
+}
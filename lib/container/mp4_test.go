@@ -0,0 +1,240 @@
+package container
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+func u16(v int) []byte {
+	return binary.BigEndian.AppendUint16(nil, uint16(v))
+}
+
+func u32(v int) []byte {
+	return binary.BigEndian.AppendUint32(nil, uint32(v))
+}
+
+// box builds an ISO BMFF box
+func box(boxType string, children ...[]byte) []byte {
+	data := bytes.Join(children, nil)
+	return bytes.Join([][]byte{u32(8 + len(data)), []byte(boxType), data}, nil)
+}
+
+// packLanguage packs an ISO 639-2/T code into three 5 bit letters
+func packLanguage(lang string) int {
+	return int(lang[0]-0x60)<<10 | int(lang[1]-0x60)<<5 | int(lang[2]-0x60)
+}
+
+func mdhd(version int, lang string) []byte {
+	if version == 1 {
+		return box("mdhd", []byte{1, 0, 0, 0}, make([]byte, 16), u32(1000), make([]byte, 8), u16(packLanguage(lang)), u16(0))
+	}
+	return box("mdhd", []byte{0, 0, 0, 0}, make([]byte, 8), u32(1000), u32(0), u16(packLanguage(lang)), u16(0))
+}
+
+func hdlr(handler string) []byte {
+	return box("hdlr", make([]byte, 8), []byte(handler), make([]byte, 13))
+}
+
+// tkhd is a track header with the enabled flag and 16.16 width and height at the end
+func tkhd(enabled bool, width, height int) []byte {
+	flags := byte(0)
+	if enabled {
+		flags = 1
+	}
+	return box("tkhd", []byte{0, 0, 0, flags}, make([]byte, 72), u32(width<<16), u32(height<<16))
+}
+
+func stsd(entry []byte) []byte {
+	return box("stsd", []byte{0, 0, 0, 0}, u32(1), entry)
+}
+
+func videoEntry(format string, width, height int) []byte {
+	return box(format, make([]byte, 8), make([]byte, 16), u16(width), u16(height), make([]byte, 50))
+}
+
+// audioEntry is a version 0 sound description with the channel count field set to 2 as most muxers do
+func audioEntry(format string, children ...[]byte) []byte {
+	return box(format, make([]byte, 8), u16(0), make([]byte, 6), u16(2), u16(16), make([]byte, 4), u32(48000<<16), bytes.Join(children, nil))
+}
+
+func trak(header, lang, handler string, entry []byte, extra ...[]byte) []byte {
+	var th []byte
+	switch header {
+	case "enabled":
+		th = tkhd(true, 0, 0)
+	case "video":
+		th = tkhd(true, 1920, 1080)
+	default:
+		th = tkhd(false, 0, 0)
+	}
+	mdia := box("mdia", mdhd(0, lang), hdlr(handler), box("minf", box("stbl", stsd(entry))))
+	return box("trak", append([][]byte{th, mdia}, extra...)...)
+}
+
+// esds builds an AAC elementary stream descriptor around an AudioSpecificConfig
+func esds(asc []byte, wideSizes bool) []byte {
+	desc := func(tag byte, body []byte) []byte {
+		if wideSizes {
+			return append([]byte{tag, 0x80, 0x80, 0x80, byte(len(body))}, body...)
+		}
+		return append([]byte{tag, byte(len(body))}, body...)
+	}
+
+	dsi := desc(0x05, asc)
+	dc := desc(0x04, append(append([]byte{0x40, 0x15}, make([]byte, 11)...), dsi...))
+	es := desc(0x03, append([]byte{0, 1, 0}, dc...))
+	return box("esds", []byte{0, 0, 0, 0}, es)
+}
+
+func mvhd(timescale, duration int) []byte {
+	return box("mvhd", []byte{0, 0, 0, 0}, make([]byte, 8), u32(timescale), u32(duration), make([]byte, 80))
+}
+
+// mp4Sample is a movie with mdat before moov and a track of each kind
+func mp4Sample() []byte {
+	moov := box("moov",
+		mvhd(1000, 90000),
+		trak("video", "und", "vide", videoEntry("hvc1", 3840, 2160)),
+		trak("enabled", "eng", "soun", audioEntry("ec-3", box("dec3", []byte{0x00, 0x00, 0x00, 0x0F, 0x02, 0x02}))),
+		trak("", "ger", "soun", audioEntry("ac-3", box("dac3", []byte{0x10, 0x3C, 0x00}))),
+		trak("", "jpn", "soun", audioEntry("mp4a", esds([]byte{0x11, 0x90}, true))),
+		trak("", "eng", "soun", audioEntry("mp4a")),
+		trak("", "fre", "tmcd", box("tmcd", make([]byte, 20))),
+		trak("", "fre", "sbtl", box("tx3g", make([]byte, 40)), box("udta", box("name", []byte("Forced")))),
+	)
+	return bytes.Join([][]byte{box("ftyp", []byte("isom"), u32(0)), box("mdat", make([]byte, 1000)), moov}, nil)
+}
+
+var mp4SampleTracks = []Track{
+	{Index: 0, Type: TrackVideo, Codec: "hevc", Language: "und", Default: true, Width: 3840, Height: 2160},
+	{Index: 1, Type: TrackAudio, Codec: "eac3", Language: "eng", Default: true, Channels: 8, SampleRate: 48000},
+	{Index: 2, Type: TrackAudio, Codec: "ac3", Language: "ger", Channels: 6, SampleRate: 48000},
+	{Index: 3, Type: TrackAudio, Codec: "aac", Language: "jpn", Channels: 2, SampleRate: 48000},
+	{Index: 4, Type: TrackAudio, Codec: "aac", Language: "eng", SampleRate: 48000},
+	{Index: 5, Type: TrackSubtitle, Codec: "mov_text", Name: "Forced", Language: "fre"},
+}
+
+func TestReadMP4(t *testing.T) {
+	data := mp4Sample()
+	info, err := readMP4(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Format != "mp4" {
+		t.Errorf("format: got %q, want mp4", info.Format)
+	}
+	if info.Duration != 90 {
+		t.Errorf("duration: got %v, want 90", info.Duration)
+	}
+	if !reflect.DeepEqual(info.Tracks, mp4SampleTracks) {
+		t.Errorf("tracks:\n got %+v\nwant %+v", info.Tracks, mp4SampleTracks)
+	}
+}
+
+func TestReadMP4Layouts(t *testing.T) {
+	video := trak("video", "eng", "vide", videoEntry("avc1", 1280, 720))
+	moov := box("moov", mvhd(600, 900), video)
+
+	// a 64 bit size box, the size includes the 16 byte header
+	largeMdat := bytes.Join([][]byte{u32(1), []byte("mdat"), binary.BigEndian.AppendUint64(nil, 16+100), make([]byte, 100)}, nil)
+
+	cases := []struct {
+		name   string
+		data   []byte
+		format string
+		err    string
+	}{
+		{"moov first", bytes.Join([][]byte{box("ftyp", []byte("mp42")), moov, box("mdat", make([]byte, 100))}, nil), "mp4", ""},
+		{"quicktime brand", bytes.Join([][]byte{box("ftyp", []byte("qt  ")), box("wide"), box("mdat", make([]byte, 100)), moov}, nil), "mov", ""},
+		{"64 bit mdat", bytes.Join([][]byte{box("ftyp", []byte("isom")), largeMdat, moov}, nil), "mp4", ""},
+		{"moov extends to the end of the file", bytes.Join([][]byte{box("ftyp", []byte("isom")), append(u32(0), moov[4:]...)}, nil), "mp4", ""},
+		{"no moov", bytes.Join([][]byte{box("ftyp", []byte("isom")), box("mdat", make([]byte, 100))}, nil), "", "no moov"},
+		{"box smaller than its header", bytes.Join([][]byte{box("ftyp", []byte("isom")), u32(4), []byte("free")}, nil), "", "invalid"},
+		{"child overruns the moov", bytes.Join([][]byte{box("ftyp", []byte("isom")), box("moov", u32(100), []byte("trak"))}, nil), "", "overruns"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			info, err := readMP4(bytes.NewReader(tc.data), int64(len(tc.data)))
+			if tc.err != "" {
+				if err == nil || !bytes.Contains([]byte(err.Error()), []byte(tc.err)) {
+					t.Fatalf("got %v, want an error containing %q", err, tc.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if info.Format != tc.format || info.Duration != 1.5 {
+				t.Errorf("got %s %vs, want %s 1.5s", info.Format, info.Duration, tc.format)
+			}
+			want := []Track{{Type: TrackVideo, Codec: "h264", Language: "eng", Default: true, Width: 1280, Height: 720}}
+			if !reflect.DeepEqual(info.Tracks, want) {
+				t.Errorf("tracks:\n got %+v\nwant %+v", info.Tracks, want)
+			}
+		})
+	}
+}
+
+func TestMdhdLanguage(t *testing.T) {
+	cases := []struct {
+		name string
+		mdhd []byte
+		want string
+	}{
+		{"version 0", mdhd(0, "eng"), "eng"},
+		{"version 1", mdhd(1, "jpn"), "jpn"},
+		{"unset", box("mdhd", make([]byte, 24)), "und"},
+		{"truncated", box("mdhd", []byte{1, 0, 0, 0}, make([]byte, 20)), "und"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			data := box("trak", tkhd(true, 0, 0), box("mdia", tc.mdhd, hdlr("soun")))
+			tr, ok, err := parseTrak(data[8:])
+			if err != nil || !ok {
+				t.Fatalf("got ok %v err %v", ok, err)
+			}
+			if tr.Language != tc.want {
+				t.Errorf("got %q, want %q", tr.Language, tc.want)
+			}
+		})
+	}
+}
+
+func TestAudioConfigChannels(t *testing.T) {
+	cases := []struct {
+		name    string
+		boxType string
+		data    []byte
+		want    int
+	}{
+		{"ac3 stereo", "dac3", []byte{0x10, 0x10, 0x00}, 2},
+		{"ac3 5.1", "dac3", []byte{0x10, 0x3C, 0x00}, 6},
+		{"ac3 mono", "dac3", []byte{0x10, 0x08, 0x00}, 1},
+		{"ac3 truncated", "dac3", []byte{0x10}, 0},
+		{"eac3 5.1", "dec3", []byte{0x00, 0x00, 0x00, 0x0F, 0x00}, 6},
+		{"eac3 7.1 with a dependent substream", "dec3", []byte{0x00, 0x00, 0x00, 0x0F, 0x02, 0x02}, 8},
+		{"eac3 truncated", "dec3", []byte{0x00, 0x00}, 0},
+		{"opus", "dOps", []byte{0x00, 0x06}, 6},
+		{"aac stereo", "esds", esds([]byte{0x11, 0x90}, false)[8:], 2},
+		{"aac 5.1", "esds", esds([]byte{0x11, 0xB0}, false)[8:], 6},
+		{"aac 7.1", "esds", esds([]byte{0x11, 0xB8}, true)[8:], 8},
+		{"aac program config element", "esds", esds([]byte{0x11, 0x80}, false)[8:], 0},
+		{"aac escaped sample rate", "esds", esds([]byte{0x17, 0x80, 0x5D, 0xC0, 0x30}, false)[8:], 6},
+		{"aac truncated config", "esds", esds([]byte{0x11}, false)[8:], 0},
+		{"unknown box", "wave", []byte{0x00, 0x06}, 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := audioConfigChannels(tc.boxType, tc.data); got != tc.want {
+				t.Errorf("got %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
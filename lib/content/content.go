@@ -2,7 +2,6 @@ package content
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"regexp"
 	"strconv"
@@ -35,7 +34,7 @@ func ContentFor(lib *Library, folder string) (*Content, error) {
 		newPath := filepath.Join(filepath.Dir(folder), trimmed)
 
 		c.Printf("  <yellow>WARNING:</> folder has whitespace, renaming %q -> %q\n", f, trimmed)
-		if err := os.Rename(oldPath, newPath); err != nil {
+		if err := ktio.Rename(oldPath, newPath); err != nil {
 			return nil, fmt.Errorf("error renaming folder to remove whitespace: %w", err)
 		}
 
@@ -46,7 +45,7 @@ func ContentFor(lib *Library, folder string) (*Content, error) {
 	c := Content{
 		Library: lib,
 		Folder:  f,
-		Letter:  GetLetter(f),
+		Letter:  letterFolderOf(lib, folder),
 	}
 
 	// get year - look for (YYYY) anywhere in the folder name
@@ -63,6 +62,17 @@ func ContentFor(lib *Library, folder string) (*Content, error) {
 	return &c, nil
 }
 
+// letterFolderOf returns the letter folder a content folder is in when it is a path within a letter foldered library,
+// which may not be the one LetterFor picks if it was filed by hand or the lettering changed. Otherwise it is the
+// letter the folder would be sorted into
+func letterFolderOf(lib *Library, folder string) string {
+	letterPath := filepath.Dir(folder)
+	if lib.LetterFolders && filepath.Dir(letterPath) == filepath.Clean(lib.Path) {
+		return filepath.Base(letterPath)
+	}
+	return lib.LetterFor(filepath.Base(folder))
+}
+
 // Path returns the full path to this content folder
 func (c Content) Path() string {
 	if c.Library.LetterFolders {
@@ -78,18 +88,18 @@ func (c Content) Exists() bool {
 
 // MoveFolder moves this content folder to the given destination path
 func (c Content) MoveFolder(destPath string, prompt bool, indent int) error {
-	return ktio.RunCommand(indent, prompt, "mv", "-v", c.Path(), destPath)
+	return ktio.Move(indent, prompt, c.Path(), destPath)
 }
 
 // DeleteFolder deletes this content folder
 func (c Content) DeleteFolder(prompt bool, indent int) error {
-	return ktio.RunCommand(indent, prompt, "rm", "-rfv", c.Path())
+	return ktio.RemoveAll(indent, prompt, c.Path())
 }
 
 // DestPathIn returns what the path would be in the given destination library
 func (c Content) DestPathIn(destLib *Library) string {
 	if destLib.LetterFolders {
-		return filepath.Join(destLib.Path, c.Letter, c.Folder)
+		return filepath.Join(destLib.Path, destLib.LetterFor(c.Folder), c.Folder)
 	}
 	return filepath.Join(destLib.Path, c.Folder)
 }
@@ -108,7 +118,7 @@ func (c Content) DestPathInWithRename(destLib *Library, libType LibraryType) (st
 	}
 
 	if destLib.LetterFolders {
-		return filepath.Join(destLib.Path, c.Letter, destFolder), nil
+		return filepath.Join(destLib.Path, destLib.LetterFor(destFolder), destFolder), nil
 	}
 	return filepath.Join(destLib.Path, destFolder), nil
 }
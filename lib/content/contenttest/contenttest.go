@@ -0,0 +1,88 @@
+// Package contenttest builds folders of placeholder videos for tests. A video's contents is the name of the saved ffprobe
+// output it is probed as by content.FixtureProber, so the tests need neither ffprobe nor real videos
+package contenttest
+
+import (
+	"bytes"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/katbyte/go-ingest-media/lib/ktio"
+	"github.com/spf13/afero"
+)
+
+// Fixtures is the folder of saved ffprobe output, ie hevc-1080p.mkv.json
+var Fixtures = fixturesDir()
+
+func fixturesDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "testdata", "probes")
+}
+
+// Tree creates files under Root on the current ktio filesystem, the real one or an in memory one set with ktio.SetFS
+type Tree struct {
+	t    testing.TB
+	Root string
+}
+
+// New returns a tree rooted at root
+func New(t testing.TB, root string) *Tree {
+	return &Tree{t: t, Root: root}
+}
+
+// Add creates a file at path (relative to the root) probed as fixture, padded to size bytes when size is larger than
+// the fixture name. An empty fixture has no probe data so probing it fails
+func (tr *Tree) Add(path, fixture string, size int64) string {
+	tr.t.Helper()
+
+	if size > 0 && size < int64(len(fixture)) {
+		tr.t.Fatalf("%s: %d bytes is too small for fixture %s", path, size, fixture)
+	}
+
+	data := []byte(fixture)
+	if pad := size - int64(len(data)); pad > 0 {
+		data = append(data, bytes.Repeat([]byte{0}, int(pad))...)
+	}
+
+	return tr.Write(path, data)
+}
+
+// Write creates a file at path (relative to the root) with data, ie an nfo
+func (tr *Tree) Write(path string, data []byte) string {
+	tr.t.Helper()
+
+	full := filepath.Join(tr.Root, path)
+	if err := ktio.FS().MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		tr.t.Fatal(err)
+	}
+	if err := afero.WriteFile(ktio.FS(), full, data, 0o644); err != nil {
+		tr.t.Fatal(err)
+	}
+
+	return full
+}
+
+// Spec creates the folders and files in a spec of one path per line, folders end with / and videos are followed by
+// `= <fixture>` to be probed as that fixture. Videos of the same fixture are the same size
+func (tr *Tree) Spec(spec string) {
+	tr.t.Helper()
+
+	for _, line := range strings.Split(spec, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		path, fixture, _ := strings.Cut(line, " = ")
+		if strings.HasSuffix(path, "/") {
+			if err := ktio.FS().MkdirAll(filepath.Join(tr.Root, path), 0o755); err != nil {
+				tr.t.Fatal(err)
+			}
+			continue
+		}
+
+		tr.Add(path, fixture, 0)
+	}
+}
@@ -0,0 +1,157 @@
+package content
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/katbyte/go-ingest-media/lib/ktio"
+)
+
+// editions are the cuts recognised in folder and file names, in the order they are listed when there are several
+var editions = []struct {
+	Name  string
+	Regex *regexp.Regexp
+}{
+	{"Director's Cut", regexp.MustCompile(`(?i)\bdirector'?s[ ._-]*cut\b`)},
+	{"Final Cut", regexp.MustCompile(`(?i)\bfinal[ ._-]*cut\b`)},
+	{"Theatrical", regexp.MustCompile(`(?i)\btheatrical\b`)},
+	{"Extended", regexp.MustCompile(`(?i)\bextended\b`)},
+	{"Unrated", regexp.MustCompile(`(?i)\bunrated\b`)},
+	{"IMAX", regexp.MustCompile(`(?i)\bimax\b`)},
+	{"Remastered", regexp.MustCompile(`(?i)\bremaster(ed)?\b`)},
+}
+
+// the jellyfin edition tag ie Movie (2000) {edition-Special Edition}.mkv
+var editionTagRegex = regexp.MustCompile(`(?i)\{edition-([^}]+)\}`)
+
+// a year in a name, editions are only looked for after it so titles like The Extended Family are not matched
+var editionYearRegex = regexp.MustCompile(`\b(19|20)\d{2}\b`)
+
+// videos whose durations differ by more than this many seconds are taken to be different cuts
+const cutDurationDiff = 5 * 60
+
+// ParseEdition returns the edition in a folder or file name, ie "Final Cut" for "Blade Runner (1982) - Final Cut", or
+// "" if there is none
+func ParseEdition(name string) string {
+	if m := editionTagRegex.FindStringSubmatch(name); m != nil {
+		return strings.TrimSpace(m[1])
+	}
+
+	if loc := editionYearRegex.FindAllStringIndex(name, -1); len(loc) > 0 {
+		name = name[loc[len(loc)-1][1]:]
+	}
+
+	var found []string
+	for _, e := range editions {
+		if e.Regex.MatchString(name) {
+			found = append(found, e.Name)
+		}
+	}
+	return strings.Join(found, " ")
+}
+
+// EditionOf returns the edition of a video from its file name, its folder name or the <edition> in its nfo, "" if
+// none is found. A folder nfo is only used when the video is alone in the folder as it can't say which version it
+// describes
+func EditionOf(v VideoFile) string {
+	if e := ParseEdition(strings.TrimSuffix(filepath.Base(v.Path), filepath.Ext(v.Path))); e != "" {
+		return e
+	}
+
+	dir := filepath.Dir(v.Path)
+	if e := ParseEdition(filepath.Base(dir)); e != "" {
+		return e
+	}
+
+	// a version's own nfo is named after the video, the folder's nfo only describes the video when it is the only one
+	nfoPath := strings.TrimSuffix(v.Path, filepath.Ext(v.Path)) + ".nfo"
+	if !ktio.PathExists(ktio.RealPath(nfoPath)) {
+		if !onlyVideoIn(dir) {
+			return ""
+		}
+
+		var err error
+		if nfoPath, err = FindNfoFile(dir); err != nil || nfoPath == "" {
+			return ""
+		}
+	}
+	if nfo, err := ReadNfo(nfoPath); err == nil {
+		return strings.TrimSpace(nfo.Edition)
+	}
+	return ""
+}
+
+// onlyVideoIn returns true if the folder holds a single video
+func onlyVideoIn(dir string) bool {
+	files, err := ktio.ListFiles(dir)
+	if err != nil {
+		return false
+	}
+
+	videos := 0
+	for _, f := range files {
+		if IsVideoFile(f) {
+			videos++
+		}
+	}
+	return videos == 1
+}
+
+// DurationsDiffer returns true if the videos' durations are far enough apart for them to be different cuts
+func DurationsDiffer(v1, v2 VideoFile) bool {
+	if v1.FFProbeFailed || v2.FFProbeFailed || v1.Duration <= 0 || v2.Duration <= 0 {
+		return false
+	}
+	return math.Abs(v1.Duration-v2.Duration) > cutDurationDiff
+}
+
+// CutDifference describes why two videos look like different cuts of a movie, a different edition or a large
+// difference in duration, or returns "" if they look like the same cut
+func CutDifference(src, dst VideoFile) string {
+	var reasons []string
+
+	srcEdition, dstEdition := EditionOf(src), EditionOf(dst)
+	if !strings.EqualFold(srcEdition, dstEdition) {
+		reasons = append(reasons, fmt.Sprintf("%s vs %s", editionOrNone(srcEdition), editionOrNone(dstEdition)))
+	}
+
+	if DurationsDiffer(src, dst) {
+		diff := src.Duration - dst.Duration
+		longer := "longer"
+		if diff < 0 {
+			longer = "shorter"
+		}
+		reasons = append(reasons, fmt.Sprintf("%s %s", formatRuntime(math.Abs(diff)), longer))
+	}
+
+	return strings.Join(reasons, ", ")
+}
+
+func editionOrNone(e string) string {
+	if e == "" {
+		return "no edition"
+	}
+	return e
+}
+
+// VersionNames returns the file names to keep both videos side by side in a movie folder with the emby/jellyfin
+// multi-version convention, ie "Blade Runner (1982) - Final Cut.mkv". A video without an edition keeps the plain
+// folder name, and if both have the same edition the source is told apart by its runtime
+func VersionNames(folder string, src, dst VideoFile) (string, string) {
+	srcEdition, dstEdition := EditionOf(src), EditionOf(dst)
+	if strings.EqualFold(srcEdition, dstEdition) {
+		srcEdition = strings.TrimSpace(srcEdition + " " + formatRuntime(src.Duration))
+	}
+
+	return versionName(folder, srcEdition, src.Ext), versionName(folder, dstEdition, dst.Ext)
+}
+
+func versionName(folder, edition, ext string) string {
+	if edition == "" {
+		return folder + ext
+	}
+	return folder + " - " + edition + ext
+}
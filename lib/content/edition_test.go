@@ -0,0 +1,110 @@
+package content
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/katbyte/go-ingest-media/lib/content/contenttest"
+)
+
+func TestParseEdition(t *testing.T) {
+	cases := []struct {
+		name string
+		want string
+	}{
+		{"Blade Runner (1982)", ""},
+		{"Blade Runner (1982) - Final Cut", "Final Cut"},
+		{"Blade.Runner.1982.The.Final.Cut.1080p.BluRay", "Final Cut"},
+		{"Aliens (1986) - Directors Cut", "Director's Cut"},
+		{"Aliens (1986) - Director's Cut", "Director's Cut"},
+		{"Apocalypse Now (1979) - Extended Remastered", "Extended Remastered"},
+		{"Dunkirk (2017) IMAX", "IMAX"},
+		{"The Extended Family (2020)", ""},
+		{"Blade Runner 2049 (2017) - Theatrical", "Theatrical"},
+		{"Movie (2000) {edition-Special Edition}", "Special Edition"},
+		{"Unrated Movie Without A Year", "Unrated"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ParseEdition(tc.name); got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEditionOf(t *testing.T) {
+	fv := contenttest.New(t, t.TempDir())
+	byFile := loadVideo(t, fv.Add("Brazil (1985)/Brazil (1985) - Final Cut.mkv", "hevc-1080p.mkv", 100))
+	byFolder := loadVideo(t, fv.Add("Dunkirk (2017) IMAX/Dunkirk (2017).mkv", "hevc-2160p.mkv", 100))
+	byNfo := loadVideo(t, fv.Add("Aliens (1986)/Aliens (1986).mkv", "h264-1080p.mkv", 100))
+	none := loadVideo(t, fv.Add("Heat (1995)/Heat (1995).mkv", "hevc-1080p.mkv", 100))
+
+	nfo := []byte("<movie><title>Aliens</title><edition>Special Edition</edition></movie>")
+	fv.Write("Aliens (1986)/Aliens (1986).nfo", nfo)
+
+	// with two versions the folder nfo could describe either, only a version's own nfo counts
+	versionNfo := loadVideo(t, fv.Add("Alien (1979)/Alien (1979) - 2160p.mkv", "hevc-2160p.mkv", 100))
+	versionNone := loadVideo(t, fv.Add("Alien (1979)/Alien (1979) - 1080p.mkv", "hevc-1080p.mkv", 100))
+	fv.Write("Alien (1979)/Alien (1979).nfo", []byte("<movie><title>Alien</title><edition>Director's Cut</edition></movie>"))
+	fv.Write("Alien (1979)/Alien (1979) - 2160p.nfo", []byte("<movie><title>Alien</title><edition>Theatrical</edition></movie>"))
+
+	for v, want := range map[*VideoFile]string{
+		&byFile:      "Final Cut",
+		&byFolder:    "IMAX",
+		&byNfo:       "Special Edition",
+		&none:        "",
+		&versionNfo:  "Theatrical",
+		&versionNone: "",
+	} {
+		if got := EditionOf(*v); got != want {
+			t.Errorf("%s: got %q, want %q", filepath.Base(v.Path), got, want)
+		}
+	}
+}
+
+func TestCutDifference(t *testing.T) {
+	fv := contenttest.New(t, t.TempDir())
+	theatrical := loadVideo(t, fv.Add("Brazil (1985)/Brazil (1985).mkv", "hevc-1080p.mkv", 100))
+	reencode := loadVideo(t, fv.Add("Brazil (1985)/Brazil.1985.720p.mkv", "h264-1080p.mkv", 100))
+	finalCut := loadVideo(t, fv.Add("Brazil (1985)/Brazil (1985) - Final Cut.mkv", "hevc-1080p.mkv", 100))
+	short := loadVideo(t, fv.Add("Brazil (1985)/Brazil (1985) - 1080p.mkv", "hevc-1080p-truncated.mkv", 100))
+
+	cases := []struct {
+		name     string
+		src, dst VideoFile
+		want     string
+	}{
+		{"same cut", theatrical, reencode, ""},
+		{"edition", finalCut, theatrical, "Final Cut vs no edition"},
+		{"duration", short, theatrical, "1h10m shorter"},
+		{"edition and duration", finalCut, short, "Final Cut vs no edition, 1h10m longer"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := CutDifference(tc.src, tc.dst); got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestVersionNames(t *testing.T) {
+	fv := contenttest.New(t, t.TempDir())
+	theatrical := loadVideo(t, fv.Add("Brazil (1985)/Brazil.1985.1080p.mkv", "hevc-1080p.mkv", 100))
+	finalCut := loadVideo(t, fv.Add("import/Brazil (1985)/Brazil (1985) - Final Cut.avi", "h264-480p.avi", 100))
+	short := loadVideo(t, fv.Add("import/Brazil (1985)/Brazil (1985).mkv", "hevc-1080p-truncated.mkv", 100))
+
+	src, dst := VersionNames("Brazil (1985)", finalCut, theatrical)
+	if src != "Brazil (1985) - Final Cut.avi" || dst != "Brazil (1985).mkv" {
+		t.Errorf("got %q and %q", src, dst)
+	}
+
+	// neither has an edition so the source is named after its runtime
+	src, dst = VersionNames("Brazil (1985)", short, theatrical)
+	if src != "Brazil (1985) - 20m.mkv" || dst != "Brazil (1985).mkv" {
+		t.Errorf("got %q and %q", src, dst)
+	}
+}
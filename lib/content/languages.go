@@ -0,0 +1,257 @@
+package content
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// LanguageRequirements are the audio and subtitle languages every video in a library must have, as ISO 639-2 codes
+type LanguageRequirements struct {
+	Audio     []string
+	Subtitles []string
+}
+
+// common ISO 639-1 codes, names and ISO 639-2/T codes mapped to the ISO 639-2/B codes used in container tags
+var languageAliases = map[string]string{
+	"en": "eng", "english": "eng",
+	"ja": "jpn", "jp": "jpn", "japanese": "jpn",
+	"fr": "fre", "fra": "fre", "french": "fre",
+	"de": "ger", "deu": "ger", "german": "ger",
+	"es": "spa", "spanish": "spa",
+	"it": "ita", "italian": "ita",
+	"pt": "por", "portuguese": "por",
+	"nl": "dut", "nld": "dut", "dutch": "dut",
+	"zh": "chi", "zho": "chi", "chinese": "chi",
+	"ko": "kor", "korean": "kor",
+	"ru": "rus", "russian": "rus",
+	"sv": "swe", "swedish": "swe",
+	"no": "nor", "norwegian": "nor",
+	"da": "dan", "danish": "dan",
+	"fi": "fin", "finnish": "fin",
+	"pl": "pol", "polish": "pol",
+	"ar": "ara", "arabic": "ara",
+	"hi": "hin", "hindi": "hin",
+	"undetermined": "und", "unknown": "und",
+}
+
+// every ISO 639-2/B code, a sidecar name is only taken as a language if it is one of these
+var knownLanguages = map[string]bool{}
+
+func init() {
+	for _, code := range iso6392 {
+		knownLanguages[code] = true
+	}
+	for t, b := range iso6392T {
+		if _, ok := languageAliases[t]; !ok {
+			languageAliases[t] = b
+		}
+	}
+}
+
+// flags that follow the language in a sidecar name, hi is also Hindi so it is only a flag after a language
+var sidecarFlags = map[string]bool{
+	"forced":  true,
+	"sdh":     true,
+	"cc":      true,
+	"default": true,
+	"full":    true,
+}
+
+// subtitle formats that are picked up as sidecar files next to a video
+var subtitleExtensions = map[string]bool{
+	".srt": true,
+	".ass": true,
+	".ssa": true,
+	".vtt": true,
+}
+
+// NormalizeLanguage returns the ISO 639-2/B code for a language code or name, "" if it is not recognised
+func NormalizeLanguage(l string) string {
+	l = strings.ToLower(strings.TrimSpace(l))
+	if code, ok := languageAliases[l]; ok {
+		return code
+	}
+	if len(l) == 3 && strings.Trim(l, "abcdefghijklmnopqrstuvwxyz") == "" {
+		return l
+	}
+	return ""
+}
+
+// NewLanguageRequirements normalises the required languages and errors on any that are not recognised
+func NewLanguageRequirements(audio, subtitles []string) (LanguageRequirements, error) {
+	var r LanguageRequirements
+
+	for _, l := range audio {
+		code := NormalizeLanguage(l)
+		if code == "" {
+			return r, fmt.Errorf("unknown audio language %q (expected an ISO 639 code ie jpn)", l)
+		}
+		r.Audio = append(r.Audio, code)
+	}
+	for _, l := range subtitles {
+		code := NormalizeLanguage(l)
+		if code == "" {
+			return r, fmt.Errorf("unknown subtitle language %q (expected an ISO 639 code ie eng)", l)
+		}
+		r.Subtitles = append(r.Subtitles, code)
+	}
+
+	return r, nil
+}
+
+// IsEmpty returns true if no languages are required
+func (r LanguageRequirements) IsEmpty() bool {
+	return len(r.Audio) == 0 && len(r.Subtitles) == 0
+}
+
+func (r LanguageRequirements) String() string {
+	var parts []string
+	if len(r.Audio) > 0 {
+		parts = append(parts, "audio "+strings.Join(r.Audio, ", "))
+	}
+	if len(r.Subtitles) > 0 {
+		parts = append(parts, "subs "+strings.Join(r.Subtitles, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// LanguageCheck is the result of checking a video against the language requirements
+type LanguageCheck struct {
+	MissingAudio     []string
+	MissingSubtitles []string
+	ProbeFailed      bool // the embedded tracks are unknown so only sidecar subtitles were checked
+}
+
+// Passed returns true if every required language was found
+func (lc LanguageCheck) Passed() bool {
+	return len(lc.MissingAudio) == 0 && len(lc.MissingSubtitles) == 0
+}
+
+func (lc LanguageCheck) String() string {
+	if lc.Passed() {
+		return "pass"
+	}
+
+	var parts []string
+	if len(lc.MissingAudio) > 0 {
+		parts = append(parts, "no "+strings.Join(lc.MissingAudio, ", ")+" audio")
+	}
+	if len(lc.MissingSubtitles) > 0 {
+		parts = append(parts, "no "+strings.Join(lc.MissingSubtitles, ", ")+" subs")
+	}
+
+	s := strings.Join(parts, ", ")
+	if lc.ProbeFailed {
+		s += " (probe failed)"
+	}
+	return s
+}
+
+// Check returns which of the required languages a video is missing
+func (r LanguageRequirements) Check(v VideoFile) LanguageCheck {
+	lc := LanguageCheck{ProbeFailed: v.FFProbeFailed}
+	lc.MissingAudio = missingLanguages(r.Audio, v.AudioLanguages())
+	lc.MissingSubtitles = missingLanguages(r.Subtitles, v.SubtitleLanguages())
+	return lc
+}
+
+func missingLanguages(required, have []string) []string {
+	var missing []string
+	for _, l := range required {
+		found := false
+		for _, h := range have {
+			if NormalizeLanguage(h) == l {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, l)
+		}
+	}
+	return missing
+}
+
+// IsSubtitleFile returns true if the file is a text subtitle format
+func IsSubtitleFile(path string) bool {
+	return subtitleExtensions[strings.ToLower(filepath.Ext(path))]
+}
+
+// SidecarLanguage returns the language of a subtitle file from its name ie "Show - 01x01 - Title.en.forced.srt" is
+// eng, "und" if there is none
+func SidecarLanguage(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if code := sidecarLanguage(strings.Split(name, ".")); code != "" {
+		return code
+	}
+	return "und"
+}
+
+// sidecarLanguage returns the language from the dot separated parts of a name after the first, "" if there is none
+func sidecarLanguage(parts []string) string {
+	// skip any flags after the language
+	for i := len(parts) - 1; i > 0; i-- {
+		part := strings.ToLower(parts[i])
+		if sidecarFlags[part] {
+			continue
+		}
+
+		// hearing impaired when it follows a language ie .en.hi.srt, otherwise Hindi
+		if part == "hi" {
+			if code := sidecarLanguage(parts[:i]); code != "" {
+				return code
+			}
+		}
+
+		if code := NormalizeLanguage(part); knownLanguages[code] {
+			return code
+		}
+		break
+	}
+
+	return ""
+}
+
+// sidecarsFor returns the subtitle files that belong to a video, those named after it or all of them if it is the only
+// video in the folder
+func sidecarsFor(video string, files []string, onlyVideo bool) []string {
+	base := strings.TrimSuffix(filepath.Base(video), filepath.Ext(video))
+
+	var sidecars []string
+	for _, f := range files {
+		if !IsSubtitleFile(f) {
+			continue
+		}
+		if onlyVideo || strings.HasPrefix(filepath.Base(f), base+".") {
+			sidecars = append(sidecars, f)
+		}
+	}
+	return sidecars
+}
+
+// SubtitleLanguages returns the sorted unique languages of the embedded and sidecar subtitles
+func (v *VideoFile) SubtitleLanguages() []string {
+	seen := map[string]bool{}
+	var languages []string
+	add := func(l string) {
+		if l == "" {
+			l = "und"
+		}
+		if !seen[l] {
+			seen[l] = true
+			languages = append(languages, l)
+		}
+	}
+
+	for _, s := range v.Subtitles {
+		add(s.Language)
+	}
+	for _, f := range v.SidecarSubtitles {
+		add(SidecarLanguage(f))
+	}
+	sort.Strings(languages)
+
+	return languages
+}
@@ -0,0 +1,102 @@
+package content
+
+import (
+	"testing"
+
+	"github.com/katbyte/go-ingest-media/lib/content/contenttest"
+)
+
+func TestSidecarLanguage(t *testing.T) {
+	cases := []struct {
+		file string
+		want string
+	}{
+		{"Show - 01x01 - Pilot.en.srt", "eng"},
+		{"Show - 01x01 - Pilot.eng.forced.srt", "eng"},
+		{"Show - 01x01 - Pilot.Japanese.ass", "jpn"},
+		{"Show - 01x01 - Pilot.fr.sdh.srt", "fre"},
+		{"Show - 01x01 - Pilot.en.hi.srt", "eng"},
+		{"Show - 01x01 - Pilot.eng.HI.forced.srt", "eng"},
+		{"Movie (2000).hi.srt", "hin"},
+		{"Movie (2000).hi.forced.srt", "hin"},
+		{"Movie (2000).tgl.srt", "tgl"},
+		{"Movie (2000).ces.srt", "cze"},
+		{"Movie (2000).heb.sdh.srt", "heb"},
+		{"Movie (2000).xyz.srt", "und"},
+		{"Show - 01x01 - Pilot.srt", "und"},
+		{"Dr. Who - 01x01 - Rose.srt", "und"},
+		{"Movie (2000).720.srt", "und"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.file, func(t *testing.T) {
+			if got := SidecarLanguage(tc.file); got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNewLanguageRequirements(t *testing.T) {
+	r, err := NewLanguageRequirements([]string{"ja", "English"}, []string{"eng"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := r.String(); got != "audio jpn, eng; subs eng" {
+		t.Errorf("got %q", got)
+	}
+
+	if _, err := NewLanguageRequirements([]string{"klingon"}, nil); err == nil {
+		t.Errorf("expected an error for an unknown language")
+	}
+}
+
+func TestLanguageRequirementsCheck(t *testing.T) {
+	anime := LanguageRequirements{Audio: []string{"jpn"}, Subtitles: []string{"eng"}}
+	tv := LanguageRequirements{Audio: []string{"eng"}}
+
+	fv := contenttest.New(t, t.TempDir())
+	fv.Add("Show - s01/Show - 01x01 - Pilot.mkv", "hevc-1080p.mkv", 100)
+	fv.Add("Show - s01/Show - 01x02 - Second.mkv", "h264-1080p.mkv", 100)
+	fv.Add("Show - s01/Show - 01x03 - Third.avi", "h264-480p.avi", 100)
+	fv.Add("Show - s01/Show - 01x03 - Third.en.srt", "", 10)
+	fv.Add("Show - s01/Show - 01x04 - Fourth.mkv", "", 100)
+	fv.Add("Movie (2000)/Movie (2000).avi", "h264-480p.avi", 100)
+	fv.Add("Movie (2000)/Movie (2000).ja.ass", "", 10)
+
+	seasons, err := GetSeasons(fixtureContext(), fv.Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	episode := func(n int) VideoFile {
+		return seasons[1].Episodes[n].Videos[0]
+	}
+
+	movies, err := VideosInPath(fixtureContext(), fv.Root+"/Movie (2000)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name  string
+		req   LanguageRequirements
+		video VideoFile
+		want  string
+	}{
+		{"embedded audio and subs", anime, episode(1), "pass"},
+		{"missing japanese audio", anime, episode(2), "no jpn audio"},
+		{"sidecar subs count", anime, episode(3), "no jpn audio"},
+		{"probe failed", anime, episode(4), "no jpn audio, no eng subs (probe failed)"},
+		{"english audio", tv, episode(2), "pass"},
+		{"untagged audio", tv, episode(3), "no eng audio"},
+		{"movie sidecar", LanguageRequirements{Subtitles: []string{"jpn"}}, movies[0], "pass"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.req.Check(tc.video).String(); got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -1,36 +1,153 @@
 package content
 
 import (
+	"fmt"
 	"strings"
 	"unicode"
 	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
 )
 
-var prefixes = [...]string{"The ", "A ", "An "}
+// Lettering decides which letter folder a title is sorted into
+type Lettering struct {
+	Articles []string // leading words that are skipped, ie The, Der, Le or L', nil for the default and empty for none
+	Digits   string   // folder for titles starting with a digit
+	Symbols  string   // folder for titles starting with anything that isn't a letter or digit
+}
+
+// DefaultLettering skips English articles, puts digits in 0 and everything else in @
+var DefaultLettering = Lettering{
+	Articles: []string{"The", "A", "An"},
+	Digits:   "0",
+	Symbols:  "@",
+}
+
+// letters that don't decompose into a base letter and a diacritic
+var letterTransliterations = map[rune]rune{
+	'ß': 's', 'ø': 'o', 'æ': 'a', 'œ': 'o', 'þ': 't', 'ð': 'd', 'đ': 'd', 'ł': 'l', 'ı': 'i', 'ŋ': 'n', 'ħ': 'h',
+}
+
+// NewLettering returns the lettering with any unset values taken from the default, and errors on bucket folder names
+// that can't be a single folder. nil articles are the default, an empty list skips none
+func NewLettering(articles []string, digits, symbols string) (Lettering, error) {
+	l := DefaultLettering
+	if articles != nil {
+		l.Articles = []string{}
+		for _, a := range articles {
+			if a = strings.TrimSpace(a); a != "" {
+				l.Articles = append(l.Articles, a)
+			}
+		}
+	}
+	if digits != "" {
+		l.Digits = digits
+	}
+	if symbols != "" {
+		l.Symbols = symbols
+	}
+
+	for _, b := range []string{l.Digits, l.Symbols} {
+		if strings.ContainsAny(b, `/\`) || b == "." || b == ".." {
+			return l, fmt.Errorf("invalid letter folder %q", b)
+		}
+	}
+
+	return l, nil
+}
+
+// LetterFor returns the letter folder for a title in this library
+func (l *Library) LetterFor(folder string) string {
+	return l.lettering().LetterFor(folder)
+}
+
+// LetterBuckets returns the library's letter folders that aren't a letter, ie 0 and @
+func (l *Library) LetterBuckets() []string {
+	return l.lettering().Buckets()
+}
+
+// lettering returns the library's lettering with the default for anything it doesn't set, empty (not nil) articles
+// are kept as no articles
+func (l *Library) lettering() Lettering {
+	if l == nil {
+		return DefaultLettering
+	}
+
+	lt := l.Lettering
+	if lt.Articles == nil {
+		lt.Articles = DefaultLettering.Articles
+	}
+	if lt.Digits == "" {
+		lt.Digits = DefaultLettering.Digits
+	}
+	if lt.Symbols == "" {
+		lt.Symbols = DefaultLettering.Symbols
+	}
+	return lt
+}
 
+// GetLetter returns the letter folder for a title with the default lettering
 func GetLetter(folder string) string {
-	s := folder
+	return DefaultLettering.LetterFor(folder)
+}
+
+// LetterFor returns the letter folder for a title: the first letter after any article with diacritics removed (É is
+// e, ß is s), or the digits or symbols folder
+func (l Lettering) LetterFor(folder string) string {
+	s := strings.TrimSpace(folder)
 
-	for _, prefix := range prefixes {
-		if strings.HasPrefix(strings.ToLower(s), strings.ToLower(prefix)) {
-			s = s[len(prefix):]
+	for _, article := range l.Articles {
+		if rest, ok := cutArticle(s, article); ok {
+			s = rest
+			break
 		}
 	}
 
-	// get first rune
 	r, _ := utf8.DecodeRuneInString(s)
 	if r == utf8.RuneError {
-		return "@"
+		return l.Symbols
 	}
-
-	if r >= '0' && r <= '9' {
-		return "0"
+	if unicode.IsDigit(r) {
+		return l.Digits
 	}
 
-	lower := unicode.ToLower(r)
-	if lower >= 'a' && lower <= 'z' {
+	if lower := foldLetter(r); lower >= 'a' && lower <= 'z' {
 		return string(lower)
 	}
+	return l.Symbols
+}
+
+// Buckets returns the folders that aren't a letter
+func (l Lettering) Buckets() []string {
+	return []string{l.Digits, l.Symbols}
+}
+
+// cutArticle removes a leading article (case insensitive) followed by a space, or directly followed by the title if
+// it ends in an apostrophe ie L'Atalante. A title that is only the article is left alone
+func cutArticle(s, article string) (string, bool) {
+	if len(s) <= len(article) || !strings.EqualFold(s[:len(article)], article) {
+		return s, false
+	}
+
+	rest := s[len(article):]
+	if !strings.HasSuffix(article, "'") {
+		if rest[0] != ' ' {
+			return s, false
+		}
+		rest = strings.TrimSpace(rest)
+	}
+
+	return rest, rest != ""
+}
+
+// foldLetter returns the lower case base letter of a rune, ie é is e and Ø is o
+func foldLetter(r rune) rune {
+	r = unicode.ToLower(r)
+	if t, ok := letterTransliterations[r]; ok {
+		return t
+	}
 
-	return "@"
+	// the first rune of the decomposed form is the letter without its diacritics
+	base, _ := utf8.DecodeRuneInString(norm.NFD.String(string(r)))
+	return base
 }
@@ -0,0 +1,90 @@
+package content
+
+import (
+	"testing"
+)
+
+func TestGetLetter(t *testing.T) {
+	for folder, want := range map[string]string{
+		"Avatar (2009)":        "a",
+		"The Matrix (1999)":    "m",
+		"An American Werewolf": "a",
+		"A Quiet Place (2018)": "q",
+		"Theodore Rex (1995)":  "t",
+		"The (2020)":           "@",
+		"1917 (2019)":          "0",
+		"Élite (2018)":         "e",
+		"Ôkami (2006)":         "o",
+		"Ørnen (2004)":         "o",
+		"ßtest":                "s",
+		"Æon Flux (2005)":      "a",
+		"Łódź Story":           "l",
+		"Der Untergang (2004)": "d",
+		"'Allo 'Allo! (1982)":  "@",
+		"千と千尋の神隠し (2001)":      "@",
+		"":                     "@",
+	} {
+		if got := GetLetter(folder); got != want {
+			t.Errorf("%q: got %q, want %q", folder, got, want)
+		}
+	}
+}
+
+func TestLibraryLetterFor(t *testing.T) {
+	lettering, err := NewLettering([]string{"Der", "Die", "Das", "Le", "La", "Les", "L'", "El"}, "0-9", "#")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lib := &Library{Lettering: lettering}
+
+	for folder, want := range map[string]string{
+		"Der Untergang (2004)":    "u",
+		"Das Boot (1981)":         "b",
+		"Les Misérables (2012)":   "m",
+		"L'Atalante (1934)":       "a",
+		"El Laberinto del Fauno":  "l",
+		"Lesbian Vampire Killers": "l",
+		"The Matrix (1999)":       "t", // only the configured articles are skipped
+		"1917 (2019)":             "0-9",
+		"'Allo 'Allo! (1982)":     "#",
+		"Élite (2018)":            "e",
+	} {
+		if got := lib.LetterFor(folder); got != want {
+			t.Errorf("%q: got %q, want %q", folder, got, want)
+		}
+	}
+
+	// a library without lettering uses the default
+	if got := (&Library{}).LetterFor("The Matrix (1999)"); got != "m" {
+		t.Errorf("default: got %q, want m", got)
+	}
+	if got := (&Library{Lettering: Lettering{Symbols: "#"}}).LetterFor("1917 (2019)"); got != "0" {
+		t.Errorf("default digits: got %q, want 0", got)
+	}
+}
+
+func TestNewLettering(t *testing.T) {
+	if _, err := NewLettering(nil, "0/9", ""); err == nil {
+		t.Error("expected an error for a digits folder with a separator")
+	}
+	if _, err := NewLettering(nil, "", ".."); err == nil {
+		t.Error("expected an error for a .. symbols folder")
+	}
+
+	l, err := NewLettering(nil, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(l.Articles) != 3 || l.Digits != "0" || l.Symbols != "@" {
+		t.Errorf("got %+v, want the default", l)
+	}
+
+	// an empty list is no articles rather than the default
+	none, err := NewLettering([]string{}, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := (&Library{Lettering: none}).LetterFor("The Matrix (1999)"); got != "t" {
+		t.Errorf("no articles: got %q, want t", got)
+	}
+}
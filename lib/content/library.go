@@ -3,6 +3,7 @@ package content
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/katbyte/go-ingest-media/lib/ktio"
 )
@@ -18,9 +19,13 @@ const (
 
 // Library represents a single library location (either source or destination)
 type Library struct {
+	Name          string // name used in the config file
 	Path          string // full absolute path
 	Type          LibraryType
 	LetterFolders bool
+	Policy        Policy               // rules to automatically resolve import conflicts in this library
+	Languages     LanguageRequirements // audio and subtitle languages every video should have
+	Lettering     Lettering            // how titles are sorted into letter folders, unset values use the default
 }
 
 // LibraryMapping joins a source library to a destination library for processing
@@ -34,36 +39,49 @@ func (m LibraryMapping) FolderRenames() []FolderMapping {
 	return folderRenames[m.Source.Type]
 }
 
-// Libraries - all known library locations (using pointers for direct access)
-var Libraries = map[string]*Library{
-	// Torrent (sorted) libraries - source (/mnt/ztmp/torrents/sorted/...)
-	"torrent-anime-movies": {Path: "/mnt/ztmp/torrents/sorted/m.anime", Type: LibraryTypeMovies},
-	"torrent-movies":       {Path: "/mnt/ztmp/torrents/sorted/m.movies", Type: LibraryTypeMovies},
-	"torrent-documentary":  {Path: "/mnt/ztmp/torrents/sorted/m.docu", Type: LibraryTypeMovies},
-	"torrent-standup":      {Path: "/mnt/ztmp/torrents/sorted/m.standup", Type: LibraryTypeStandup},
-	"torrent-anime-series": {Path: "/mnt/ztmp/torrents/sorted/s.anime", Type: LibraryTypeSeries},
-	"torrent-tv":           {Path: "/mnt/ztmp/torrents/sorted/s.tv", Type: LibraryTypeSeries},
-	"torrent-docuseries":   {Path: "/mnt/ztmp/torrents/sorted/s.docu", Type: LibraryTypeSeries},
-
-	// Video libraries - destination (/mnt/video/...)
-	"video-anime-movies": {Path: "/mnt/video/anime/movies", Type: LibraryTypeMovies},
-	"video-movies":       {Path: "/mnt/video/movies", Type: LibraryTypeMovies, LetterFolders: true},
-	"video-documentary":  {Path: "/mnt/video/docu/documentary", Type: LibraryTypeMovies},
-	"video-standup":      {Path: "/mnt/video/standup", Type: LibraryTypeStandup},
-	"video-anime-series": {Path: "/mnt/video/anime/series", Type: LibraryTypeSeries, LetterFolders: true},
-	"video-tv":           {Path: "/mnt/video/tv", Type: LibraryTypeSeries, LetterFolders: true},
-	"video-docuseries":   {Path: "/mnt/video/docu/docuseries", Type: LibraryTypeSeries},
+// Libraries - all known library locations keyed by name, populated from the config file
+var Libraries = map[string]*Library{}
+
+// ImportMappings - mappings from source to destination libraries used by the import command
+var ImportMappings = map[string]LibraryMapping{}
+
+// LibraryFor returns the named library or an error if it has not been configured
+func LibraryFor(name string) (*Library, error) {
+	l, ok := Libraries[name]
+	if !ok || l == nil {
+		return nil, fmt.Errorf("library %q is not defined in the config", name)
+	}
+
+	return l, nil
 }
 
-// LibraryMappingSortedTorrentsImport - mappings from source to destination for importing sorted torrents
-var LibraryMappingSortedTorrentsImport = map[string]LibraryMapping{
-	"anime-movies": {Source: Libraries["torrent-anime-movies"], Dest: Libraries["video-anime-movies"]},
-	"movies":       {Source: Libraries["torrent-movies"], Dest: Libraries["video-movies"]},
-	"documentary":  {Source: Libraries["torrent-documentary"], Dest: Libraries["video-documentary"]},
-	"standup":      {Source: Libraries["torrent-standup"], Dest: Libraries["video-standup"]},
-	"anime-series": {Source: Libraries["torrent-anime-series"], Dest: Libraries["video-anime-series"]},
-	"tv":           {Source: Libraries["torrent-tv"], Dest: Libraries["video-tv"]},
-	"docuseries":   {Source: Libraries["torrent-docuseries"], Dest: Libraries["video-docuseries"]},
+// LibraryTypeFor parses a library type name as used in the config file
+func LibraryTypeFor(name string) (LibraryType, error) {
+	switch strings.ToLower(name) {
+	case "movies", "movie":
+		return LibraryTypeMovies, nil
+	case "series", "tv":
+		return LibraryTypeSeries, nil
+	case "standup":
+		return LibraryTypeStandup, nil
+	}
+
+	return LibraryTypeUnknown, fmt.Errorf("unknown library type %q (expected movies, series or standup)", name)
+}
+
+func (t LibraryType) String() string {
+	switch t {
+	case LibraryTypeMovies:
+		return "movies"
+	case LibraryTypeSeries:
+		return "series"
+	case LibraryTypeStandup:
+		return "standup"
+	case LibraryTypeUnknown:
+		fallthrough
+	default:
+		return "unknown"
+	}
 }
 
 // Contents scans this library and returns all content items
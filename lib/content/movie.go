@@ -1,6 +1,7 @@
 package content
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 
@@ -30,9 +31,9 @@ func MovieFor(lib *Library, folder string) (*Movie, error) {
 }
 
 // LoadVideos loads video info for this movie
-func (m *Movie) LoadVideos() error {
+func (m *Movie) LoadVideos(ctx context.Context) error {
 	var err error
-	m.Videos, err = VideosInPath(m.Path())
+	m.Videos, err = VideosInPath(ctx, m.Path())
 	if err != nil {
 		return fmt.Errorf("error loading videos: %w", err)
 	}
@@ -43,7 +44,7 @@ func (m *Movie) LoadVideos() error {
 func (m *Movie) MoveFilesTo(destPath string, prompt bool, indent int) error {
 	// move video files
 	for _, v := range m.Videos {
-		if err := ktio.RunCommand(indent, prompt, "mv", "-v", v.Path, destPath+"/"); err != nil {
+		if err := ktio.Move(indent, prompt, v.Path, destPath+"/"); err != nil {
 			return fmt.Errorf("error moving video: %w", err)
 		}
 	}
@@ -66,7 +67,7 @@ func (m *Movie) MoveFilesTo(destPath string, prompt bool, indent int) error {
 		}
 
 		// move file or folder
-		if err := ktio.RunCommand(indent, prompt, "mv", "-v", contentPath, destPath+"/"); err != nil {
+		if err := ktio.Move(indent, prompt, contentPath, destPath+"/"); err != nil {
 			return fmt.Errorf("error moving file or folder: %w", err)
 		}
 	}
@@ -82,7 +83,7 @@ func (m *Movie) MoveFilesTo(destPath string, prompt bool, indent int) error {
 // DeleteVideos deletes all video files for this movie
 func (m *Movie) DeleteVideos(prompt bool, indent int) {
 	for _, v := range m.Videos {
-		if err := ktio.RunCommand(indent, prompt, "rm", "-v", v.Path); err != nil {
+		if err := ktio.Remove(indent, prompt, v.Path); err != nil {
 			c.Printf("   <red>ERROR:</> deleting video: %s\n", err)
 		}
 	}
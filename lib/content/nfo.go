@@ -3,8 +3,8 @@ package content
 import (
 	"encoding/xml"
 	"fmt"
-	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/katbyte/go-ingest-media/lib/ktio"
@@ -12,6 +12,7 @@ import (
 
 // NfoFile represents the relevant fields from an Emby/Jellyfin NFO XML file
 type NfoFile struct {
+	XMLName xml.Name // movie, tvshow or episodedetails
 	Title   string   `xml:"title"`
 	Year    string   `xml:"year"`
 	Genres  []string `xml:"genre"`
@@ -19,11 +20,13 @@ type NfoFile struct {
 	Outline string   `xml:"outline"`
 	Tagline string   `xml:"tagline"`
 	TmdbId  string   `xml:"tmdbid"`
+	Runtime string   `xml:"runtime"` // minutes
+	Edition string   `xml:"edition"`
 }
 
 // ReadNfo reads and parses an NFO XML file
 func ReadNfo(filePath string) (*NfoFile, error) {
-	data, err := os.ReadFile(filePath)
+	data, err := ktio.ReadFile(ktio.RealPath(filePath))
 	if err != nil {
 		return nil, fmt.Errorf("error reading nfo file: %w", err)
 	}
@@ -41,7 +44,7 @@ func ReadNfo(filePath string) (*NfoFile, error) {
 func FindNfoFile(dirPath string) (string, error) {
 	// Fast path: try expected filename (folder_name.nfo) first - avoids ReadDir over NFS
 	expected := filepath.Join(dirPath, filepath.Base(dirPath)+".nfo")
-	if _, err := os.Stat(expected); err == nil {
+	if ktio.PathExists(expected) {
 		return expected, nil
 	}
 
@@ -60,6 +63,16 @@ func FindNfoFile(dirPath string) (string, error) {
 	return "", nil // no nfo file found (not an error)
 }
 
+// RuntimeSeconds returns the runtime in seconds, 0 if it is missing or not a number of minutes (ie "120" or "120 min")
+func (n *NfoFile) RuntimeSeconds() float64 {
+	minutes, _, _ := strings.Cut(strings.TrimSpace(n.Runtime), " ")
+	m, err := strconv.ParseFloat(minutes, 64)
+	if err != nil || m <= 0 {
+		return 0
+	}
+	return m * 60
+}
+
 // IsDocumentary returns true if any genre contains "documentary" (case-insensitive)
 func (n *NfoFile) IsDocumentary() bool {
 	for _, genre := range n.Genres {
@@ -85,7 +98,7 @@ func (n *NfoFile) TmdbURL(isSeries bool) string {
 // RemoveDocumentaryGenre removes documentary genre tags from an NFO file on disk
 // by filtering out matching <genre> lines, preserving all other content
 func RemoveDocumentaryGenre(filePath string) error {
-	data, err := os.ReadFile(filePath)
+	data, err := ktio.ReadFile(ktio.RealPath(filePath))
 	if err != nil {
 		return fmt.Errorf("error reading nfo file: %w", err)
 	}
@@ -102,5 +115,5 @@ func RemoveDocumentaryGenre(filePath string) error {
 		filtered = append(filtered, line)
 	}
 
-	return os.WriteFile(filePath, []byte(strings.Join(filtered, "\n")), 0o644)
+	return ktio.WriteFile(filePath, []byte(strings.Join(filtered, "\n")), 0o644)
 }
@@ -0,0 +1,200 @@
+package content
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// NormalizeRename is a season folder or episode file that doesn't follow the library convention and its new path
+type NormalizeRename struct {
+	From string
+	To   string
+
+	Conflict     bool   // something else is already at (or is being renamed to) To so it is left alone
+	Unrenameable string // why From can't be given a library name, it is left alone and To is the same as From
+}
+
+var (
+	libraryEpisodeTitleRegex = regexp.MustCompile(`^.* - \d+x\d+(?:[-+]\d+)? - (.+)$`)
+	dailyEpisodeTitleRegex   = regexp.MustCompile(`^.*?\b(?:19|20)\d{2}[-. ]\d{2}[-. ]\d{2}\b(.*)$`)
+)
+
+// Name returns the series folder name without its year, ie Show for Show (2010)
+func (s Series) Name() string {
+	return strings.Join(strings.Fields(yearRegEx.ReplaceAllString(s.Folder, "")), " ")
+}
+
+// SeasonFolderName returns the library's name for a season folder, ie Show - s01 (2019), season 0 is specials
+func SeasonFolderName(series string, season Season) string {
+	if season.Number == 0 {
+		return "specials"
+	}
+
+	name := fmt.Sprintf("%s - s%02d", series, season.Number)
+	if season.Year > 0 {
+		name += fmt.Sprintf(" (%d)", season.Year)
+	}
+	return name
+}
+
+// EpisodeFileStem returns the library's name for an episode file without its extension, ie Show - 01x02 - Title,
+// Show - 01x01-03 - Title for a range and Show - 01x06+08 - Title for two episodes that are not consecutive. Daily
+// episodes keep their date ie Show - 2024-03-15 - Title. More than two episodes that are not a range can't be named,
+// check with EpisodesNameable first
+func EpisodeFileStem(series string, e *Episode, title string) string {
+	if title == "" {
+		title = fmt.Sprintf("Episode %d", e.Number)
+	}
+	if e.Aired != "" {
+		return fmt.Sprintf("%s - %s - %s", series, e.Aired, title)
+	}
+
+	numbers := fmt.Sprintf("%02dx%02d", e.Season, e.Number)
+	if n := len(e.EpisodeNumbers); n > 1 {
+		first, last := e.EpisodeNumbers[0], e.EpisodeNumbers[n-1]
+		if last-first == n-1 {
+			numbers += fmt.Sprintf("-%02d", last)
+		} else {
+			numbers += fmt.Sprintf("+%02d", last)
+		}
+	}
+	return fmt.Sprintf("%s - %s - %s", series, numbers, title)
+}
+
+// EpisodesNameable returns true if the episodes in a file can be written in a library name, a single episode, a range
+// or a pair
+func EpisodesNameable(episodes []int) bool {
+	n := len(episodes)
+	return n <= 2 || episodes[n-1]-episodes[0] == n-1
+}
+
+// Title returns the episode's title from its file names, or the title in its episodedetails nfo, "" if neither has one
+func (e *Episode) Title() string {
+	paths := make([]string, 0, len(e.Videos)+len(e.OtherFiles))
+	for _, v := range e.Videos {
+		paths = append(paths, v.Path)
+	}
+	paths = append(paths, e.OtherFiles...)
+
+	for _, p := range paths {
+		if IsSubtitleFile(p) || strings.ToLower(filepath.Ext(p)) == ".nfo" {
+			continue // their names have languages and flags after the title
+		}
+		if title := episodeTitleFromName(strings.TrimSuffix(filepath.Base(p), filepath.Ext(p))); title != "" {
+			return title
+		}
+	}
+
+	if nfo := e.nfo(); nfo != nil && nfo.XMLName.Local == "episodedetails" {
+		return strings.TrimSpace(nfo.Title)
+	}
+	return ""
+}
+
+// episodeTitleFromName returns the title after the episode numbers or date in a file name, "" if there isn't one
+func episodeTitleFromName(stem string) string {
+	if m := libraryEpisodeTitleRegex.FindStringSubmatch(stem); m != nil {
+		return strings.TrimSpace(m[1])
+	}
+
+	r := ParseRelease(stem)
+	if r.IsEpisode() {
+		return r.EpisodeTitle
+	}
+
+	if m := dailyEpisodeTitleRegex.FindStringSubmatch(stem); m != nil {
+		return titleBeforeTags(m[1], r.dotted)
+	}
+	return ""
+}
+
+// NormalizeRenames returns the renames that bring the series' loaded seasons and episodes to the library convention.
+// Files are renamed within their season folder and come before the season folder's own rename. Subtitles, nfo and
+// other files named after a video are renamed with it, keeping what follows its name ie .en.srt
+func (s *Series) NormalizeRenames(exists func(string) bool) []NormalizeRename {
+	name := s.Name()
+
+	seasonNums := make([]int, 0, len(s.Seasons))
+	for n := range s.Seasons {
+		seasonNums = append(seasonNums, n)
+	}
+	sort.Ints(seasonNums)
+
+	var renames []NormalizeRename
+	planned := map[string]bool{}
+	add := func(from, to string) {
+		if from == to {
+			return
+		}
+
+		r := NormalizeRename{From: from, To: to}
+		// a case only rename is the same file on case insensitive file systems
+		if planned[to] || (exists(to) && !strings.EqualFold(from, to)) {
+			r.Conflict = true
+		}
+		planned[to] = true
+		renames = append(renames, r)
+	}
+
+	for _, sn := range seasonNums {
+		season := s.Seasons[sn]
+
+		epNums := make([]int, 0, len(season.Episodes))
+		for n := range season.Episodes {
+			epNums = append(epNums, n)
+		}
+		sort.Ints(epNums)
+
+		seen := map[*Episode]bool{}
+		for _, en := range epNums {
+			ep := season.Episodes[en]
+			if seen[ep] {
+				continue
+			}
+			seen[ep] = true
+
+			if ep.Aired == "" && !EpisodesNameable(ep.EpisodeNumbers) {
+				for _, v := range ep.Videos {
+					renames = append(renames, NormalizeRename{
+						From:         v.Path,
+						To:           v.Path,
+						Unrenameable: fmt.Sprintf("episodes %s are not a range or a pair", joinInts(ep.EpisodeNumbers)),
+					})
+				}
+				continue
+			}
+
+			stem := EpisodeFileStem(name, ep, ep.Title())
+			renamed := map[string]bool{}
+			for _, v := range ep.Videos {
+				oldStem := strings.TrimSuffix(filepath.Base(v.Path), filepath.Ext(v.Path))
+				add(v.Path, filepath.Join(season.Path, stem+filepath.Ext(v.Path)))
+				renamed[v.Path] = true
+
+				for _, f := range ep.OtherFiles {
+					if rest, ok := strings.CutPrefix(filepath.Base(f), oldStem+"."); ok && !renamed[f] {
+						add(f, filepath.Join(season.Path, stem+"."+rest))
+						renamed[f] = true
+					}
+				}
+			}
+		}
+
+		add(season.Path, filepath.Join(s.Path(), SeasonFolderName(name, season)))
+	}
+
+	return renames
+}
+
+// joinInts returns the numbers separated by commas ie 1, 3, 5
+func joinInts(numbers []int) string {
+	s := make([]string, 0, len(numbers))
+	for _, n := range numbers {
+		s = append(s, strconv.Itoa(n))
+	}
+	return strings.Join(s, ", ")
+}
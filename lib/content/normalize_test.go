@@ -0,0 +1,127 @@
+package content
+
+import (
+	"testing"
+
+	"github.com/katbyte/go-ingest-media/lib/content/contenttest"
+)
+
+func TestSeasonFolderName(t *testing.T) {
+	cases := []struct {
+		season Season
+		want   string
+	}{
+		{Season{Number: 1}, "Show - s01"},
+		{Season{Number: 2, Year: 2021}, "Show - s02 (2021)"},
+		{Season{Number: 0}, "specials"},
+	}
+
+	for _, tc := range cases {
+		if got := SeasonFolderName("Show", tc.season); got != tc.want {
+			t.Errorf("season %d: got %q, want %q", tc.season.Number, got, tc.want)
+		}
+	}
+}
+
+func TestEpisodeFileStem(t *testing.T) {
+	cases := []struct {
+		episode Episode
+		title   string
+		want    string
+	}{
+		{Episode{Season: 1, Number: 2, EpisodeNumbers: []int{2}}, "Second", "Show - 01x02 - Second"},
+		{Episode{Season: 1, Number: 3, EpisodeNumbers: []int{3, 4, 5}}, "Triple", "Show - 01x03-05 - Triple"},
+		{Episode{Season: 1, Number: 6, EpisodeNumbers: []int{6, 8}}, "Pair", "Show - 01x06+08 - Pair"},
+		{Episode{Season: 2, Number: 7, EpisodeNumbers: []int{7}}, "", "Show - 02x07 - Episode 7"},
+		{Episode{Season: 2024, Number: 315, EpisodeNumbers: []int{315}, Aired: "2024-03-15"}, "Guest", "Show - 2024-03-15 - Guest"},
+	}
+
+	for _, tc := range cases {
+		if got := EpisodeFileStem("Show", &tc.episode, tc.title); got != tc.want {
+			t.Errorf("got %q, want %q", got, tc.want)
+		}
+	}
+}
+
+func TestEpisodesNameable(t *testing.T) {
+	cases := []struct {
+		episodes []int
+		want     bool
+	}{
+		{[]int{1}, true},
+		{[]int{1, 2, 3}, true},
+		{[]int{1, 3}, true},
+		{[]int{1, 3, 5}, false},
+		{[]int{1, 2, 4}, false},
+	}
+
+	for _, tc := range cases {
+		if got := EpisodesNameable(tc.episodes); got != tc.want {
+			t.Errorf("%v: got %t, want %t", tc.episodes, got, tc.want)
+		}
+	}
+}
+
+func TestEpisodeTitleFromName(t *testing.T) {
+	for stem, want := range map[string]string{
+		"Show - 01x02 - Second":                         "Second",
+		"Show - 01x03-05 - Triple":                      "Triple",
+		"The.Show.S01E01.Pilot.1080p.WEB-DL.H.264-NTb":  "Pilot",
+		"show_1x05_the_title":                           "the title",
+		"Show.S01E02.1080p.WEB-DL-GRP":                  "",
+		"The.Daily.Show.2024.03.15.Guest.Name.720p.WEB": "Guest Name",
+		"The Daily Show 2024-03-16":                     "",
+		"S01E04":                                        "",
+	} {
+		if got := episodeTitleFromName(stem); got != want {
+			t.Errorf("%s: got %q, want %q", stem, got, want)
+		}
+	}
+}
+
+func TestNormalizeRenames(t *testing.T) {
+	fv := contenttest.New(t, t.TempDir())
+
+	fv.Add("Show (2010)/Season 1/Show.S01E01.Pilot.1080p.WEB-DL-GRP.mkv", "hevc-1080p.mkv", 100)
+	fv.Add("Show (2010)/Season 1/Show.S01E01.Pilot.1080p.WEB-DL-GRP.en.srt", "", 10)
+	fv.Add("Show (2010)/Season 1/Show.S01E02E03.1080p.WEB-DL-GRP.mkv", "h264-1080p.mkv", 100)
+	fv.Add("Show (2010)/Season 1/Show.S01E02E03.1080p.WEB-DL-GRP.nfo", "", 10)
+	fv.Add("Show (2010)/Season 1/Show.S01E04E06E08.1080p.WEB-DL-GRP.mkv", "h264-1080p.mkv", 100)
+	fv.Add("Show (2010)/Show - s02/Show - 02x01 - Return.mkv", "hevc-2160p.mkv", 100)
+	fv.Add("Show (2010)/Show - s02/Show - 02x02 - Return.mkv", "hevc-2160p.mkv", 100)
+
+	nfo := []byte(`<episodedetails><title>Double Trouble</title></episodedetails>`)
+	fv.Write("Show (2010)/Season 1/Show.S01E02E03.1080p.WEB-DL-GRP.nfo", nfo)
+
+	s := Series{Content: Content{Library: &Library{Path: fv.Root}, Folder: "Show (2010)"}}
+	if err := s.LoadSeasons(fixtureContext()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the second season is already named for the library, and an empty Show - s01 is in the way of the first
+	exists := func(path string) bool { return path == s.Path()+"/Show - s01" }
+
+	season1 := s.Path() + "/Season 1/"
+	want := []NormalizeRename{
+		{From: season1 + "Show.S01E01.Pilot.1080p.WEB-DL-GRP.mkv", To: season1 + "Show - 01x01 - Pilot.mkv"},
+		{From: season1 + "Show.S01E01.Pilot.1080p.WEB-DL-GRP.en.srt", To: season1 + "Show - 01x01 - Pilot.en.srt"},
+		{From: season1 + "Show.S01E02E03.1080p.WEB-DL-GRP.mkv", To: season1 + "Show - 01x02-03 - Double Trouble.mkv"},
+		{From: season1 + "Show.S01E02E03.1080p.WEB-DL-GRP.nfo", To: season1 + "Show - 01x02-03 - Double Trouble.nfo"},
+		{
+			From:         season1 + "Show.S01E04E06E08.1080p.WEB-DL-GRP.mkv",
+			To:           season1 + "Show.S01E04E06E08.1080p.WEB-DL-GRP.mkv",
+			Unrenameable: "episodes 4, 6, 8 are not a range or a pair",
+		},
+		{From: s.Path() + "/Season 1", To: s.Path() + "/Show - s01", Conflict: true},
+	}
+
+	got := s.NormalizeRenames(exists)
+	if len(got) != len(want) {
+		t.Fatalf("got %d renames %+v, want %d", len(got), got, len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("rename %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
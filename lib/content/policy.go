@@ -0,0 +1,296 @@
+package content
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PolicyDecision is what to do when a source video conflicts with existing destination videos
+type PolicyDecision int
+
+const (
+	PolicyUndecided PolicyDecision = iota // no rule matched, ask
+	PolicyReplace                         // replace the destination videos with the source
+	PolicyKeep                            // keep the destination videos and delete the source
+	PolicySkip                            // leave both alone
+)
+
+var policyDecisionNames = map[string]PolicyDecision{
+	"replace": PolicyReplace,
+	"keep":    PolicyKeep,
+	"skip":    PolicySkip,
+}
+
+func (d PolicyDecision) String() string {
+	for name, pd := range policyDecisionNames {
+		if pd == d {
+			return name
+		}
+	}
+	return "undecided"
+}
+
+// CompareOp compares a source value to a destination value
+type CompareOp string
+
+const (
+	CompareNone CompareOp = ""
+	CompareGT   CompareOp = ">"
+	CompareGTE  CompareOp = ">="
+	CompareLT   CompareOp = "<"
+	CompareLTE  CompareOp = "<="
+	CompareEQ   CompareOp = "=="
+	CompareNE   CompareOp = "!="
+)
+
+// ParseCompareOp parses a comparison operator as written in the config
+func ParseCompareOp(s string) (CompareOp, error) {
+	op := CompareOp(strings.TrimSpace(s))
+	switch op {
+	case CompareGT, CompareGTE, CompareLT, CompareLTE, CompareEQ, CompareNE:
+		return op, nil
+	case CompareNone:
+		fallthrough
+	default:
+		return CompareNone, fmt.Errorf("invalid comparison %q (use >, >=, <, <=, == or !=)", s)
+	}
+}
+
+// Compare returns true if src op dst holds
+func (op CompareOp) Compare(src, dst float64) bool {
+	switch op {
+	case CompareGT:
+		return src > dst
+	case CompareGTE:
+		return src >= dst
+	case CompareLT:
+		return src < dst
+	case CompareLTE:
+		return src <= dst
+	case CompareEQ:
+		return src == dst
+	case CompareNE:
+		return src != dst
+	case CompareNone:
+		fallthrough
+	default:
+		return true
+	}
+}
+
+// PolicyRule automatically decides a conflict when all of its conditions hold for the source against every destination video
+type PolicyRule struct {
+	Name string
+
+	SrcCodec     string    // source video codec is this (hevc, h264, ...)
+	DstCodec     string    // destination video codec is this
+	Resolution   CompareOp // source height compared to destination height
+	AudioStreams CompareOp // source audio stream count compared to destination
+	BitRate      CompareOp // source bitrate compared to destination
+	Size         CompareOp // source size compared to destination
+	MaxSizeRatio float64   // the larger file is at most this many times the size of the smaller
+	Score        CompareOp // source quality score compared to destination
+	ScoreMargin  float64   // the scores differ by at least this many points
+
+	Decision PolicyDecision
+}
+
+// Policy is an ordered list of rules, the first matching rule decides
+type Policy []PolicyRule
+
+// policy condition keys as used in the config
+const (
+	policySrcCodec     = "src-codec"
+	policyDstCodec     = "dst-codec"
+	policyResolution   = "resolution"
+	policyAudioStreams = "audio-streams"
+	policyBitRate      = "bitrate"
+	policySize         = "size"
+	policyMaxSizeRatio = "max-size-ratio"
+	policyScore        = "score"
+	policyScoreMargin  = "score-margin"
+)
+
+// NewPolicyRule builds a rule from the condition map and decision name used in the config
+func NewPolicyRule(name string, when map[string]string, decision string) (*PolicyRule, error) {
+	d, ok := policyDecisionNames[strings.ToLower(decision)]
+	if !ok {
+		return nil, fmt.Errorf("unknown decision %q (use replace, keep or skip)", decision)
+	}
+	if len(when) == 0 {
+		return nil, errors.New("at least one condition is required")
+	}
+
+	r := PolicyRule{Name: name, Decision: d}
+
+	// sorted so errors are reported consistently
+	keys := make([]string, 0, len(when))
+	for k := range when {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v := when[k]
+
+		var err error
+		switch k {
+		case policySrcCodec:
+			r.SrcCodec = normalizeCodec(v)
+		case policyDstCodec:
+			r.DstCodec = normalizeCodec(v)
+		case policyResolution:
+			r.Resolution, err = ParseCompareOp(v)
+		case policyAudioStreams:
+			r.AudioStreams, err = ParseCompareOp(v)
+		case policyBitRate:
+			r.BitRate, err = ParseCompareOp(v)
+		case policySize:
+			r.Size, err = ParseCompareOp(v)
+		case policyMaxSizeRatio:
+			r.MaxSizeRatio, err = strconv.ParseFloat(v, 64)
+			if err == nil && r.MaxSizeRatio < 1 {
+				err = errors.New("must be at least 1")
+			}
+		case policyScore:
+			r.Score, err = ParseCompareOp(v)
+		case policyScoreMargin:
+			r.ScoreMargin, err = strconv.ParseFloat(v, 64)
+			if err == nil && r.ScoreMargin < 0 {
+				err = errors.New("must not be negative")
+			}
+		default:
+			err = errors.New("unknown condition")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
+	}
+
+	return &r, nil
+}
+
+// codecAliases maps common names for a codec to the ffprobe name
+var codecAliases = map[string]string{
+	"h265": "hevc",
+	"x265": "hevc",
+	"avc":  "h264",
+	"x264": "h264",
+}
+
+func normalizeCodec(codec string) string {
+	codec = strings.ToLower(strings.TrimSpace(codec))
+	if alias, ok := codecAliases[codec]; ok {
+		return alias
+	}
+	return codec
+}
+
+func (r PolicyRule) String() string {
+	if r.Name != "" {
+		return r.Name
+	}
+
+	var conds []string
+	if r.SrcCodec != "" {
+		conds = append(conds, policySrcCodec+"="+r.SrcCodec)
+	}
+	if r.DstCodec != "" {
+		conds = append(conds, policyDstCodec+"="+r.DstCodec)
+	}
+	for _, c := range []struct {
+		key string
+		op  CompareOp
+	}{
+		{policyResolution, r.Resolution},
+		{policyAudioStreams, r.AudioStreams},
+		{policyBitRate, r.BitRate},
+		{policySize, r.Size},
+		{policyScore, r.Score},
+	} {
+		if c.op != CompareNone {
+			conds = append(conds, c.key+" "+string(c.op))
+		}
+	}
+	if r.MaxSizeRatio > 0 {
+		conds = append(conds, fmt.Sprintf("%s %g", policyMaxSizeRatio, r.MaxSizeRatio))
+	}
+	if r.ScoreMargin > 0 {
+		conds = append(conds, fmt.Sprintf("%s %g", policyScoreMargin, r.ScoreMargin))
+	}
+
+	return strings.Join(conds, ", ") + " -> " + r.Decision.String()
+}
+
+// Matches returns true if all the rule's conditions hold for src against dst
+func (r PolicyRule) Matches(src, dst VideoFile) bool {
+	// without probe data nothing can be compared safely
+	if src.FFProbeFailed || dst.FFProbeFailed {
+		return false
+	}
+
+	if r.SrcCodec != "" && normalizeCodec(src.VideoStream.CodecName) != r.SrcCodec {
+		return false
+	}
+	if r.DstCodec != "" && normalizeCodec(dst.VideoStream.CodecName) != r.DstCodec {
+		return false
+	}
+	if !r.Resolution.Compare(float64(src.ResolutionH), float64(dst.ResolutionH)) {
+		return false
+	}
+	if !r.AudioStreams.Compare(float64(len(src.AudioStreams)), float64(len(dst.AudioStreams))) {
+		return false
+	}
+	if !r.BitRate.Compare(float64(src.BitRate), float64(dst.BitRate)) {
+		return false
+	}
+	if !r.Size.Compare(float64(src.SizeBytes), float64(dst.SizeBytes)) {
+		return false
+	}
+	if r.MaxSizeRatio > 0 {
+		small, large := float64(src.SizeBytes), float64(dst.SizeBytes)
+		if small > large {
+			small, large = large, small
+		}
+		if small <= 0 || large/small > r.MaxSizeRatio {
+			return false
+		}
+	}
+	if r.Score != CompareNone || r.ScoreMargin > 0 {
+		srcScore, dstScore := src.Score().Total, dst.Score().Total
+		if !r.Score.Compare(srcScore, dstScore) {
+			return false
+		}
+		if math.Abs(srcScore-dstScore) < r.ScoreMargin {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Decide returns the decision of the first rule that matches the source against every destination video
+func (p Policy) Decide(src VideoFile, dsts []VideoFile) (PolicyDecision, *PolicyRule) {
+	if len(dsts) == 0 {
+		return PolicyUndecided, nil
+	}
+
+	for i, r := range p {
+		matched := true
+		for _, dst := range dsts {
+			if !r.Matches(src, dst) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return r.Decision, &p[i]
+		}
+	}
+
+	return PolicyUndecided, nil
+}
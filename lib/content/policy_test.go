@@ -0,0 +1,155 @@
+package content
+
+import (
+	"strings"
+	"testing"
+)
+
+// policyVideo is a probed video with only the fields policy rules compare
+func policyVideo(codec string, height, audioStreams, bitRate int, size int64) VideoFile {
+	return VideoFile{
+		Path:         codec + ".mkv",
+		SizeBytes:    size,
+		BitRate:      bitRate,
+		ResolutionH:  height,
+		VideoStream:  FFProbeStreamVideo{CodecName: codec},
+		AudioStreams: make([]FFProbeStreamAudio, audioStreams),
+	}
+}
+
+func TestPolicyRuleMatches(t *testing.T) {
+	hevc4k := policyVideo("hevc", 2160, 2, 20000000, 20000)
+	h264HD := policyVideo("h264", 1080, 1, 8000000, 8000)
+	h264HDBig := policyVideo("h264", 1080, 3, 8000000, 30000)
+
+	cases := []struct {
+		name string
+		when map[string]string
+		src  VideoFile
+		dst  VideoFile
+		want bool
+	}{
+		{name: "src codec", when: map[string]string{"src-codec": "hevc"}, src: hevc4k, dst: h264HD, want: true},
+		{name: "src codec alias", when: map[string]string{"src-codec": "x265"}, src: hevc4k, dst: h264HD, want: true},
+		{name: "src codec differs", when: map[string]string{"src-codec": "hevc"}, src: h264HD, dst: hevc4k, want: false},
+		{name: "dst codec", when: map[string]string{"dst-codec": "AVC"}, src: hevc4k, dst: h264HD, want: true},
+		{name: "dst codec differs", when: map[string]string{"dst-codec": "h264"}, src: h264HD, dst: hevc4k, want: false},
+
+		{name: "resolution >", when: map[string]string{"resolution": ">"}, src: hevc4k, dst: h264HD, want: true},
+		{name: "resolution > equal", when: map[string]string{"resolution": ">"}, src: h264HD, dst: h264HDBig, want: false},
+		{name: "resolution >=", when: map[string]string{"resolution": ">="}, src: h264HD, dst: h264HDBig, want: true},
+		{name: "resolution <", when: map[string]string{"resolution": "<"}, src: h264HD, dst: hevc4k, want: true},
+		{name: "resolution <=", when: map[string]string{"resolution": "<="}, src: hevc4k, dst: h264HD, want: false},
+		{name: "resolution ==", when: map[string]string{"resolution": "=="}, src: h264HD, dst: h264HDBig, want: true},
+		{name: "resolution !=", when: map[string]string{"resolution": "!="}, src: h264HD, dst: h264HDBig, want: false},
+
+		{name: "audio streams >", when: map[string]string{"audio-streams": ">"}, src: hevc4k, dst: h264HD, want: true},
+		{name: "audio streams <", when: map[string]string{"audio-streams": "<"}, src: hevc4k, dst: h264HDBig, want: true},
+		{name: "bitrate >", when: map[string]string{"bitrate": ">"}, src: hevc4k, dst: h264HD, want: true},
+		{name: "bitrate ==", when: map[string]string{"bitrate": "=="}, src: h264HD, dst: h264HDBig, want: true},
+		{name: "size <", when: map[string]string{"size": "<"}, src: hevc4k, dst: h264HDBig, want: true},
+		{name: "size < larger", when: map[string]string{"size": "<"}, src: hevc4k, dst: h264HD, want: false},
+
+		{name: "max size ratio within", when: map[string]string{"max-size-ratio": "3"}, src: hevc4k, dst: h264HD, want: true},
+		{name: "max size ratio either way", when: map[string]string{"max-size-ratio": "3"}, src: h264HD, dst: hevc4k, want: true},
+		{name: "max size ratio exceeded", when: map[string]string{"max-size-ratio": "3"}, src: h264HD, dst: h264HDBig, want: false},
+		{name: "max size ratio empty file", when: map[string]string{"max-size-ratio": "3"}, src: hevc4k, dst: policyVideo("h264", 1080, 1, 0, 0), want: false},
+
+		{name: "all conditions", when: map[string]string{"src-codec": "hevc", "dst-codec": "h264", "resolution": ">", "size": ">"}, src: hevc4k, dst: h264HD, want: true},
+		{name: "one condition fails", when: map[string]string{"src-codec": "hevc", "resolution": ">", "size": "<"}, src: hevc4k, dst: h264HD, want: false},
+		{name: "failed probe", when: map[string]string{"resolution": "!="}, src: hevc4k, dst: VideoFile{FFProbeFailed: true}, want: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewPolicyRule(tc.name, tc.when, "replace")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := r.Matches(tc.src, tc.dst); got != tc.want {
+				t.Errorf("%s: got %t, want %t", r, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPolicyDecide(t *testing.T) {
+	hevc4k := policyVideo("hevc", 2160, 2, 20000000, 20000)
+	hevc4kSmall := policyVideo("hevc", 2160, 2, 10000000, 10000)
+	h264HD := policyVideo("h264", 1080, 1, 8000000, 8000)
+	h264SD := policyVideo("h264", 480, 1, 2000000, 2000)
+
+	rule := func(name string, when map[string]string, decision string) PolicyRule {
+		r, err := NewPolicyRule(name, when, decision)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return *r
+	}
+	policy := Policy{
+		rule("same resolution smaller", map[string]string{"resolution": "==", "size": "<"}, "skip"),
+		rule("upgrade", map[string]string{"resolution": ">"}, "replace"),
+		rule("downgrade", map[string]string{"resolution": "<"}, "keep"),
+	}
+
+	cases := []struct {
+		name     string
+		src      VideoFile
+		dsts     []VideoFile
+		decision PolicyDecision
+		rule     string
+	}{
+		{name: "no existing videos", src: hevc4k, decision: PolicyUndecided},
+		{name: "first rule", src: hevc4kSmall, dsts: []VideoFile{hevc4k}, decision: PolicySkip, rule: "same resolution smaller"},
+		{name: "later rule", src: hevc4k, dsts: []VideoFile{h264HD}, decision: PolicyReplace, rule: "upgrade"},
+		{name: "first match wins", src: hevc4k, dsts: []VideoFile{h264HD, h264SD}, decision: PolicyReplace, rule: "upgrade"},
+		{name: "must match every existing video", src: h264HD, dsts: []VideoFile{h264SD, hevc4k}, decision: PolicyUndecided},
+		{name: "every existing video downgrades", src: h264SD, dsts: []VideoFile{h264HD, hevc4k}, decision: PolicyKeep, rule: "downgrade"},
+		{name: "no rule matches", src: hevc4k, dsts: []VideoFile{hevc4kSmall}, decision: PolicyUndecided},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			decision, r := policy.Decide(tc.src, tc.dsts)
+			if decision != tc.decision {
+				t.Errorf("got decision %s, want %s", decision, tc.decision)
+			}
+
+			got := ""
+			if r != nil {
+				got = r.Name
+			}
+			if got != tc.rule {
+				t.Errorf("got rule %q, want %q", got, tc.rule)
+			}
+		})
+	}
+}
+
+func TestNewPolicyRuleErrors(t *testing.T) {
+	cases := []struct {
+		name     string
+		when     map[string]string
+		decision string
+		want     string
+	}{
+		{name: "unknown decision", when: map[string]string{"resolution": ">"}, decision: "delete", want: `unknown decision "delete"`},
+		{name: "no conditions", decision: "replace", want: "at least one condition is required"},
+		{name: "unknown condition", when: map[string]string{"colour": ">"}, decision: "keep", want: "colour: unknown condition"},
+		{name: "invalid operator", when: map[string]string{"size": "=>"}, decision: "keep", want: `size: invalid comparison "=>"`},
+		{name: "empty operator", when: map[string]string{"bitrate": ""}, decision: "keep", want: `bitrate: invalid comparison ""`},
+		{name: "ratio not a number", when: map[string]string{"max-size-ratio": "big"}, decision: "skip", want: "max-size-ratio: strconv.ParseFloat"},
+		{name: "ratio below one", when: map[string]string{"max-size-ratio": "0.5"}, decision: "skip", want: "max-size-ratio: must be at least 1"},
+		{name: "negative margin", when: map[string]string{"score-margin": "-1"}, decision: "skip", want: "score-margin: must not be negative"},
+		{name: "first invalid condition", when: map[string]string{"size": "?", "audio-streams": "?"}, decision: "skip", want: "audio-streams:"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewPolicyRule(tc.name, tc.when, tc.decision)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("got %v, want an error containing %q", err, tc.want)
+			}
+		})
+	}
+}
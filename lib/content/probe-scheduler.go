@@ -0,0 +1,199 @@
+package content
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/katbyte/go-ingest-media/lib/ktio"
+)
+
+// MountLimit overrides how many videos can be probed at once under a path (ie a slow nfs mount)
+type MountLimit struct {
+	Path    string
+	Workers int
+}
+
+// ProbeScheduler limits how many videos are probed at once on each filesystem so a slow mount isn't flooded
+// with ffprobe processes while still probing different mounts in parallel
+type ProbeScheduler struct {
+	workers int
+	limits  []MountLimit // longest path first so the most specific limit wins
+
+	mu    sync.Mutex
+	slots map[string]chan struct{}
+
+	probe func(ctx context.Context, path string) (*VideoFile, error) // VideoFor, replaced in tests
+
+	progress func(done, total int64, path string)
+	done     atomic.Int64
+	total    atomic.Int64
+}
+
+// scheduler is used for all video probing
+var scheduler = NewProbeScheduler(4, nil)
+
+// NewProbeScheduler creates a scheduler allowing workers probes at once per filesystem unless a limit covers the path
+func NewProbeScheduler(workers int, limits []MountLimit) *ProbeScheduler {
+	if workers < 1 {
+		workers = 1
+	}
+
+	sorted := make([]MountLimit, 0, len(limits))
+	for _, l := range limits {
+		l.Path = filepath.Clean(l.Path)
+		if l.Workers < 1 {
+			l.Workers = 1
+		}
+		sorted = append(sorted, l)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return len(sorted[i].Path) > len(sorted[j].Path)
+	})
+
+	return &ProbeScheduler{
+		workers: workers,
+		limits:  sorted,
+		slots:   map[string]chan struct{}{},
+		probe:   VideoFor,
+	}
+}
+
+// WithWorkers returns a scheduler with the same mount limits allowing workers probes at once on any other filesystem
+func (s *ProbeScheduler) WithWorkers(workers int) *ProbeScheduler {
+	return NewProbeScheduler(workers, s.limits)
+}
+
+// SetProbeScheduler replaces the scheduler used for all video probing
+func SetProbeScheduler(s *ProbeScheduler) {
+	scheduler = s
+}
+
+// Scheduler returns the scheduler used for all video probing
+func Scheduler() *ProbeScheduler {
+	return scheduler
+}
+
+// SetProgress sets a function called after each probe with the number done and queued since it was set, nil disables it
+func (s *ProbeScheduler) SetProgress(fn func(done, total int64, path string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.progress = fn
+	s.done.Store(0)
+	s.total.Store(0)
+}
+
+// slotsFor returns the semaphore for the mount holding path
+func (s *ProbeScheduler) slotsFor(path string) chan struct{} {
+	key, workers := "", s.workers
+	for _, l := range s.limits {
+		if path == l.Path || strings.HasPrefix(path, l.Path+string(filepath.Separator)) {
+			key, workers = l.Path, l.Workers
+			break
+		}
+	}
+	if key == "" {
+		// unknown devices share a single pool rather than failing
+		dev, _ := ktio.DeviceID(path)
+		key = fmt.Sprintf("dev:%d", dev)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	slots, ok := s.slots[key]
+	if !ok {
+		slots = make(chan struct{}, workers)
+		s.slots[key] = slots
+	}
+	return slots
+}
+
+// Probe loads the video details for path once a slot on its mount is free
+func (s *ProbeScheduler) Probe(ctx context.Context, path string) (*VideoFile, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.total.Add(1)
+	slots := s.slotsFor(path)
+
+	select {
+	case slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	v, err := s.probe(ctx, path)
+	<-slots
+
+	done := s.done.Add(1)
+	s.mu.Lock()
+	progress := s.progress
+	s.mu.Unlock()
+	if progress != nil {
+		progress(done, s.total.Load(), path)
+	}
+
+	return v, err
+}
+
+// ProbeAll loads the video details for all paths in parallel (within the mount limits) returning them in the same order,
+// the first error cancels any probes still waiting for a slot
+func (s *ProbeScheduler) ProbeAll(ctx context.Context, paths []string) ([]VideoFile, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var firstErr error
+	var errOnce sync.Once
+
+	videos := make([]VideoFile, len(paths))
+	for i, p := range paths {
+		wg.Add(1)
+		go func(i int, p string) {
+			defer wg.Done()
+
+			v, err := s.Probe(ctx, p)
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			videos[i] = *v
+		}(i, p)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return videos, nil
+}
+
+// ProbeEach loads the video details for all paths in parallel (within the mount limits) without stopping on errors,
+// returning the videos and errors in the same order as paths
+func (s *ProbeScheduler) ProbeEach(ctx context.Context, paths []string) ([]*VideoFile, []error) {
+	var wg sync.WaitGroup
+
+	videos := make([]*VideoFile, len(paths))
+	errs := make([]error, len(paths))
+	for i, p := range paths {
+		wg.Add(1)
+		go func(i int, p string) {
+			defer wg.Done()
+			videos[i], errs[i] = s.Probe(ctx, p)
+		}(i, p)
+	}
+	wg.Wait()
+
+	return videos, errs
+}
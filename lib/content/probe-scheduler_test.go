@@ -0,0 +1,159 @@
+package content
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// concurrencyProbe records the most probes running at once under each prefix
+type concurrencyProbe struct {
+	mu       sync.Mutex
+	prefixes []string
+	active   map[string]int
+	max      map[string]int
+}
+
+func (p *concurrencyProbe) probe(_ context.Context, path string) (*VideoFile, error) {
+	prefix := ""
+	for _, pre := range p.prefixes {
+		if strings.HasPrefix(path, pre) {
+			prefix = pre
+			break
+		}
+	}
+
+	p.mu.Lock()
+	p.active[prefix]++
+	if p.active[prefix] > p.max[prefix] {
+		p.max[prefix] = p.active[prefix]
+	}
+	p.mu.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	p.mu.Lock()
+	p.active[prefix]--
+	p.mu.Unlock()
+
+	if strings.Contains(path, "broken") {
+		return nil, errors.New("unreadable")
+	}
+	return &VideoFile{Path: path}, nil
+}
+
+func TestProbeSchedulerMountLimits(t *testing.T) {
+	s := NewProbeScheduler(3, []MountLimit{
+		{Path: "/mnt/nas", Workers: 2},
+		{Path: "/mnt/nas/slow/", Workers: 1},
+	})
+	cp := &concurrencyProbe{
+		prefixes: []string{"/mnt/nas/slow/", "/mnt/nas/"},
+		active:   map[string]int{},
+		max:      map[string]int{},
+	}
+	s.probe = cp.probe
+
+	var paths []string
+	for _, p := range []string{"/mnt/nas/slow/", "/mnt/nas/"} {
+		for _, name := range []string{"a.mkv", "b.mkv", "c.mkv", "d.mkv", "e.mkv", "f.mkv"} {
+			paths = append(paths, p+name)
+		}
+	}
+
+	videos, err := s.ProbeAll(context.Background(), paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range videos {
+		if v.Path != paths[i] {
+			t.Errorf("video %d: got %s, want %s", i, v.Path, paths[i])
+		}
+	}
+
+	if got := cp.max["/mnt/nas/slow/"]; got != 1 {
+		t.Errorf("slow mount: %d probes at once, want 1", got)
+	}
+	if got := cp.max["/mnt/nas/"]; got > 2 {
+		t.Errorf("nas mount: %d probes at once, want at most 2", got)
+	}
+}
+
+func TestProbeSchedulerSlots(t *testing.T) {
+	s := NewProbeScheduler(3, []MountLimit{
+		{Path: "/mnt/nas", Workers: 2},
+		{Path: "/mnt/nas/slow", Workers: 0},
+	})
+
+	cases := []struct {
+		path string
+		want int
+	}{
+		{"/mnt/nas/movie.mkv", 2},
+		{"/mnt/nas/slow/movie.mkv", 1}, // the most specific limit wins and is at least 1
+		{"/mnt/nasty/movie.mkv", 3},    // only whole path elements match
+		{"/mnt/nas", 2},
+	}
+
+	for _, tc := range cases {
+		if got := cap(s.slotsFor(tc.path)); got != tc.want {
+			t.Errorf("%s: got %d slots, want %d", tc.path, got, tc.want)
+		}
+	}
+
+	if s.slotsFor("/mnt/nas/a.mkv") != s.slotsFor("/mnt/nas/b.mkv") {
+		t.Error("videos under the same limit should share slots")
+	}
+
+	// --workers only changes the default
+	w := s.WithWorkers(5)
+	if got := cap(w.slotsFor("/mnt/nas/slow/movie.mkv")); got != 1 {
+		t.Errorf("with workers: got %d slots under a limit, want 1", got)
+	}
+	if got := cap(w.slotsFor("/mnt/nasty/movie.mkv")); got != 5 {
+		t.Errorf("with workers: got %d slots, want 5", got)
+	}
+}
+
+func TestProbeSchedulerEachContinues(t *testing.T) {
+	s := NewProbeScheduler(2, []MountLimit{{Path: "/mnt", Workers: 2}})
+	cp := &concurrencyProbe{active: map[string]int{}, max: map[string]int{}}
+	s.probe = cp.probe
+
+	var progress []int64
+	var mu sync.Mutex
+	s.SetProgress(func(done, total int64, _ string) {
+		mu.Lock()
+		defer mu.Unlock()
+		progress = append(progress, done)
+	})
+
+	paths := []string{"/mnt/a.mkv", "/mnt/broken.mkv", "/mnt/c.mkv"}
+	videos, errs := s.ProbeEach(context.Background(), paths)
+	if errs[1] == nil || videos[1] != nil {
+		t.Errorf("broken video: got %v, %v, want an error", videos[1], errs[1])
+	}
+	for _, i := range []int{0, 2} {
+		if errs[i] != nil || videos[i] == nil || videos[i].Path != paths[i] {
+			t.Errorf("video %d: got %v, %v", i, videos[i], errs[i])
+		}
+	}
+	if len(progress) != 3 {
+		t.Errorf("got %d progress updates, want 3", len(progress))
+	}
+
+	// a new run starts counting from zero
+	var last int64
+	s.SetProgress(func(done, total int64, _ string) {
+		last = total
+	})
+	if _, err := s.ProbeAll(context.Background(), []string{"/mnt/d.mkv"}); err != nil {
+		t.Fatal(err)
+	}
+	if last != 1 {
+		t.Errorf("total after reset: got %d, want 1", last)
+	}
+}
@@ -0,0 +1,89 @@
+package content
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/katbyte/go-ingest-media/lib/ktio"
+)
+
+// Prober reads the stream details of a video file
+type Prober interface {
+	Probe(path string, info os.FileInfo) (*FFProbeOutput, error)
+}
+
+type proberKey struct{}
+
+// WithProber returns a context that reads videos with p, ie a FixtureProber in tests
+func WithProber(ctx context.Context, p Prober) context.Context {
+	return context.WithValue(ctx, proberKey{}, p)
+}
+
+// ProberFrom returns the prober set on the context, or the system prober using ffprobe first
+func ProberFrom(ctx context.Context) Prober {
+	if p, ok := ctx.Value(proberKey{}).(Prober); ok && p != nil {
+		return p
+	}
+	return SystemProber{}
+}
+
+// SystemProber runs ffprobe (or uses its cached result) falling back to reading the container headers,
+// or the other way around when the native reader is preferred
+type SystemProber struct {
+	PreferNative bool
+}
+
+func (p SystemProber) Probe(path string, info os.FileInfo) (*FFProbeOutput, error) {
+	if p.PreferNative {
+		if probe, err := NativeProbe(path); err == nil {
+			return probe, nil
+		}
+		return cachedFFProbe(path, info)
+	}
+
+	probe, err := cachedFFProbe(path, info)
+	if err == nil {
+		return probe, nil
+	}
+	if native, nerr := NativeProbe(path); nerr == nil {
+		return native, nil
+	}
+
+	return nil, err
+}
+
+// FixtureProber returns saved ffprobe json output instead of probing so tests don't need ffprobe or real videos. The
+// contents of a video (up to any padding NULs) is the name of its fixture, ie a file containing hevc-1080p.mkv is probed
+// as <dir>/hevc-1080p.mkv.json, so the fixture follows the file when it is moved or renamed
+type FixtureProber struct {
+	Dir string
+}
+
+func (p FixtureProber) Probe(path string, _ os.FileInfo) (*FFProbeOutput, error) {
+	b, err := ktio.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	name, _, _ := strings.Cut(string(b), "\x00")
+	if name = strings.TrimSpace(name); name == "" || strings.ContainsAny(name, `/\`) {
+		return nil, fmt.Errorf("no probe fixture for %s", filepath.Base(path))
+	}
+
+	fixture := filepath.Join(p.Dir, name+".json")
+	b, err = os.ReadFile(fixture)
+	if err != nil {
+		return nil, fmt.Errorf("no probe fixture for %s: %w", filepath.Base(path), err)
+	}
+
+	var probe FFProbeOutput
+	if err := json.Unmarshal(b, &probe); err != nil {
+		return nil, fmt.Errorf("error parsing probe fixture %s: %w", fixture, err)
+	}
+
+	return &probe, nil
+}
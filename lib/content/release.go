@@ -0,0 +1,255 @@
+package content
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Release is what can be parsed from a scene or p2p release name ie The.Movie.2019.1080p.BluRay.x265-GRP
+type Release struct {
+	Title        string
+	Year         int
+	Season       int   // 0 if the name has no season
+	Episodes     []int // empty unless the name is for one or more episodes
+	EpisodeTitle string
+	Resolution   string // 2160p, 1080p, 720p...
+	Source       string // BluRay, WEB-DL, HDTV...
+	Codec        string // x264, x265, hevc...
+	Edition      string
+	Group        string
+
+	tagged bool // found resolution, source, codec or group tags
+	dotted bool // words are separated by dots instead of spaces
+}
+
+var (
+	releaseSeasonRegex = regexp.MustCompile(`(?i)\b(?:S(\d{1,2})|Season[ .]?(\d{1,2}))\b`)
+	releaseYearRegex   = regexp.MustCompile(`\b(19\d{2}|20\d{2})\b`)
+	releaseResRegex    = regexp.MustCompile(`(?i)\b(?:(2160|1080|720|576|480)[pi]|(4k|uhd))\b`)
+	releaseSourceRegex = regexp.MustCompile(`(?i)\b(blu-?ray|bdrip|brrip|bdremux|remux|web-?dl|web-?rip|web|hdtv|dvdrip|dvd|hdrip)\b`)
+	releaseCodecRegex  = regexp.MustCompile(`(?i)\b(x264|x265|h\.?264|h\.?265|hevc|avc|av1|xvid|divx|vp9)\b`)
+	releaseGroupRegex  = regexp.MustCompile(`-([A-Za-z0-9]+)$`)
+	releaseTagRegex    = regexp.MustCompile(`\[[^\]]*\]`)
+	releaseSampleRegex = regexp.MustCompile(`(?i)\bsample\b`)
+)
+
+// the consistent name for each source, keyed by the lower case name without dashes
+var releaseSources = map[string]string{
+	"bluray":  "BluRay",
+	"bdrip":   "BDRip",
+	"brrip":   "BRRip",
+	"bdremux": "Remux",
+	"remux":   "Remux",
+	"webdl":   "WEB-DL",
+	"webrip":  "WEBRip",
+	"web":     "WEB",
+	"hdtv":    "HDTV",
+	"dvdrip":  "DVDRip",
+	"dvd":     "DVD",
+	"hdrip":   "HDRip",
+}
+
+// the consistent name for each codec, keyed by the lower case name without dots
+var releaseCodecs = map[string]string{
+	"x264": "x264",
+	"x265": "x265",
+	"h264": "h264",
+	"avc":  "h264",
+	"h265": "hevc",
+	"hevc": "hevc",
+	"av1":  "av1",
+	"xvid": "xvid",
+	"divx": "divx",
+	"vp9":  "vp9",
+}
+
+// ParseRelease pulls the title, year, season and episode and the quality tags out of a release name (without its
+// extension). Names already following the library convention parse too but are not a release, see IsRelease
+func ParseRelease(name string) Release {
+	r := Release{}
+
+	name = strings.ReplaceAll(strings.TrimSpace(name), "_", " ")
+	r.dotted = !strings.Contains(name, " ") && strings.Count(name, ".") >= 2
+
+	// [tags] are either a leading group or trailing site names, neither are part of the title
+	if strings.HasPrefix(name, "[") {
+		if end := strings.Index(name, "]"); end > 0 {
+			r.Group = strings.TrimSpace(name[1:end])
+			name = strings.TrimSpace(name[end+1:])
+		}
+	}
+	name = strings.TrimSpace(releaseTagRegex.ReplaceAllString(name, ""))
+
+	// the title ends at the first tag, or at the year just before it
+	titleEnd := len(name)
+	first := func(loc []int) {
+		if loc != nil && loc[0] < titleEnd {
+			titleEnd = loc[0]
+		}
+	}
+
+	// the episodes are found the same way as in the library, the season and where the title ends from the S01E02 or
+	// 1x02 the episode matchers look for
+	episodeLoc := sxxeyyEpisodeRegex.FindStringSubmatchIndex(name)
+	if episodeLoc == nil {
+		episodeLoc = crossEpisodeRegex.FindStringSubmatchIndex(name)
+	}
+	if episodeLoc != nil {
+		r.Season, _ = strconv.Atoi(name[episodeLoc[2]:episodeLoc[3]])
+		if match, ok := MatchEpisode(name); ok {
+			r.Episodes = match.Episodes
+		}
+	} else if loc := releaseSeasonRegex.FindStringSubmatchIndex(name); loc != nil {
+		m := releaseSeasonRegex.FindStringSubmatch(name)
+		r.Season, _ = strconv.Atoi(m[1] + m[2])
+		first(loc)
+	}
+	first(episodeLoc)
+
+	if m := releaseResRegex.FindStringSubmatch(name); m != nil {
+		r.Resolution = strings.ToLower(m[1]) + "p"
+		if m[2] != "" {
+			r.Resolution = "2160p"
+		}
+		first(releaseResRegex.FindStringIndex(name))
+	}
+	if m := releaseSourceRegex.FindStringSubmatch(name); m != nil {
+		r.Source = releaseSources[strings.ReplaceAll(strings.ToLower(m[1]), "-", "")]
+		first(releaseSourceRegex.FindStringIndex(name))
+	}
+	if m := releaseCodecRegex.FindStringSubmatch(name); m != nil {
+		r.Codec = releaseCodecs[strings.ReplaceAll(strings.ToLower(m[1]), ".", "")]
+		first(releaseCodecRegex.FindStringIndex(name))
+	}
+
+	// the group is after the last dash, as long as the dash isn't part of a tag ie WEB-DL
+	if m := releaseGroupRegex.FindStringSubmatch(name); m != nil && titleEnd < len(name) {
+		if _, isSource := releaseSources["web"+strings.ToLower(m[1])]; !isSource {
+			r.Group = m[1]
+		}
+	}
+	r.tagged = r.Resolution != "" || r.Source != "" || r.Codec != "" || r.Group != ""
+
+	// a year at the very start is the title, ie 1917 or 2012, the last one before the tags is the release year
+	yearEnd := titleEnd
+	for _, loc := range releaseYearRegex.FindAllStringIndex(name, -1) {
+		if loc[0] > 0 && loc[0] < yearEnd {
+			r.Year, _ = strconv.Atoi(name[loc[0]:loc[1]])
+			titleEnd = loc[0]
+		}
+	}
+
+	r.Edition = ParseEdition(name)
+	if r.Year == 0 && r.Edition != "" {
+		for _, e := range editions {
+			first(e.Regex.FindStringIndex(name))
+		}
+	}
+
+	r.Title = cleanReleaseTitle(name[:titleEnd], r.dotted)
+
+	// the episode title is between the episode and the next tag
+	if episodeLoc != nil && len(r.Episodes) > 0 {
+		r.EpisodeTitle = titleBeforeTags(name[episodeLoc[1]:], r.dotted)
+	}
+
+	return r
+}
+
+// titleBeforeTags returns the title at the start of s, up to the first resolution, source, codec, year or edition
+func titleBeforeTags(s string, dotted bool) string {
+	end := len(s)
+	for _, re := range []*regexp.Regexp{releaseResRegex, releaseSourceRegex, releaseCodecRegex, releaseYearRegex} {
+		if loc := re.FindStringIndex(s); loc != nil && loc[0] < end {
+			end = loc[0]
+		}
+	}
+	for _, e := range editions {
+		if loc := e.Regex.FindStringIndex(s); loc != nil && loc[0] < end {
+			end = loc[0]
+		}
+	}
+	return cleanReleaseTitle(s[:end], dotted)
+}
+
+// episodeRange returns the episodes from first to last, or just the first if there is no last
+func episodeRange(first, last string) []int {
+	start, _ := strconv.Atoi(first)
+	end, err := strconv.Atoi(last)
+	if err != nil || end <= start {
+		return []int{start}
+	}
+
+	episodes := []int{}
+	for e := start; e <= end; e++ {
+		episodes = append(episodes, e)
+	}
+	return episodes
+}
+
+// cleanReleaseTitle turns dots back into spaces and trims the separators left around a title
+func cleanReleaseTitle(s string, dotted bool) string {
+	if dotted {
+		s = strings.ReplaceAll(s, ".", " ")
+	}
+	s = strings.Join(strings.Fields(s), " ")
+	return strings.Trim(s, " -([.")
+}
+
+// IsRelease returns true if the name looks like a raw download rather than the library convention
+func (r Release) IsRelease() bool {
+	return r.Title != "" && (r.tagged || r.dotted)
+}
+
+// IsEpisode returns true if the name has a season and episode
+func (r Release) IsEpisode() bool {
+	return len(r.Episodes) > 0
+}
+
+// MovieFolder returns the library folder name, ie The Movie (2019)
+func (r Release) MovieFolder() string {
+	if r.Year == 0 {
+		return r.Title
+	}
+	return fmt.Sprintf("%s (%d)", r.Title, r.Year)
+}
+
+// MovieFile returns the library file name for the movie's video, with the edition as a version if there is one
+func (r Release) MovieFile(ext string) string {
+	if r.Edition == "" {
+		return r.MovieFolder() + ext
+	}
+	return r.MovieFolder() + " - " + r.Edition + ext
+}
+
+// SeriesFolder returns the library folder name for the series, ie The Show (2010)
+func (r Release) SeriesFolder() string {
+	return r.MovieFolder()
+}
+
+// SeasonFolder returns the library folder name for the season, ie The Show - s01
+func (r Release) SeasonFolder() string {
+	return fmt.Sprintf("%s - s%02d", r.Title, r.Season)
+}
+
+// EpisodeFile returns the library file name for the episode, ie The Show - 01x01 - Pilot.mkv or 01x01-02 for a multi
+// episode file
+func (r Release) EpisodeFile(ext string) string {
+	numbers := fmt.Sprintf("%02dx%02d", r.Season, r.Episodes[0])
+	if len(r.Episodes) > 1 {
+		numbers += fmt.Sprintf("-%02d", r.Episodes[len(r.Episodes)-1])
+	}
+
+	title := r.EpisodeTitle
+	if title == "" {
+		title = fmt.Sprintf("Episode %d", r.Episodes[0])
+	}
+	return fmt.Sprintf("%s - %s - %s%s", r.Title, numbers, title, ext)
+}
+
+// IsSample returns true for the sample clips included with some releases
+func IsSample(name string) bool {
+	return releaseSampleRegex.MatchString(name)
+}
@@ -0,0 +1,116 @@
+package content
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRelease(t *testing.T) {
+	cases := []struct {
+		name string
+		want Release
+	}{
+		{
+			name: "The.Movie.2019.1080p.BluRay.x265-GRP",
+			want: Release{Title: "The Movie", Year: 2019, Resolution: "1080p", Source: "BluRay", Codec: "x265", Group: "GRP"},
+		},
+		{
+			name: "Blade.Runner.1982.The.Final.Cut.REMASTERED.2160p.UHD.BluRay.REMUX.HEVC-FGT",
+			want: Release{Title: "Blade Runner", Year: 1982, Resolution: "2160p", Source: "BluRay", Codec: "hevc", Edition: "Final Cut Remastered", Group: "FGT"},
+		},
+		{
+			name: "Blade.Runner.2049.2017.1080p.WEB-DL.H.264",
+			want: Release{Title: "Blade Runner 2049", Year: 2017, Resolution: "1080p", Source: "WEB-DL", Codec: "h264"},
+		},
+		{
+			name: "1917.2019.720p.WEBRip.x264-YTS",
+			want: Release{Title: "1917", Year: 2019, Resolution: "720p", Source: "WEBRip", Codec: "x264", Group: "YTS"},
+		},
+		{
+			name: "The Movie (2019) [1080p] [BluRay] [5.1] [YTS.MX]",
+			want: Release{Title: "The Movie", Year: 2019},
+		},
+		{
+			name: "The.Show.S01E01.Pilot.1080p.AMZN.WEB-DL.DDP5.1.H.264-NTb",
+			want: Release{Title: "The Show", Season: 1, Episodes: []int{1}, EpisodeTitle: "Pilot", Resolution: "1080p", Source: "WEB-DL", Codec: "h264", Group: "NTb"},
+		},
+		{
+			name: "The.Show.2010.S02E03E04.720p.HDTV.x264-LOL[rarbg]",
+			want: Release{Title: "The Show", Year: 2010, Season: 2, Episodes: []int{3, 4}, Resolution: "720p", Source: "HDTV", Codec: "x264", Group: "LOL"},
+		},
+		{
+			name: "The.Show.2010.S02E01E03.720p.HDTV.x264-LOL",
+			want: Release{Title: "The Show", Year: 2010, Season: 2, Episodes: []int{1, 3}, Resolution: "720p", Source: "HDTV", Codec: "x264", Group: "LOL"},
+		},
+		{
+			name: "The.Show.S03.1080p.BluRay.x264-GRP",
+			want: Release{Title: "The Show", Season: 3, Resolution: "1080p", Source: "BluRay", Codec: "x264", Group: "GRP"},
+		},
+		{
+			name: "the_show_1x05_the_title",
+			want: Release{Title: "the show", Season: 1, Episodes: []int{5}, EpisodeTitle: "the title"},
+		},
+		{
+			name: "[SubGroup] Show Name S01E02 [1080p]",
+			want: Release{Title: "Show Name", Season: 1, Episodes: []int{2}, Group: "SubGroup"},
+		},
+		{
+			name: "Spider-Man (2002)",
+			want: Release{Title: "Spider-Man", Year: 2002},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := ParseRelease(tc.name)
+			got.tagged, got.dotted = false, false
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %+v\nwant %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestReleaseIsRelease(t *testing.T) {
+	for name, want := range map[string]bool{
+		"The.Movie.2019.1080p.BluRay.x265-GRP":     true,
+		"The.Movie.2019":                           true,
+		"The Movie 2019 1080p WEB-DL":              true,
+		"The Movie (2019)":                         false,
+		"Spider-Man (2002)":                        false,
+		"Show (2010)":                              false,
+		"Show - 01x01 - Pilot":                     false,
+		"Mr. Robot (2015)":                         false,
+		"The.Show.S01E01.Pilot.1080p.WEB-DL-GRP":   true,
+		"Blade Runner (1982) - Final Cut":          false,
+		"Blade.Runner.1982.Final.Cut.1080p.BluRay": true,
+	} {
+		if got := ParseRelease(name).IsRelease(); got != want {
+			t.Errorf("%s: got %t, want %t", name, got, want)
+		}
+	}
+}
+
+func TestReleaseNames(t *testing.T) {
+	movie := ParseRelease("Blade.Runner.1982.Final.Cut.1080p.BluRay.x264-GRP")
+	if got := movie.MovieFolder(); got != "Blade Runner (1982)" {
+		t.Errorf("folder: got %q", got)
+	}
+	if got := movie.MovieFile(".mkv"); got != "Blade Runner (1982) - Final Cut.mkv" {
+		t.Errorf("file: got %q", got)
+	}
+
+	episode := ParseRelease("The.Show.2010.S02E03E04.720p.HDTV.x264-LOL")
+	if got := episode.SeasonFolder(); got != "The Show - s02" {
+		t.Errorf("season: got %q", got)
+	}
+	if got := episode.EpisodeFile(".mkv"); got != "The Show - 02x03-04 - Episode 3.mkv" {
+		t.Errorf("episode: got %q", got)
+	}
+
+	// the files parse the same as the library's episode names
+	named := ParseRelease("The.Show.S01E01.Pilot.1080p.WEB-DL-GRP")
+	if got := named.EpisodeFile(".mkv"); got != "The Show - 01x01 - Pilot.mkv" {
+		t.Errorf("episode: got %q", got)
+	}
+}
@@ -1,173 +1,190 @@
 package content
 
 import (
+	_ "embed"
 	"errors"
 	"fmt"
+	"os"
 	"regexp"
 	"strings"
+
+	"gopkg.in/yaml.v3"
 )
 
 var yearRegEx = regexp.MustCompile(`\(\d{4}\)`)
 
-func strPtr(s string) *string { return &s }
-
 type MappingType int
 
 const (
-	UnknownMapping     MappingType = iota
-	MappingTypeReplace             // replace find regex with replace string
-	MappingTypeMoveYearRegex
+	UnknownMapping              MappingType = iota
+	MappingTypeReplace                      // replace find string exactly with replace string
+	MappingTypeMoveYearRegex                // move the (YYYY) to just after the find regex match
+	MappingTypeRegexReplace                 // replace find regex with replace string, capture groups allowed ($1)
+	MappingTypePrefixStrip                  // remove find prefix from the start of the folder
+	MappingTypeSeriesPrefixYear             // prefix "Series (YYYY) - " when the find regex matches
 )
 
+// mappingTypeNames are the rule type names used in the rules file
+var mappingTypeNames = map[string]MappingType{
+	"replace":            MappingTypeReplace,
+	"move-year":          MappingTypeMoveYearRegex,
+	"regex-replace":      MappingTypeRegexReplace,
+	"prefix-strip":       MappingTypePrefixStrip,
+	"series-prefix-year": MappingTypeSeriesPrefixYear,
+}
+
+func (t MappingType) String() string {
+	for name, mt := range mappingTypeNames {
+		if mt == t {
+			return name
+		}
+	}
+	return "unknown"
+}
+
 // FolderMapping handles folder name transformations (renamed from Mapping)
 type FolderMapping struct {
-	Type             MappingType
-	FindStr          *string        // find exact match including year
-	FindRegex        *regexp.Regexp // find anything matching regex
-	FindPrefix       *string        // find anything matching prefix
-	ReplaceStr       *string        // replace exactly with this string
-	ReplaceYearRegex *regexp.Regexp // replace with this regex where (YYYY) is replaced with ie (1999)
+	Type        MappingType
+	FindStr     *string        // find exact match including year
+	FindRegex   *regexp.Regexp // find anything matching regex
+	FindPrefix  *string        // find anything matching prefix
+	ReplaceStr  *string        // replace exactly with this string (or regex template)
+	SeriesStr   *string        // series name to prefix with the year
+	Description string         // how the rule was written in the rules file
+}
+
+func (m FolderMapping) String() string {
+	return m.Description
 }
 
+//go:embed renames.yaml
+var defaultRenameRules []byte
+
 // folderRenames - global folder rename mappings per library type
-var folderRenames = map[LibraryType][]FolderMapping{
-	LibraryTypeMovies: {
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Alvin and the Chipmunks")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^American Ninja")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^American Pie")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Amityville")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Asterix")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Attack on Titan")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Batman")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Battlestar Galactica")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Below Deck")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Beverly Hills Cop")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Blade")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Bourne")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Candyman")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Captain America")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Children of the Corn")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^The Chronicles of Narnia")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^City Hunter")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Critters")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Cube")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Death Wish")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Dead Space")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Deathstalker")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^The Descent")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Die Hard")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Family Guy Presents")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Futurama")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Friday the 13th")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Gamera")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Garfield")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile(`^G\.I\. Joe`)},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Get Smart")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Ginger Snaps")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^The Godfather")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Guardians of the Galaxy")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Halloween ")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Hellraiser")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Highlander")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Hitman")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Howling")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Jurassic Park")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Jaws")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Jay and Silent Bob")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Jackass")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Jurassic World")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Kung Fu Panda")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Mega Shark")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Mission Impossible")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Monster High")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Monty Python")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Mortal Kombat")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^National Treasure")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^NCIS")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Night at the Museum")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^A Nightmare on Elm Street")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^One Piece")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Paranormal Activity")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Perry Mason")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Police Academy")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Prince of Persia")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^The Princess Diaries")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Puss in Boots")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^The Purge")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Resident Evil")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Return of the Living Dead")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^RoboCop")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Rurouni Kenshin")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Saw")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Scooby-Doo")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Sharknado")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^South Park")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Spider-Man")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Starship Troopers")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Teenage Mutant Ninja Turtles")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Terminator")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^The Adventures of Young Indiana Jones")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^The Conjuring")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^The Fast and the Furious")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^The Hunger Games")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^The Lion King")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^The Matrix")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^The Terminator")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Tinker Bell")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Tom and Jerry")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Transformers")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Transporter")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Trinity Seven")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Wallace")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Death Note")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Attack on Titan")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Lupin the Third")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^The Walking Dead")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Broken Blade")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Mobile Suit Gundam")},
-
-
-
-		// Exact renames for franchises where the series title isn't at the start
-		{Type: MappingTypeReplace, FindStr: strPtr("Beneath the Planet of the Apes (1970)"), ReplaceStr: strPtr("Planet of the Apes (1970) - Beneath the Planet of the Apes")},
-		{Type: MappingTypeReplace, FindStr: strPtr("Escape from the Planet of the Apes (1971)"), ReplaceStr: strPtr("Planet of the Apes (1971) - Escape from the Planet of the Apes")},
-		{Type: MappingTypeReplace, FindStr: strPtr("Conquest of the Planet of the Apes (1972)"), ReplaceStr: strPtr("Planet of the Apes (1972) - Conquest of the Planet of the Apes")},
-		{Type: MappingTypeReplace, FindStr: strPtr("Battle for the Planet of the Apes (1973)"), ReplaceStr: strPtr("Planet of the Apes (1973) - Battle for the Planet of the Apes")},
-		{Type: MappingTypeReplace, FindStr: strPtr("The Chronicles of Riddick (2004)"), ReplaceStr: strPtr("Riddick (2004) - The Chronicles of Riddick")},
-		{Type: MappingTypeReplace, FindStr: strPtr("Live Free or Die Hard (2007)"), ReplaceStr: strPtr("Die Hard (2007) - Live Free or Die Hard")},
-		{Type: MappingTypeReplace, FindStr: strPtr("A Good Day to Die Hard (2013)"), ReplaceStr: strPtr("Die Hard (2013) - A Good Day to Die Hard")},
-		{Type: MappingTypeReplace, FindStr: strPtr("A Grand Day Out (1990)"), ReplaceStr: strPtr("Wallace And Gromit (1990) - A Grand Day Out")},
-		{Type: MappingTypeReplace, FindStr: strPtr("The Wrong Trousers (1993)"), ReplaceStr: strPtr("Wallace And Gromit (1993) - The Wrong Trousers")},
-		{Type: MappingTypeReplace, FindStr: strPtr("A Close Shave (1995)"), ReplaceStr: strPtr("Wallace And Gromit (1995) - A Close Shave")},
-		{Type: MappingTypeReplace, FindStr: strPtr("A Matter of Loaf and Death (2008)"), ReplaceStr: strPtr("Wallace And Gromit (2008) - A Matter of Loaf and Death")},
-		{Type: MappingTypeReplace, FindStr: strPtr("And Now for Something Completely Different (1971)"), ReplaceStr: strPtr("Monty Python (1971) - And Now for Something Completely Different")},
-		{Type: MappingTypeReplace, FindStr: strPtr("Jabberwocky (1977)"), ReplaceStr: strPtr("Monty Python (1977) - Jabberwocky")},
-		{Type: MappingTypeReplace, FindStr: strPtr("The Terminator (1984)"), ReplaceStr: strPtr("Terminator (1984) - The Terminator")},
-		{Type: MappingTypeReplace, FindStr: strPtr("Terminator 2 - Judgment Day (1991)"), ReplaceStr: strPtr("Terminator (1991) 2 - Judgement Day")},
-		{Type: MappingTypeReplace, FindStr: strPtr("Terminator 3 - Rise of the Machines (2003)"), ReplaceStr: strPtr("Terminator (2003) 3 - The Rise of the Machines")},
-		{Type: MappingTypeReplace, FindStr: strPtr("Terminator Salvation (2009)"), ReplaceStr: strPtr("Terminator (2009) - Salvation")},
-		{Type: MappingTypeReplace, FindStr: strPtr("The Transporter (2002)"), ReplaceStr: strPtr("Transporter (2002)")},
-		{Type: MappingTypeReplace, FindStr: strPtr("The Transporter Refueled (2015)"), ReplaceStr: strPtr("Transporter (2015) Refueled")},
-		{Type: MappingTypeReplace, FindStr: strPtr("Twilight (2008)"), ReplaceStr: strPtr("The Twilight Saga (2008) - Twilight")},
-		{Type: MappingTypeReplace, FindStr: strPtr("The X Files (1998)"), ReplaceStr: strPtr("The X-Files (1998)")},
-		{Type: MappingTypeReplace, FindStr: strPtr("The X Files - I Want to Believe (2008)"), ReplaceStr: strPtr("The X-Files (2008) - I Want to Believe")},
-	},
-	LibraryTypeStandup: {},
-	LibraryTypeSeries: {
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Batman")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Law & Order")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Mobile Suit Gundam")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Spider-Man")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Star Trek")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Star Wars")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Stargate")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Teenage Mutant Ninja Turtles")},
-		{Type: MappingTypeMoveYearRegex, FindRegex: regexp.MustCompile("^Transformers")},
-	},
+var folderRenames = mustParseRenameRules(defaultRenameRules)
+
+// renameRuleConfig is a single rule as written in the rules file
+type renameRuleConfig struct {
+	Type       string `yaml:"type"`
+	Find       string `yaml:"find"`
+	Replace    string `yaml:"replace"`
+	Series     string `yaml:"series"`
+	AllowEmpty bool   `yaml:"allow-empty"` // regex-replace may delete the matched text
+}
+
+func mustParseRenameRules(data []byte) map[LibraryType][]FolderMapping {
+	rules, err := ParseRenameRules(data)
+	if err != nil {
+		panic(fmt.Sprintf("parsing built in rename rules: %v", err))
+	}
+	return rules
+}
+
+// LoadRenameRules replaces the built in folder rename rules with those from the given rules file
+func LoadRenameRules(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading rename rules: %w", err)
+	}
+
+	rules, err := ParseRenameRules(data)
+	if err != nil {
+		return fmt.Errorf("error parsing rename rules %s: %w", path, err)
+	}
+
+	folderRenames = rules
+	return nil
+}
+
+// ParseRenameRules parses a yaml rules file with a list of rules per library type (movies, series, standup)
+func ParseRenameRules(data []byte) (map[LibraryType][]FolderMapping, error) {
+	var sections map[string][]renameRuleConfig
+	if err := yaml.Unmarshal(data, &sections); err != nil {
+		return nil, err
+	}
+
+	rules := map[LibraryType][]FolderMapping{}
+	for section, configs := range sections {
+		libType, err := LibraryTypeFor(section)
+		if err != nil {
+			return nil, fmt.Errorf("section %q: %w", section, err)
+		}
+
+		mappings := make([]FolderMapping, 0, len(configs))
+		for i, rc := range configs {
+			m, err := rc.mapping()
+			if err != nil {
+				return nil, fmt.Errorf("%s rule %d: %w", section, i+1, err)
+			}
+			mappings = append(mappings, *m)
+		}
+
+		rules[libType] = mappings
+	}
+
+	return rules, nil
+}
+
+func (rc renameRuleConfig) mapping() (*FolderMapping, error) {
+	t, ok := mappingTypeNames[rc.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown rule type %q", rc.Type)
+	}
+	if rc.Find == "" {
+		return nil, errors.New("find is required")
+	}
+
+	m := FolderMapping{Type: t}
+	switch t {
+	case MappingTypeReplace:
+		if rc.Replace == "" {
+			return nil, errors.New("replace is required")
+		}
+		m.FindStr = &rc.Find
+		m.ReplaceStr = &rc.Replace
+		m.Description = fmt.Sprintf("%s %q -> %q", t, rc.Find, rc.Replace)
+	case MappingTypeRegexReplace:
+		if rc.Replace == "" && !rc.AllowEmpty {
+			return nil, errors.New("replace is required (set allow-empty to delete the matched text)")
+		}
+		m.ReplaceStr = &rc.Replace
+		m.Description = fmt.Sprintf("%s /%s/ -> %q", t, rc.Find, rc.Replace)
+	case MappingTypePrefixStrip:
+		m.FindPrefix = &rc.Find
+		m.Description = fmt.Sprintf("%s %q", t, rc.Find)
+	case MappingTypeSeriesPrefixYear:
+		if rc.Series == "" {
+			return nil, errors.New("series is required")
+		}
+		m.SeriesStr = &rc.Series
+		m.Description = fmt.Sprintf("%s /%s/ -> %q", t, rc.Find, rc.Series)
+	case MappingTypeMoveYearRegex:
+		m.Description = fmt.Sprintf("%s /%s/", t, rc.Find)
+	case UnknownMapping:
+		fallthrough
+	default:
+		return nil, fmt.Errorf("unknown rule type %q", rc.Type)
+	}
+
+	if t == MappingTypeMoveYearRegex || t == MappingTypeRegexReplace || t == MappingTypeSeriesPrefixYear {
+		r, err := regexp.Compile(rc.Find)
+		if err != nil {
+			return nil, fmt.Errorf("invalid find regex: %w", err)
+		}
+		m.FindRegex = r
+	}
+
+	return &m, nil
 }
 
 // AltFolderFor returns an alternate folder name based on folder renames
 func AltFolderFor(libType LibraryType, folder string) (*string, error) {
+	newFolder, _, err := MatchFolderRename(libType, folder)
+	return newFolder, err
+}
+
+// MatchFolderRename returns an alternate folder name and a description of the rule that produced it
+func MatchFolderRename(libType LibraryType, folder string) (*string, string, error) {
 	maps := folderRenames[libType]
 
 	// find matching mappings and then return the "alt" folder
@@ -184,16 +201,38 @@ func AltFolderFor(libType LibraryType, folder string) (*string, error) {
 
 				// replace find regex match with year
 				newFolder := m.FindRegex.ReplaceAllString(folderWithoutYear, fmt.Sprintf("%s %s", match, year))
-				return &newFolder, nil
+				return &newFolder, m.String(), nil
 			}
 		case MappingTypeReplace:
 			if m.FindStr != nil && *m.FindStr == folder {
-				return m.ReplaceStr, nil
+				return m.ReplaceStr, m.String(), nil
+			}
+		case MappingTypeRegexReplace:
+			if m.FindRegex.MatchString(folder) {
+				newFolder := strings.TrimSpace(m.FindRegex.ReplaceAllString(folder, *m.ReplaceStr))
+				return &newFolder, m.String(), nil
+			}
+		case MappingTypePrefixStrip:
+			if strings.HasPrefix(folder, *m.FindPrefix) {
+				newFolder := strings.TrimSpace(strings.TrimPrefix(folder, *m.FindPrefix))
+				return &newFolder, m.String(), nil
+			}
+		case MappingTypeSeriesPrefixYear:
+			if m.FindRegex.MatchString(folder) {
+				// ie "Live Free or Die Hard (2007)" -> "Die Hard (2007) - Live Free or Die Hard"
+				year := yearRegEx.FindString(folder)
+				if year == "" {
+					return nil, "", fmt.Errorf("no year found in folder name for rule %s", m)
+				}
+				title := strings.TrimSpace(yearRegEx.ReplaceAllString(folder, ""))
+
+				newFolder := fmt.Sprintf("%s %s - %s", *m.SeriesStr, year, title)
+				return &newFolder, m.String(), nil
 			}
 		case UnknownMapping:
 			fallthrough
 		default:
-			return nil, fmt.Errorf("unknown mapping type: %d", m.Type)
+			return nil, "", fmt.Errorf("unknown mapping type: %d", m.Type)
 		}
 	}
 
@@ -203,7 +242,7 @@ func AltFolderFor(libType LibraryType, folder string) (*string, error) {
 		// ie "Jim Jefferies - Freedumb (2016)" -> "Jim Jefferies (2016) - Freedumb"
 		year := yearRegEx.FindString(folder)
 		if year == "" {
-			return nil, errors.New("no year found in folder name")
+			return nil, "", errors.New("no year found in folder name")
 		}
 
 		// remove year from folder name
@@ -212,7 +251,7 @@ func AltFolderFor(libType LibraryType, folder string) (*string, error) {
 		// Split the folder name into two parts based on the first dash
 		parts := strings.SplitN(folderWithoutYear, "-", 2)
 		if len(parts) < 2 {
-			return nil, errors.New("invalid folder name format")
+			return nil, "", errors.New("invalid folder name format")
 		}
 
 		// Trim any leading or trailing spaces
@@ -222,8 +261,8 @@ func AltFolderFor(libType LibraryType, folder string) (*string, error) {
 
 		newFolderName := fmt.Sprintf("%s %s - %s", parts[0], year, parts[1])
 
-		return &newFolderName, nil
+		return &newFolderName, "standup year move (built in)", nil
 	}
 
-	return nil, nil
+	return nil, "", nil
 }
@@ -0,0 +1,100 @@
+package content
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRenameRulesErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		rules string
+		err   string
+	}{
+		{"unknown type", `movies: [{ type: swap, find: a }]`, "unknown rule type"},
+		{"missing find", `movies: [{ type: replace, replace: b }]`, "find is required"},
+		{"replace without replace", `movies: [{ type: replace, find: a }]`, "replace is required"},
+		{"regex-replace without replace", `movies: [{ type: regex-replace, find: ' \(Extended\)' }]`, "allow-empty"},
+		{"series-prefix-year without series", `movies: [{ type: series-prefix-year, find: Die Hard }]`, "series is required"},
+		{"invalid regex", `movies: [{ type: move-year, find: '^(Batman' }]`, "invalid find regex"},
+		{"unknown section", `films: [{ type: move-year, find: '^Batman' }]`, "section"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseRenameRules([]byte(tc.rules))
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("got %v, want an error containing %q", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestMatchFolderRename(t *testing.T) {
+	rules, err := ParseRenameRules([]byte(`
+movies:
+  - { type: move-year, find: '^Die Hard' }
+  - { type: replace, find: 'The Terminator (1984)', replace: 'Terminator (1984) - The Terminator' }
+  - { type: regex-replace, find: '^Star Wars Episode (\w+) - (.*) \((\d{4})\)$', replace: 'Star Wars ($3) $1 - $2' }
+  - { type: regex-replace, find: ' \[Remastered\]', replace: '', allow-empty: true }
+  - { type: prefix-strip, find: 'Disney''s ' }
+  - { type: series-prefix-year, find: '^Live Free or Die Hard', series: Die Hard }
+standup: []
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	old := folderRenames
+	folderRenames = rules
+	t.Cleanup(func() { folderRenames = old })
+
+	cases := []struct {
+		libType LibraryType
+		folder  string
+		want    string // "" for no rename
+		rule    string
+	}{
+		{LibraryTypeMovies, "Die Hard 2 (1990)", "Die Hard (1990) 2", "move-year /^Die Hard/"},
+		{LibraryTypeMovies, "The Terminator (1984)", "Terminator (1984) - The Terminator", `replace "The Terminator (1984)" -> "Terminator (1984) - The Terminator"`},
+		{LibraryTypeMovies, "The Terminator (1985)", "", ""},
+		{LibraryTypeMovies, "Star Wars Episode IV - A New Hope (1977)", "Star Wars (1977) IV - A New Hope", ""},
+		{LibraryTypeMovies, "Brazil (1985) [Remastered]", "Brazil (1985)", ""},
+		{LibraryTypeMovies, "Disney's Aladdin (1992)", "Aladdin (1992)", `prefix-strip "Disney's "`},
+		{LibraryTypeMovies, "Live Free or Die Hard (2007)", "Die Hard (2007) - Live Free or Die Hard", ""},
+		{LibraryTypeMovies, "Heat (1995)", "", ""},
+		{LibraryTypeStandup, "Jim Jefferies - Freedumb (2016)", "Jim Jefferies (2016) - Freedumb", "standup year move (built in)"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.folder, func(t *testing.T) {
+			got, rule, err := MatchFolderRename(tc.libType, tc.folder)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.want == "" {
+				if got != nil {
+					t.Errorf("got %q, want no rename", *got)
+				}
+				return
+			}
+			if got == nil || *got != tc.want {
+				t.Errorf("got %v, want %q", got, tc.want)
+			}
+			if tc.rule != "" && rule != tc.rule {
+				t.Errorf("rule: got %q, want %q", rule, tc.rule)
+			}
+		})
+	}
+
+	// the series prefix needs a year to put after the series
+	if _, _, err := MatchFolderRename(LibraryTypeMovies, "Live Free or Die Hard"); err == nil {
+		t.Error("expected an error for a series-prefix-year match without a year")
+	}
+}
+
+func TestDefaultRenameRules(t *testing.T) {
+	if _, err := ParseRenameRules(defaultRenameRules); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,217 @@
+package content
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SeasonMatcher recognises a season folder name and returns its season number, and year if the name has one
+type SeasonMatcher interface {
+	MatchSeason(folder string) (season, year int, ok bool)
+}
+
+// EpisodeMatcher recognises an episode file name and returns the episodes in it
+type EpisodeMatcher interface {
+	MatchEpisode(file string) (EpisodeMatch, bool)
+}
+
+// EpisodeMatch is what an episode matcher found in a file name
+type EpisodeMatch struct {
+	Episodes []int  // every episode in the file, the first is the episode's number
+	Aired    string // YYYY-MM-DD for daily episodes
+}
+
+// SeasonMatcherFunc makes a function a SeasonMatcher
+type SeasonMatcherFunc func(folder string) (int, int, bool)
+
+func (f SeasonMatcherFunc) MatchSeason(folder string) (int, int, bool) {
+	return f(folder)
+}
+
+// EpisodeMatcherFunc makes a function an EpisodeMatcher
+type EpisodeMatcherFunc func(file string) (EpisodeMatch, bool)
+
+func (f EpisodeMatcherFunc) MatchEpisode(file string) (EpisodeMatch, bool) {
+	return f(file)
+}
+
+var (
+	librarySeasonRegex  = regexp.MustCompile(`.* - s(\d+)(?: \((\d*)\))?`)
+	namedSeasonRegex    = regexp.MustCompile(`(?i)^(?:.*[ ._-])?season[ ._-]?(\d{1,4})(?: \((\d{4})\))?$`)
+	shortSeasonRegex    = regexp.MustCompile(`(?i)^(?:.*[ ._-])?s(\d{1,4})(?: \((\d{4})\))?$`)
+	specialsSeasonRegex = regexp.MustCompile(`(?i)^(?:.*[ ._-])?specials?$`)
+	yearSeasonRegex     = regexp.MustCompile(`^((?:19|20)\d{2})$`)
+
+	libraryEpisodeRegex = regexp.MustCompile(`.* - (\d+)x(\d+)(?:([-+])(\d+))? - .*`)
+	sxxeyyEpisodeRegex  = regexp.MustCompile(`(?i)\bS(\d{1,4})[ ._-]?E(\d{1,3})((?:-?E\d{1,3})*)(?:-(\d{1,3}))?\b`)
+	extraEpisodeRegex   = regexp.MustCompile(`(?i)(-?)E(\d{1,3})`)
+	crossEpisodeRegex   = regexp.MustCompile(`(?i)\b(\d{1,2})x(\d{2,3})(?:-(\d{2,3}))?\b`)
+	dailyEpisodeRegex   = regexp.MustCompile(`\b((?:19|20)\d{2})[-. ](\d{2})[-. ](\d{2})\b`)
+)
+
+// DefaultSeasonMatchers are tried in order until one matches
+var DefaultSeasonMatchers = []SeasonMatcher{
+	// Show - s01, Show - s01 (2021)
+	SeasonMatcherFunc(func(folder string) (int, int, bool) {
+		return seasonFromMatch(librarySeasonRegex.FindStringSubmatch(folder))
+	}),
+	// Season 1, Show Season 01 (2021)
+	SeasonMatcherFunc(func(folder string) (int, int, bool) {
+		return seasonFromMatch(namedSeasonRegex.FindStringSubmatch(folder))
+	}),
+	// S01, Show.S02
+	SeasonMatcherFunc(func(folder string) (int, int, bool) {
+		return seasonFromMatch(shortSeasonRegex.FindStringSubmatch(folder))
+	}),
+	// Specials is season 0
+	SeasonMatcherFunc(func(folder string) (int, int, bool) {
+		return 0, 0, specialsSeasonRegex.MatchString(folder)
+	}),
+	// 2024, the season of a daily show is its year
+	SeasonMatcherFunc(func(folder string) (int, int, bool) {
+		m := yearSeasonRegex.FindStringSubmatch(folder)
+		if m == nil {
+			return 0, 0, false
+		}
+		season, _ := strconv.Atoi(m[1])
+		return season, 0, true
+	}),
+}
+
+// DefaultEpisodeMatchers are tried in order until one matches, the library's own format first
+var DefaultEpisodeMatchers = []EpisodeMatcher{
+	EpisodeMatcherFunc(matchLibraryEpisode),
+	EpisodeMatcherFunc(matchSxxEyyEpisode),
+	EpisodeMatcherFunc(matchCrossEpisode),
+	EpisodeMatcherFunc(matchDailyEpisode),
+}
+
+var (
+	seasonMatchers  = DefaultSeasonMatchers
+	episodeMatchers = DefaultEpisodeMatchers
+)
+
+// SetSeasonMatchers replaces how season folders are recognised, nil restores the defaults
+func SetSeasonMatchers(m []SeasonMatcher) {
+	if m == nil {
+		m = DefaultSeasonMatchers
+	}
+	seasonMatchers = m
+}
+
+// SetEpisodeMatchers replaces how episode files are recognised, nil restores the defaults
+func SetEpisodeMatchers(m []EpisodeMatcher) {
+	if m == nil {
+		m = DefaultEpisodeMatchers
+	}
+	episodeMatchers = m
+}
+
+// MatchSeason returns the season number and year of a season folder name from the first matcher that recognises it
+func MatchSeason(folder string) (int, int, bool) {
+	for _, m := range seasonMatchers {
+		if season, year, ok := m.MatchSeason(folder); ok {
+			return season, year, true
+		}
+	}
+	return 0, 0, false
+}
+
+// MatchEpisode returns the episodes in an episode file name from the first matcher that recognises it
+func MatchEpisode(file string) (EpisodeMatch, bool) {
+	for _, m := range episodeMatchers {
+		if match, ok := m.MatchEpisode(file); ok {
+			return match, true
+		}
+	}
+	return EpisodeMatch{}, false
+}
+
+func seasonFromMatch(m []string) (int, int, bool) {
+	if m == nil {
+		return 0, 0, false
+	}
+
+	season, _ := strconv.Atoi(m[1])
+	year := 0
+	if len(m) > 2 && m[2] != "" {
+		year, _ = strconv.Atoi(m[2])
+	}
+	return season, year, true
+}
+
+// matchLibraryEpisode matches Show - 01x01 - Title, 01x01-03 is a range and 01x01+03 is just the two episodes
+func matchLibraryEpisode(file string) (EpisodeMatch, bool) {
+	m := libraryEpisodeRegex.FindStringSubmatch(file)
+	if m == nil {
+		return EpisodeMatch{}, false
+	}
+
+	episode, _ := strconv.Atoi(m[2])
+	episodes := []int{episode}
+	if end, err := strconv.Atoi(m[4]); err == nil && end > episode {
+		if m[3] == "-" {
+			episodes = episodeRange(m[2], m[4])
+		} else {
+			episodes = append(episodes, end)
+		}
+	}
+	return EpisodeMatch{Episodes: episodes}, true
+}
+
+// matchSxxEyyEpisode matches S01E02, S01E01E02 (each episode listed) and S01E01-E03 or S01E01-03 (a range)
+func matchSxxEyyEpisode(file string) (EpisodeMatch, bool) {
+	m := sxxeyyEpisodeRegex.FindStringSubmatch(file)
+	if m == nil {
+		return EpisodeMatch{}, false
+	}
+
+	first, _ := strconv.Atoi(m[2])
+	episodes := []int{first}
+	for _, e := range extraEpisodeRegex.FindAllStringSubmatch(m[3], -1) {
+		n, _ := strconv.Atoi(e[2])
+		last := episodes[len(episodes)-1]
+		if n <= last {
+			continue
+		}
+		if e[1] == "-" {
+			episodes = append(episodes, episodeRange(strconv.Itoa(last), e[2])[1:]...)
+		} else {
+			episodes = append(episodes, n)
+		}
+	}
+	if m[4] != "" {
+		last := episodes[len(episodes)-1]
+		if n, _ := strconv.Atoi(m[4]); n > last {
+			episodes = append(episodes, episodeRange(strconv.Itoa(last), m[4])[1:]...)
+		}
+	}
+
+	return EpisodeMatch{Episodes: episodes}, true
+}
+
+// matchCrossEpisode matches 1x02 and 1x02-03 without the library's dashes around it
+func matchCrossEpisode(file string) (EpisodeMatch, bool) {
+	m := crossEpisodeRegex.FindStringSubmatch(file)
+	if m == nil {
+		return EpisodeMatch{}, false
+	}
+	return EpisodeMatch{Episodes: episodeRange(m[2], m[3])}, true
+}
+
+// matchDailyEpisode matches a date for daily shows, the episode number is the month and day ie 2024-03-15 is 315 so
+// episodes sort by date within the season, a year folder ie 2024 or Season 2024
+func matchDailyEpisode(file string) (EpisodeMatch, bool) {
+	m := dailyEpisodeRegex.FindStringSubmatch(file)
+	if m == nil {
+		return EpisodeMatch{}, false
+	}
+
+	month, _ := strconv.Atoi(m[2])
+	day, _ := strconv.Atoi(m[3])
+	if month < 1 || month > 12 || day < 1 || day > 31 {
+		return EpisodeMatch{}, false
+	}
+	return EpisodeMatch{Episodes: []int{month*100 + day}, Aired: strings.Join(m[1:4], "-")}, true
+}
@@ -0,0 +1,100 @@
+package content
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMatchSeason(t *testing.T) {
+	cases := []struct {
+		folder string
+		season int
+		year   int
+		ok     bool
+	}{
+		{"Show - s01", 1, 0, true},
+		{"Show - s02 (2021)", 2, 2021, true},
+		{"Season 1", 1, 0, true},
+		{"Season 03 (2019)", 3, 2019, true},
+		{"Show.Season.2", 2, 0, true},
+		{"S04", 4, 0, true},
+		{"Show S05", 5, 0, true},
+		{"Specials", 0, 0, true},
+		{"specials", 0, 0, true},
+		{"Season 2024", 2024, 0, true},
+		{"2024", 2024, 0, true},
+		{"Show Specials", 0, 0, true},
+		{"Show.Specials", 0, 0, true},
+		{"1080p", 0, 0, false},
+		{"extras", 0, 0, false},
+		{"Behind the Scenes", 0, 0, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.folder, func(t *testing.T) {
+			season, year, ok := MatchSeason(tc.folder)
+			if ok != tc.ok || season != tc.season || year != tc.year {
+				t.Errorf("got %d, %d, %t, want %d, %d, %t", season, year, ok, tc.season, tc.year, tc.ok)
+			}
+		})
+	}
+}
+
+func TestMatchEpisode(t *testing.T) {
+	cases := []struct {
+		file     string
+		episodes []int
+		aired    string
+	}{
+		{"Show - 01x02 - Title.mkv", []int{2}, ""},
+		{"Show - 01x03-05 - Triple.mkv", []int{3, 4, 5}, ""},
+		{"Show - 01x06+08 - Pair.mkv", []int{6, 8}, ""},
+		{"Show.S01E02.1080p.WEB-DL.mkv", []int{2}, ""},
+		{"Show S01E01E02 Double.mkv", []int{1, 2}, ""},
+		{"Show.S01E01-E03.mkv", []int{1, 2, 3}, ""},
+		{"Show.S01E04-06.mkv", []int{4, 5, 6}, ""},
+		{"Show.S01E07-720p.mkv", []int{7}, ""},
+		{"Show.S01E01E03E05.mkv", []int{1, 3, 5}, ""},
+		{"Show.S01.E08.mkv", []int{8}, ""},
+		{"Show 1x02 Title.mkv", []int{2}, ""},
+		{"Show.1x02-03.mkv", []int{2, 3}, ""},
+		{"Show - 2024-03-15 - Guest.mkv", []int{315}, "2024-03-15"},
+		{"Show.2024.11.05.1080p.mkv", []int{1105}, "2024-11-05"},
+		{"Show 1920x1080.mkv", nil, ""},
+		{"Show - Behind the Scenes.mkv", nil, ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.file, func(t *testing.T) {
+			match, ok := MatchEpisode(tc.file)
+			if ok != (tc.episodes != nil) {
+				t.Fatalf("matched: got %t, want %t", ok, tc.episodes != nil)
+			}
+			if !reflect.DeepEqual(match.Episodes, tc.episodes) || match.Aired != tc.aired {
+				t.Errorf("got %v %q, want %v %q", match.Episodes, match.Aired, tc.episodes, tc.aired)
+			}
+		})
+	}
+}
+
+func TestSetEpisodeMatchers(t *testing.T) {
+	// absolute numbering ie anime releases
+	absolute := EpisodeMatcherFunc(func(file string) (EpisodeMatch, bool) {
+		var n int
+		if _, err := fmt.Sscanf(filepath.Base(file), "Show - %d.mkv", &n); err != nil {
+			return EpisodeMatch{}, false
+		}
+		return EpisodeMatch{Episodes: []int{n}}, true
+	})
+	SetEpisodeMatchers([]EpisodeMatcher{absolute})
+	t.Cleanup(func() { SetEpisodeMatchers(nil) })
+
+	if m, ok := MatchEpisode("Show - 12.mkv"); !ok || !reflect.DeepEqual(m.Episodes, []int{12}) {
+		t.Errorf("got %v, %t", m.Episodes, ok)
+	}
+	if _, ok := MatchEpisode("Show - 01x02 - Title.mkv"); ok {
+		t.Errorf("the default matchers should be replaced")
+	}
+}
@@ -1,9 +1,9 @@
 package content
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
-	"regexp"
 	"strconv"
 	"strings"
 	"sync"
@@ -25,20 +25,25 @@ type Season struct {
 type Episode struct {
 	Season         int
 	Number         int
-	EpisodeNumbers []int // list of episodes this file represents
+	EpisodeNumbers []int  // list of episodes this file represents
+	Aired          string // YYYY-MM-DD for daily episodes, their number is the month and day
 
 	Videos []VideoFile
 
 	OtherFiles []string // we want to know about these, so we can move them all, we don't care about dest files?
 }
 
-func GetSeasons(path string) (map[int]Season, error) {
+func GetSeasons(ctx context.Context, path string) (map[int]Season, error) {
 	// for each folder in source path
 	srcFolders, err := ktio.ListFolders(path)
 	if err != nil {
 		return nil, fmt.Errorf("error listing source folders: %w", err)
 	}
 
+	// the first error stops the other seasons waiting to probe
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	var wg sync.WaitGroup
 	var firstErr error
 	var errOnce sync.Once
@@ -55,38 +60,33 @@ func GetSeasons(path string) (map[int]Season, error) {
 				Path: f,
 			}
 
-			// Updated regex to match folders in the format "SeriesSource Name - s##", "SeriesSource Name - s## (####)", or "SeriesSource Name - s## ()"
-			isSeason, err := regexp.MatchString(`.* - s(\d+)(?: \((\d*)\))?`, f)
-			if err != nil {
-				errOnce.Do(func() { firstErr = fmt.Errorf("error matching season folder: %w", err) })
-				return
-			}
+			season, year, isSeason := MatchSeason(filepath.Base(f))
 			if !isSeason {
 				c.Printf("    <darkGray>SKIP:</> folder doesn't match season format: %s\n", filepath.Base(f))
 				return // Skip folders not matching the format
 			}
-
-			// get season number and year (if present) from folder name
-			re := regexp.MustCompile(`.* - s(\d+)(?: \((\d*)\))?`)
-			matches := re.FindStringSubmatch(f)
-			s.Number, _ = strconv.Atoi(matches[1]) // Convert season number to int
-
-			if len(matches) > 2 && matches[2] != "" {
-				s.Year, _ = strconv.Atoi(matches[2]) // Convert year to int, if present
-			}
+			s.Number, s.Year = season, year
 
 			// Get the episodes in a season
-			if err := s.LoadEpisodes(); err != nil {
-				errOnce.Do(func() { firstErr = fmt.Errorf("error loading episodes: %w", err) })
+			if err := s.LoadEpisodes(ctx); err != nil {
+				errOnce.Do(func() {
+					firstErr = fmt.Errorf("error loading episodes: %w", err)
+					cancel()
+				})
 				return
 			}
 
 			// Lock the mutex to prevent concurrent writes to the seasons map
 			mutex.Lock()
 
-			// warn if season already exists (don't error - just use the first one found)
+			// warn if season already exists (don't error - just use the preferred folder)
 			if existing, ok := seasons[s.Number]; ok {
-				c.Printf("    <yellow>WARNING:</> season %d already exists (%s vs %s), using first\n", s.Number, existing.Path, s.Path)
+				keep := existing
+				if preferSeason(s, existing) {
+					keep = s
+				}
+				c.Printf("    <yellow>WARNING:</> season %d already exists (%s vs %s), using %s\n", s.Number, existing.Path, s.Path, filepath.Base(keep.Path))
+				seasons[s.Number] = keep
 			} else {
 				seasons[s.Number] = s
 			}
@@ -102,7 +102,17 @@ func GetSeasons(path string) (map[int]Season, error) {
 	return seasons, nil
 }
 
-func (s *Season) LoadEpisodes() error {
+// preferSeason returns true if s1 should be used over s2 when two folders are the same season, the library's own
+// format (Show - s01) first and then the first folder by name so the choice doesn't depend on which loads first
+func preferSeason(s1, s2 Season) bool {
+	lib1, lib2 := librarySeasonRegex.MatchString(filepath.Base(s1.Path)), librarySeasonRegex.MatchString(filepath.Base(s2.Path))
+	if lib1 != lib2 {
+		return lib1
+	}
+	return s1.Path < s2.Path
+}
+
+func (s *Season) LoadEpisodes(ctx context.Context) error {
 	// for each file in season path
 	files, err := ktio.ListFiles(s.Path)
 	if err != nil {
@@ -111,38 +121,15 @@ func (s *Season) LoadEpisodes() error {
 
 	s.Episodes = make(map[int]*Episode) // Initialise the Episodes map
 
-	// match 01x01, 01x01-02, 01x01+02
-	episodeRegex := regexp.MustCompile(`.* - (\d+)x(\d+)(?:([-+])(\d+))? - .*`)
+	// video files are probed together once all episodes are known
+	var videoFiles []string
+	var videoEpisodes []*Episode
 
 	for _, file := range files {
-		// Check if the file name matches the episode format
-		if matches := episodeRegex.FindStringSubmatch(file); matches != nil {
-			episodeNumber, err := strconv.Atoi(matches[2]) // Convert episode number to int
-			if err != nil {
-				return fmt.Errorf("error parsing episode number: %w", err)
-			}
-
-			// check for multi-episode
-			episodeNumbers := []int{episodeNumber}
-			if len(matches) > 4 && matches[4] != "" {
-				separator := matches[3]
-				endEpisodeNumber, err := strconv.Atoi(matches[4])
-				if err != nil {
-					return fmt.Errorf("error parsing end episode number: %w", err)
-				}
-
-				if endEpisodeNumber > episodeNumber {
-					if separator == "-" {
-						// range: add all intermediate episodes
-						for i := episodeNumber + 1; i <= endEpisodeNumber; i++ {
-							episodeNumbers = append(episodeNumbers, i)
-						}
-					} else {
-						// plus: add just the end episode (assuming "01+03" means 1 and 3)
-						episodeNumbers = append(episodeNumbers, endEpisodeNumber)
-					}
-				}
-			}
+		// Check if the file name matches an episode format
+		if match, ok := MatchEpisode(filepath.Base(file)); ok {
+			episodeNumber := match.Episodes[0]
+			episodeNumbers := match.Episodes
 
 			// find or create episode
 			var episode *Episode
@@ -160,6 +147,7 @@ func (s *Season) LoadEpisodes() error {
 					Season:         s.Number,
 					Number:         episodeNumber,
 					EpisodeNumbers: episodeNumbers,
+					Aired:          match.Aired,
 					OtherFiles:     []string{},
 					Videos:         []VideoFile{},
 				}
@@ -167,12 +155,8 @@ func (s *Season) LoadEpisodes() error {
 
 			// Determine if the file is a video file or another type (like subtitles)
 			if IsVideoFile(file) {
-				v, err := VideoFor(file)
-				if err != nil {
-					return fmt.Errorf("error loading source video: %w", err)
-				}
-
-				episode.Videos = append(episode.Videos, *v)
+				videoFiles = append(videoFiles, file)
+				videoEpisodes = append(videoEpisodes, episode)
 			} else {
 				episode.OtherFiles = append(episode.OtherFiles, file)
 			}
@@ -184,11 +168,21 @@ func (s *Season) LoadEpisodes() error {
 		}
 	}
 
+	videos, err := scheduler.ProbeAll(ctx, videoFiles)
+	if err != nil {
+		return fmt.Errorf("error loading source video: %w", err)
+	}
+	for i, v := range videos {
+		// every subtitle file matched to the episode belongs to its videos
+		v.SidecarSubtitles = sidecarsFor(v.Path, videoEpisodes[i].OtherFiles, true)
+		videoEpisodes[i].Videos = append(videoEpisodes[i].Videos, v)
+	}
+
 	return nil
 }
 
 func (s *Season) MoveFolder(prompt bool, indent int, dstPath string) error {
-	return ktio.RunCommand(indent, prompt, "mv", "-v", s.Path, dstPath)
+	return ktio.Move(indent, prompt, s.Path, dstPath)
 }
 
 func (e *Episode) MoveFiles(prompt bool, indent int, dstPath string) error {
@@ -198,7 +192,7 @@ func (e *Episode) MoveFiles(prompt bool, indent int, dstPath string) error {
 	}
 
 	// move video file
-	if err := ktio.RunCommand(indent, prompt, "mv", "-v", e.Videos[0].Path, dstPath); err != nil {
+	if err := ktio.Move(indent, prompt, e.Videos[0].Path, dstPath); err != nil {
 		return fmt.Errorf("error moving video: %w", err)
 	}
 
@@ -224,7 +218,7 @@ func (e *Episode) MoveExtras(prompt bool, indent int, dstPath string) error {
 			padLen = 0
 		}
 		fmt.Printf("%s --> ", strings.Repeat(" ", padLen))
-		if err := ktio.RunCommand(indent, prompt, "mv", "-v", file, dstPath); err != nil {
+		if err := ktio.Move(indent, prompt, file, dstPath); err != nil {
 			c.Printf("   <red>ERROR:</> moving other file: %s\n", err)
 		}
 	}
@@ -234,8 +228,22 @@ func (e *Episode) MoveExtras(prompt bool, indent int, dstPath string) error {
 
 func (e *Episode) DeleteVideoFiles(prompt bool) {
 	for _, v := range e.Videos {
-		if err := ktio.RunCommand(0, prompt, "rm", "-v", v.Path); err != nil {
+		if err := ktio.Remove(0, prompt, v.Path); err != nil {
 			c.Printf("   <red>ERROR:</> deleting destination video: %s\n", err)
 		}
 	}
 }
+
+// nfo returns the episode's own nfo file, nil if there isn't one or it can't be read
+func (e *Episode) nfo() *NfoFile {
+	for _, f := range e.OtherFiles {
+		if strings.ToLower(filepath.Ext(f)) != ".nfo" {
+			continue
+		}
+
+		if nfo, err := ReadNfo(f); err == nil {
+			return nfo
+		}
+	}
+	return nil
+}
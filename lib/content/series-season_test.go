@@ -0,0 +1,206 @@
+package content
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/katbyte/go-ingest-media/lib/content/contenttest"
+)
+
+func TestGetSeasons(t *testing.T) {
+	fv := contenttest.New(t, t.TempDir())
+
+	fv.Add("Show - s01/Show - 01x01 - Pilot.mkv", "hevc-1080p.mkv", 100)
+	fv.Add("Show - s01/Show - 01x01 - Pilot.en.srt", "", 10)
+	fv.Add("Show - s01/Show - 01x02 - Second.mkv", "h264-1080p.mkv", 100)
+	fv.Add("Show - s01/Show - 01x02 - Second.avi", "h264-480p.avi", 100)
+	fv.Add("Show - s01/Show - 01x03-05 - Triple.mkv", "hevc-1080p.mkv", 100)
+	fv.Add("Show - s01/Show - 01x06+08 - Pair.mkv", "hevc-1080p.mkv", 100)
+	fv.Add("Show - s01/Show - 01x09 - Unprobed.mkv", "", 100)
+	fv.Add("Show - s01/notes.txt", "", 10)
+	fv.Add("Show - s02 (2021)/Show - 02x01 - Return.mkv", "hevc-2160p.mkv", 100)
+	fv.Add("extras/Show - Behind the Scenes.mkv", "", 100)
+
+	seasons, err := GetSeasons(fixtureContext(), fv.Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := sortedKeys(seasons); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Fatalf("seasons: got %v, want [1 2]", got)
+	}
+	if seasons[2].Year != 2021 {
+		t.Errorf("season 2 year: got %d, want 2021", seasons[2].Year)
+	}
+
+	cases := []struct {
+		season      int
+		episode     int
+		numbers     []int
+		resolutions []string
+		others      []string
+	}{
+		{1, 1, []int{1}, []string{"1920x1080"}, []string{"Show - 01x01 - Pilot.en.srt"}},
+		{1, 2, []int{2}, []string{"1920x1080", "640x480"}, nil},
+		{1, 3, []int{3, 4, 5}, []string{"1920x1080"}, nil},
+		{1, 4, []int{3, 4, 5}, []string{"1920x1080"}, nil},
+		{1, 5, []int{3, 4, 5}, []string{"1920x1080"}, nil},
+		{1, 6, []int{6, 8}, []string{"1920x1080"}, nil},
+		{1, 7, nil, nil, nil},
+		{1, 8, []int{6, 8}, []string{"1920x1080"}, nil},
+		{1, 9, []int{9}, []string{"UNKNOWN"}, nil},
+		{2, 1, []int{1}, []string{"3840x2160"}, nil},
+	}
+
+	for _, tc := range cases {
+		ep := seasons[tc.season].Episodes[tc.episode]
+		if tc.numbers == nil {
+			if ep != nil {
+				t.Errorf("%dx%d: expected no episode, got %v", tc.season, tc.episode, ep.EpisodeNumbers)
+			}
+			continue
+		}
+		if ep == nil {
+			t.Errorf("%dx%d: missing", tc.season, tc.episode)
+			continue
+		}
+
+		if !reflect.DeepEqual(ep.EpisodeNumbers, tc.numbers) {
+			t.Errorf("%dx%d episode numbers: got %v, want %v", tc.season, tc.episode, ep.EpisodeNumbers, tc.numbers)
+		}
+
+		var resolutions []string
+		for _, v := range ep.Videos {
+			resolutions = append(resolutions, v.Resolution)
+		}
+		sort.Strings(resolutions)
+		if !reflect.DeepEqual(resolutions, tc.resolutions) {
+			t.Errorf("%dx%d videos: got %v, want %v", tc.season, tc.episode, resolutions, tc.resolutions)
+		}
+
+		var others []string
+		for _, f := range ep.OtherFiles {
+			others = append(others, filepath.Base(f))
+		}
+		if !reflect.DeepEqual(others, tc.others) {
+			t.Errorf("%dx%d other files: got %v, want %v", tc.season, tc.episode, others, tc.others)
+		}
+	}
+
+	// every number of a multi episode file shares the same episode so it is only processed once
+	if seasons[1].Episodes[3] != seasons[1].Episodes[5] {
+		t.Errorf("1x03 and 1x05 should be the same episode")
+	}
+}
+
+func TestGetSeasonsDuplicateSeason(t *testing.T) {
+	fv := contenttest.New(t, t.TempDir())
+
+	fv.Add("Show - s01/Show - 01x01 - Pilot.mkv", "hevc-1080p.mkv", 100)
+	fv.Add("Show - s01 (2020)/Show - 01x01 - Pilot (Remastered).mkv", "hevc-2160p.mkv", 100)
+	fv.Add("Season 1/Show.S01E01.mkv", "hevc-2160p.mkv", 100)
+	fv.Add("Show - s00/Show - 00x01 - Christmas.mkv", "hevc-1080p.mkv", 100)
+	fv.Add("specials/Show - 00x02 - Easter.mkv", "hevc-1080p.mkv", 100)
+
+	// whichever season loads first, the library format and then the first by name is kept
+	for i := 0; i < 10; i++ {
+		seasons, err := GetSeasons(fixtureContext(), fv.Root)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(seasons) != 2 {
+			t.Fatalf("seasons: got %d, want one of each of the duplicates", len(seasons))
+		}
+		if got := filepath.Base(seasons[1].Path); got != "Show - s01" {
+			t.Errorf("season 1: got %s, want Show - s01", got)
+		}
+		if got := filepath.Base(seasons[0].Path); got != "Show - s00" {
+			t.Errorf("season 0: got %s, want Show - s00", got)
+		}
+	}
+}
+
+func TestGetSeasonsCancelled(t *testing.T) {
+	fv := contenttest.New(t, t.TempDir())
+	fv.Add("Show - s01/Show - 01x01 - Pilot.mkv", "hevc-1080p.mkv", 100)
+
+	ctx, cancel := context.WithCancel(fixtureContext())
+	cancel()
+
+	if _, err := GetSeasons(ctx, fv.Root); err == nil {
+		t.Fatal("expected an error from a cancelled context")
+	}
+}
+
+func TestGetSeasonsMatchers(t *testing.T) {
+	fv := contenttest.New(t, t.TempDir())
+
+	fv.Add("Season 1/Show.S01E01.1080p.mkv", "hevc-1080p.mkv", 100)
+	fv.Add("Season 1/Show.S01E02E03.1080p.mkv", "hevc-1080p.mkv", 100)
+	fv.Add("S02/Show 2x01 Return.mkv", "h264-1080p.mkv", 100)
+	fv.Add("Specials/Show.S00E01.Christmas.mkv", "hevc-1080p.mkv", 100)
+	fv.Add("Season 2024/Show - 2024-03-15 - Guest.mkv", "hevc-1080p.mkv", 100)
+
+	seasons, err := GetSeasons(fixtureContext(), fv.Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := sortedKeys(seasons); !reflect.DeepEqual(got, []int{0, 1, 2, 2024}) {
+		t.Fatalf("seasons: got %v, want [0 1 2 2024]", got)
+	}
+	if ep := seasons[1].Episodes[3]; ep == nil || !reflect.DeepEqual(ep.EpisodeNumbers, []int{2, 3}) {
+		t.Errorf("1x03: got %v", ep)
+	}
+	if ep := seasons[2].Episodes[1]; ep == nil || len(ep.Videos) != 1 {
+		t.Errorf("2x01: got %v", ep)
+	}
+	if ep := seasons[0].Episodes[1]; ep == nil {
+		t.Errorf("0x01: missing")
+	}
+	if ep := seasons[2024].Episodes[315]; ep == nil || ep.Aired != "2024-03-15" {
+		t.Errorf("2024-03-15: got %v", ep)
+	}
+}
+
+func TestLoadSeasonsSpecials(t *testing.T) {
+	fv := contenttest.New(t, t.TempDir())
+
+	fv.Add("Show (2010)/Show - s01/Show - 01x01 - Pilot.mkv", "hevc-1080p.mkv", 100)
+	fv.Add("Show (2010)/Specials/Show - 00x01 - Christmas.mkv", "hevc-1080p.mkv", 100)
+	fv.Add("Show (2010)/Specials/Show - Bloopers.mkv", "", 100)
+
+	s, err := SeriesFor(&Library{Path: fv.Root, Type: LibraryTypeSeries}, "Show (2010)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.LoadSeasons(fixtureContext()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := s.Seasons[0].Episodes[1]; !ok {
+		t.Errorf("the numbered special should be a season 0 episode")
+	}
+
+	// only the unnumbered special is left to move as a special file
+	var specials []string
+	for _, f := range s.SpecialFiles {
+		specials = append(specials, filepath.Base(f))
+	}
+	if !reflect.DeepEqual(specials, []string{"Show - Bloopers.mkv"}) {
+		t.Errorf("special files: got %v", specials)
+	}
+}
+
+func sortedKeys(m map[int]Season) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
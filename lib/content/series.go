@@ -1,7 +1,9 @@
 package content
 
 import (
+	"context"
 	"fmt"
+	"path/filepath"
 
 	c "github.com/gookit/color"
 	"github.com/katbyte/go-ingest-media/lib/ktio"
@@ -19,6 +21,7 @@ type Series struct {
 
 	ExtraFiles   []string
 	SpecialFiles []string
+	SpecialsPath string // the specials folder, whatever its case or name, "" if there is none
 }
 
 // SeriesFor creates a Series from a folder in the given library
@@ -36,9 +39,9 @@ func SeriesFor(lib *Library, folder string) (*Series, error) {
 }
 
 // LoadSeasons loads season info for this series
-func (s *Series) LoadSeasons() error {
+func (s *Series) LoadSeasons(ctx context.Context) error {
 	var err error
-	s.Seasons, err = GetSeasons(s.Path())
+	s.Seasons, err = GetSeasons(ctx, s.Path())
 	if err != nil {
 		c.Printf("    <yellow>WARNING:</> error loading seasons: %s\n", err)
 	}
@@ -51,22 +54,64 @@ func (s *Series) LoadSeasons() error {
 		}
 	}
 
-	if exists := ktio.PathExists(s.Path() + "/specials"); exists {
-		s.SpecialFiles, err = ktio.ListFiles(s.Path() + "/specials")
+	s.SpecialsPath = s.specialsFolder()
+	if specialsPath := s.SpecialsPath; specialsPath != "" {
+		s.SpecialFiles, err = ktio.ListFiles(specialsPath)
 		if err != nil {
 			return fmt.Errorf("error listing special files: %w", err)
 		}
+
+		// numbered specials are loaded as season 0 episodes, only the rest are special files
+		if specials, ok := s.Seasons[0]; ok && specials.Path == specialsPath {
+			s.SpecialFiles = unmatchedFiles(s.SpecialFiles, specials)
+		}
 	}
 
 	return nil
 }
 
+// specialsFolder returns the series' specials folder (ie specials, Specials or Show Specials), "" if it has none
+func (s *Series) specialsFolder() string {
+	folders, err := ktio.ListFolders(s.Path())
+	if err != nil {
+		return ""
+	}
+
+	for _, f := range folders {
+		if specialsSeasonRegex.MatchString(filepath.Base(f)) {
+			return f
+		}
+	}
+	return ""
+}
+
 // LoadDestSeasons loads season info from a destination path (for import comparison)
-func (s *Series) LoadDestSeasons(destPath string) error {
+func (s *Series) LoadDestSeasons(ctx context.Context, destPath string) error {
 	var err error
-	s.DstSeasons, err = GetSeasons(destPath)
+	s.DstSeasons, err = GetSeasons(ctx, destPath)
 	if err != nil {
 		c.Printf("    <yellow>WARNING:</> error loading destination seasons: %s\n", err)
 	}
 	return nil
 }
+
+// unmatchedFiles returns the files that are not part of any episode in the season
+func unmatchedFiles(files []string, season Season) []string {
+	matched := map[string]bool{}
+	for _, e := range season.Episodes {
+		for _, v := range e.Videos {
+			matched[v.Path] = true
+		}
+		for _, f := range e.OtherFiles {
+			matched[f] = true
+		}
+	}
+
+	var unmatched []string
+	for _, f := range files {
+		if !matched[f] {
+			unmatched = append(unmatched, f)
+		}
+	}
+	return unmatched
+}
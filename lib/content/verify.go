@@ -0,0 +1,210 @@
+package content
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/katbyte/go-ingest-media/lib/ktio"
+)
+
+// VerifyProblem is why a video looks corrupt or truncated
+type VerifyProblem string
+
+const (
+	ProblemProbeFailed  VerifyProblem = "probe failed"
+	ProblemNoVideo      VerifyProblem = "no video"
+	ProblemShortRuntime VerifyProblem = "short runtime" // far shorter than the nfo runtime
+	ProblemShortEpisode VerifyProblem = "short episode" // far shorter than the other episodes in the season
+	ProblemBadTail      VerifyProblem = "bad tail"      // the end of the video fails to decode
+)
+
+// VerifyIssue is a problem found with a video
+type VerifyIssue struct {
+	Path    string
+	Problem VerifyProblem
+	Detail  string
+}
+
+func (i VerifyIssue) String() string {
+	if i.Detail == "" {
+		return string(i.Problem)
+	}
+	return string(i.Problem) + ": " + i.Detail
+}
+
+const (
+	nfoRuntimeRatio     = 0.8 // a video shorter than this fraction of the nfo runtime is flagged
+	siblingRuntimeRatio = 0.5 // an episode shorter than this fraction of the season's median is flagged
+	tailSeconds         = 30  // how much of the end of a video is decoded
+)
+
+// how long ffmpeg gets to decode the end of a video before it is killed, a seek on a slow mount or damaged file can hang
+const tailTimeout = 2 * time.Minute
+
+// TailDecoder decodes the end of a video to check it is complete
+type TailDecoder interface {
+	DecodeTail(ctx context.Context, path string, duration float64) error
+}
+
+var tailDecoder TailDecoder = FFmpegTailDecoder{}
+
+// SetTailDecoder replaces how the end of a video is decoded, nil restores ffmpeg
+func SetTailDecoder(d TailDecoder) {
+	if d == nil {
+		d = FFmpegTailDecoder{}
+	}
+	tailDecoder = d
+}
+
+// TailDecoderAvailable returns an error if the end of videos can't be decoded, ie ffmpeg is not installed
+func TailDecoderAvailable() error {
+	if _, ok := tailDecoder.(FFmpegTailDecoder); !ok {
+		return nil
+	}
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return fmt.Errorf("ffmpeg not found: %w", err)
+	}
+	return nil
+}
+
+// FFmpegTailDecoder decodes the last seconds of a video with ffmpeg, any decode error or not decoding a single frame
+// (ie the file is truncated and the seek ran past its end) means it is damaged
+type FFmpegTailDecoder struct{}
+
+func (FFmpegTailDecoder) DecodeTail(ctx context.Context, path string, duration float64) error {
+	ctx, cancel := context.WithTimeout(ctx, tailTimeout)
+	defer cancel()
+
+	start := math.Max(duration-tailSeconds, 0)
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-v", "error", "-nostats", "-progress", "pipe:1",
+		"-ss", strconv.FormatFloat(start, 'f', 3, 64), "-i", path, "-f", "null", "-")
+
+	var out bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("decoding the last %ds: %w", tailSeconds, ctxErr)
+	}
+
+	// ffmpeg carries on past most decode errors so anything logged counts
+	errMsg := strings.TrimSpace(stderr.String())
+	if first, _, found := strings.Cut(errMsg, "\n"); found {
+		errMsg = first
+	}
+	if err != nil {
+		if errMsg == "" {
+			errMsg = "no stderr output"
+		}
+		return fmt.Errorf("%w: %s", err, errMsg)
+	}
+	if errMsg != "" {
+		return fmt.Errorf("decode error: %s", errMsg)
+	}
+	if progressFrames(out.Bytes()) == 0 {
+		return fmt.Errorf("no frames decoded in the last %ds, the file is likely truncated", tailSeconds)
+	}
+
+	return nil
+}
+
+// progressFrames returns the frame count from the last block of ffmpeg's -progress output, 0 if there is none
+func progressFrames(progress []byte) int {
+	frames := 0
+	scanner := bufio.NewScanner(bytes.NewReader(progress))
+	for scanner.Scan() {
+		if v, ok := strings.CutPrefix(scanner.Text(), "frame="); ok {
+			if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+				frames = n
+			}
+		}
+	}
+	return frames
+}
+
+// VerifyVideo checks a video probed, has a video stream, is not far shorter than the nfo runtime (in seconds, 0 if
+// unknown) and optionally that its end decodes
+func VerifyVideo(ctx context.Context, v VideoFile, nfoRuntime float64, decodeTail bool) []VerifyIssue {
+	if v.FFProbeFailed {
+		if v.Resolution == "NO VIDEO" {
+			return []VerifyIssue{{Path: v.Path, Problem: ProblemNoVideo}}
+		}
+		return []VerifyIssue{{Path: v.Path, Problem: ProblemProbeFailed}}
+	}
+
+	var issues []VerifyIssue
+	if nfoRuntime > 0 && v.Duration < nfoRuntime*nfoRuntimeRatio {
+		issues = append(issues, VerifyIssue{
+			Path:    v.Path,
+			Problem: ProblemShortRuntime,
+			Detail:  fmt.Sprintf("%s of %s", formatRuntime(v.Duration), formatRuntime(nfoRuntime)),
+		})
+	}
+
+	if decodeTail && v.Duration > 0 {
+		if err := tailDecoder.DecodeTail(ctx, ktio.RealPath(v.Path), v.Duration); err != nil {
+			issues = append(issues, VerifyIssue{Path: v.Path, Problem: ProblemBadTail, Detail: err.Error()})
+		}
+	}
+
+	return issues
+}
+
+// VerifySiblings flags episodes far shorter than the median of the season, the season needs at least 3 episodes to
+// have a meaningful median
+func VerifySiblings(videos []VideoFile) []VerifyIssue {
+	var durations []float64
+	for _, v := range videos {
+		if !v.FFProbeFailed && v.Duration > 0 {
+			durations = append(durations, v.Duration)
+		}
+	}
+	if len(durations) < 3 {
+		return nil
+	}
+
+	sort.Float64s(durations)
+	median := durations[len(durations)/2]
+	if len(durations)%2 == 0 {
+		median = (durations[len(durations)/2-1] + durations[len(durations)/2]) / 2
+	}
+
+	var issues []VerifyIssue
+	for _, v := range videos {
+		if !v.FFProbeFailed && v.Duration < median*siblingRuntimeRatio {
+			issues = append(issues, VerifyIssue{
+				Path:    v.Path,
+				Problem: ProblemShortEpisode,
+				Detail:  fmt.Sprintf("%s, the season's median is %s", formatRuntime(v.Duration), formatRuntime(median)),
+			})
+		}
+	}
+	return issues
+}
+
+// EpisodeNfoRuntime returns the runtime in seconds from the episode's own nfo file (named after the video), 0 if there
+// isn't one
+func (e *Episode) EpisodeNfoRuntime() float64 {
+	if nfo := e.nfo(); nfo != nil {
+		return nfo.RuntimeSeconds()
+	}
+	return 0
+}
+
+// formatRuntime formats seconds as 1h02m or 45m
+func formatRuntime(seconds float64) string {
+	m := int(math.Round(seconds / 60))
+	if m >= 60 {
+		return fmt.Sprintf("%dh%02dm", m/60, m%60)
+	}
+	return fmt.Sprintf("%dm", m)
+}
@@ -0,0 +1,103 @@
+package content
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/katbyte/go-ingest-media/lib/content/contenttest"
+)
+
+// failingTails fails to decode the end of the named videos
+type failingTails map[string]bool
+
+func (f failingTails) DecodeTail(_ context.Context, path string, _ float64) error {
+	if f[filepath.Base(path)] {
+		return errors.New("Invalid NAL unit size")
+	}
+	return nil
+}
+
+func TestVerifyVideo(t *testing.T) {
+	SetTailDecoder(failingTails{"damaged.mkv": true})
+	t.Cleanup(func() { SetTailDecoder(nil) })
+
+	cases := []struct {
+		name       string
+		file       string
+		fixture    string
+		nfoRuntime float64
+		decode     bool
+		want       []string
+	}{
+		{"fine", "fine.mkv", "hevc-1080p.mkv", 5400, true, nil},
+		{"probe failed", "broken.mkv", "", 0, true, []string{"probe failed"}},
+		{"no video", "audio-only.mkv", "audio-only.mkv", 0, true, []string{"no video"}},
+		{"short of the nfo runtime", "short.mkv", "hevc-1080p.mkv", 120 * 60, false, []string{"short runtime: 1h30m of 2h00m"}},
+		{"close to the nfo runtime", "close.mkv", "hevc-1080p.mkv", 100 * 60, false, nil},
+		{"damaged end", "damaged.mkv", "hevc-1080p.mkv", 0, true, []string{"bad tail: Invalid NAL unit size"}},
+		{"damaged end not decoded", "damaged.mkv", "hevc-1080p.mkv", 0, false, nil},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fv := contenttest.New(t, t.TempDir())
+			v := loadVideo(t, fv.Add(tc.file, tc.fixture, 1024))
+
+			var got []string
+			for _, issue := range VerifyVideo(context.Background(), v, tc.nfoRuntime, tc.decode) {
+				got = append(got, issue.String())
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %q, want %q", got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Errorf("got %q, want %q", got[i], tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestVerifySiblings(t *testing.T) {
+	fv := contenttest.New(t, t.TempDir())
+	e1 := loadVideo(t, fv.Add("Show - 01x01 - One.mkv", "hevc-1080p.mkv", 100))
+	e2 := loadVideo(t, fv.Add("Show - 01x02 - Two.mkv", "h264-1080p.mkv", 100))
+	e3 := loadVideo(t, fv.Add("Show - 01x03 - Three.mkv", "hevc-1080p-truncated.mkv", 100))
+	e4 := loadVideo(t, fv.Add("Show - 01x04 - Four.mkv", "", 100))
+
+	issues := VerifySiblings([]VideoFile{e1, e2, e3, e4})
+	if len(issues) != 1 || issues[0].Path != e3.Path {
+		t.Fatalf("expected only episode 3 to be flagged, got %v", issues)
+	}
+	if got, want := issues[0].String(), "short episode: 20m, the season's median is 1h30m"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// two episodes aren't enough to tell which is wrong
+	if issues := VerifySiblings([]VideoFile{e1, e3}); len(issues) != 0 {
+		t.Errorf("expected no issues for a 2 episode season, got %v", issues)
+	}
+}
+
+func TestProgressFrames(t *testing.T) {
+	cases := []struct {
+		name     string
+		progress string
+		want     int
+	}{
+		{"decoded", "frame=120\nfps=0.0\nprogress=continue\nframe=720\nfps=240.0\nprogress=end\n", 720},
+		{"seek past the end", "frame=0\nfps=0.0\nprogress=end\n", 0},
+		{"no output", "", 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := progressFrames([]byte(tc.progress)); got != tc.want {
+				t.Errorf("got %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
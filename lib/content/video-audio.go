@@ -0,0 +1,136 @@
+package content
+
+import (
+	"sort"
+	"strings"
+)
+
+// audio codecs ranked by quality, lossless first, anything not listed ranks below aac
+var audioCodecRanks = map[string]int{
+	"truehd":    5, // Dolby TrueHD, lossless
+	"dts-hd ma": 5, // DTS-HD Master Audio, lossless
+	"flac":      5, // Free Lossless Audio Codec
+	"pcm":       5, // uncompressed
+	"dts":       4, // DTS core, DTS-ES and DTS-HD High Resolution
+	"eac3":      3, // Dolby Digital Plus
+	"ac3":       2, // Dolby Digital
+	"aac":       1, // Advanced Audio Coding
+}
+
+// audioCodecKey returns the codec name used for ranking, ffprobe reports DTS-HD MA as dts with a profile
+func (s FFProbeStreamAudio) audioCodecKey() string {
+	switch {
+	case s.CodecName == "dts" && strings.Contains(s.Profile, "MA"):
+		return "dts-hd ma"
+	case strings.HasPrefix(s.CodecName, "pcm_"):
+		return "pcm"
+	}
+	return s.CodecName
+}
+
+// CodecRank returns how good the codec is, higher is better and 0 is unknown or worse than aac
+func (s FFProbeStreamAudio) CodecRank() int {
+	return audioCodecRanks[s.audioCodecKey()]
+}
+
+// ObjectBased returns the object audio format (Atmos or DTS:X) of the track or "" if it has none
+func (s FFProbeStreamAudio) ObjectBased() string {
+	switch {
+	case strings.Contains(s.Profile, "Atmos") || strings.Contains(s.Tags["title"], "Atmos"):
+		return "Atmos"
+	case strings.Contains(s.Profile, "DTS:X") || strings.Contains(s.Tags["title"], "DTS:X"):
+		return "DTS:X"
+	}
+	return ""
+}
+
+// Description returns a short summary of the track ie "truehd Atmos 7.1 (eng)"
+func (s FFProbeStreamAudio) Description() string {
+	parts := []string{s.audioCodecKey()}
+	if o := s.ObjectBased(); o != "" {
+		parts = append(parts, o)
+	}
+	if s.ChannelLayout != "" {
+		parts = append(parts, s.ChannelLayout)
+	}
+
+	// untagged tracks show as und the same as in AudioLanguages
+	lang := s.Language
+	if lang == "" {
+		lang = "und"
+	}
+
+	d := strings.Join(parts, " ") + " (" + lang + ")"
+	if s.Commentary {
+		d += " commentary"
+	}
+	return d
+}
+
+// BetterThan returns true if the track is better quality than another, a commentary is never better than the
+// main audio then it is codec, object audio, channels and finally bitrate
+func (s FFProbeStreamAudio) BetterThan(o FFProbeStreamAudio) bool {
+	if s.Commentary != o.Commentary {
+		return !s.Commentary
+	}
+	if s.CodecRank() != o.CodecRank() {
+		return s.CodecRank() > o.CodecRank()
+	}
+	if (s.ObjectBased() != "") != (o.ObjectBased() != "") {
+		return s.ObjectBased() != ""
+	}
+	if s.Channels != o.Channels {
+		return s.Channels > o.Channels
+	}
+	return s.BitRate > o.BitRate
+}
+
+// isCommentary returns true if a track is flagged or titled as a commentary
+func isCommentary(disposition map[string]int, title string) bool {
+	return disposition["comment"] == 1 || strings.Contains(strings.ToLower(title), "commentary")
+}
+
+// BestAudioStream returns the best quality audio track or nil if there are none
+func (v *VideoFile) BestAudioStream() *FFProbeStreamAudio {
+	var best *FFProbeStreamAudio
+	for i := range v.AudioStreams {
+		if best == nil || v.AudioStreams[i].BetterThan(*best) {
+			best = &v.AudioStreams[i]
+		}
+	}
+	return best
+}
+
+// AudioLanguages returns the sorted unique languages of the audio tracks, commentaries are not counted
+func (v *VideoFile) AudioLanguages() []string {
+	seen := map[string]bool{}
+	var languages []string
+	for _, s := range v.AudioStreams {
+		if s.Commentary {
+			continue
+		}
+
+		l := s.Language
+		if l == "" {
+			l = "und"
+		}
+		if !seen[l] {
+			seen[l] = true
+			languages = append(languages, l)
+		}
+	}
+	sort.Strings(languages)
+
+	return languages
+}
+
+// CommentaryTracks returns the number of audio tracks that are commentaries
+func (v *VideoFile) CommentaryTracks() int {
+	n := 0
+	for _, s := range v.AudioStreams {
+		if s.Commentary {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,81 @@
+package content
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/katbyte/go-ingest-media/lib/content/contenttest"
+)
+
+func TestAudioQuality(t *testing.T) {
+	cases := []struct {
+		name        string
+		fixture     string
+		best        string
+		languages   []string
+		commentary  int
+		codecRanked []string // audio codecs of the tracks from best to worst
+	}{
+		{"truehd atmos", "hevc-2160p-dv.mkv", "truehd Atmos 7.1 (eng)", []string{"eng"}, 0, []string{"truehd"}},
+		{"eac3 over aac", "hevc-1080p.mkv", "eac3 5.1(side) (eng)", []string{"eng", "jpn"}, 0, []string{"eac3", "aac"}},
+		{"commentary never best", "h264-1080p-dts.mkv", "dts-hd ma 5.1(side) (eng)", []string{"eng", "fre"}, 1, []string{"dts", "aac", "ac3"}},
+		{"no language", "h264-480p.avi", "mp3 stereo (und)", []string{"und"}, 0, []string{"mp3"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fv := contenttest.New(t, t.TempDir())
+			v := loadVideo(t, fv.Add(tc.fixture, tc.fixture, 1024))
+
+			best := v.BestAudioStream()
+			if best == nil {
+				t.Fatal("no best audio stream")
+			}
+			if got := best.Description(); got != tc.best {
+				t.Errorf("best: got %q, want %q", got, tc.best)
+			}
+			if got := v.AudioLanguages(); !reflect.DeepEqual(got, tc.languages) {
+				t.Errorf("languages: got %v, want %v", got, tc.languages)
+			}
+			if got := v.CommentaryTracks(); got != tc.commentary {
+				t.Errorf("commentary tracks: got %d, want %d", got, tc.commentary)
+			}
+
+			// every track should be better than the ones after it in ranked order
+			var ranked []FFProbeStreamAudio
+			for _, codec := range tc.codecRanked {
+				for _, s := range v.AudioStreams {
+					if s.CodecName == codec {
+						ranked = append(ranked, s)
+					}
+				}
+			}
+			for i := 1; i < len(ranked); i++ {
+				if !ranked[i-1].BetterThan(ranked[i]) || ranked[i].BetterThan(ranked[i-1]) {
+					t.Errorf("expected %s to be better than %s", ranked[i-1].Description(), ranked[i].Description())
+				}
+			}
+		})
+	}
+}
+
+func TestAudioCodecRank(t *testing.T) {
+	ranked := []FFProbeStreamAudio{
+		{CodecName: "truehd"},
+		{CodecName: "dts", Profile: "DTS"},
+		{CodecName: "eac3"},
+		{CodecName: "ac3"},
+		{CodecName: "aac"},
+		{CodecName: "mp3"},
+	}
+	for i := 1; i < len(ranked); i++ {
+		if ranked[i-1].CodecRank() <= ranked[i].CodecRank() {
+			t.Errorf("%s should rank above %s", ranked[i-1].audioCodecKey(), ranked[i].audioCodecKey())
+		}
+	}
+
+	ma := FFProbeStreamAudio{CodecName: "dts", Profile: "DTS-HD MA"}
+	if ma.CodecRank() != ranked[0].CodecRank() {
+		t.Errorf("dts-hd ma should rank the same as truehd")
+	}
+}
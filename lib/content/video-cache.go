@@ -0,0 +1,59 @@
+package content
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+
+	c "github.com/gookit/color"
+	"github.com/katbyte/go-ingest-media/lib/probecache"
+)
+
+// probeCache is consulted before running ffprobe, nil disables caching
+var probeCache *probecache.Cache
+
+var probeCacheWarning sync.Once
+
+// SetProbeCache sets the cache used to avoid re-probing unchanged files, nil disables it
+func SetProbeCache(c *probecache.Cache) {
+	probeCache = c
+}
+
+// cachedFFProbe returns the ffprobe output for a file from the cache if it has not changed since it was last probed,
+// otherwise it is probed and the result stored. Failed probes are never cached so they are retried next run
+func cachedFFProbe(path string, info os.FileInfo) (*FFProbeOutput, error) {
+	if probeCache == nil {
+		return FFProbe(path)
+	}
+
+	// the cache could not be opened, the warning is only shown once
+	if err := probeCache.Open(); err != nil {
+		probeCacheWarning.Do(func() {
+			c.Printf("<yellow>WARNING:</> ffprobe cache unavailable, every video will be probed: %s\n", err)
+		})
+		return FFProbe(path)
+	}
+
+	key := probecache.KeyFor(path, info)
+	if out, ok, err := probeCache.Get(key); err == nil && ok {
+		var probe FFProbeOutput
+		if err := json.Unmarshal(out, &probe); err == nil {
+			return &probe, nil
+		}
+	}
+
+	out, err := runFFProbe(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var probe FFProbeOutput
+	if err := json.Unmarshal(out, &probe); err != nil {
+		return nil, err
+	}
+
+	// a cache write failing only costs a re-probe next time
+	_ = probeCache.Put(key, out)
+
+	return &probe, nil
+}
@@ -69,11 +69,39 @@ type FFProbeStream struct {
 	Channels           int               `json:"channels,omitempty"`
 	ChannelLayout      string            `json:"channel_layout,omitempty"`
 	BitsPerSample      int               `json:"bits_per_sample,omitempty"`
+	SideDataList       []FFProbeSideData `json:"side_data_list,omitempty"`
 	// Add other fields as needed
 }
 
+// FFProbeSideData is extra stream information, only the dolby vision configuration is used
+type FFProbeSideData struct {
+	SideDataType              string `json:"side_data_type"`
+	DvProfile                 int    `json:"dv_profile,omitempty"`
+	DvLevel                   int    `json:"dv_level,omitempty"`
+	RpuPresentFlag            int    `json:"rpu_present_flag,omitempty"`
+	ElPresentFlag             int    `json:"el_present_flag,omitempty"`
+	BlPresentFlag             int    `json:"bl_present_flag,omitempty"`
+	DvBlSignalCompatibilityID int    `json:"dv_bl_signal_compatibility_id,omitempty"`
+}
+
 // GetVideoInfo runs ffprobe on the specified video file and returns its information.
 func FFProbe(pathToVideo string) (*FFProbeOutput, error) {
+	out, err := runFFProbe(pathToVideo)
+	if err != nil {
+		return nil, err
+	}
+
+	var info FFProbeOutput
+	err = json.Unmarshal(out, &info)
+	if err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// runFFProbe returns the raw json output of ffprobe for a file
+func runFFProbe(pathToVideo string) ([]byte, error) {
 	cmd := exec.Command("ffprobe", "-v", "quiet", "-print_format", "json", "-show_format", "-show_streams", pathToVideo)
 
 	var out bytes.Buffer
@@ -89,13 +117,7 @@ func FFProbe(pathToVideo string) (*FFProbeOutput, error) {
 		return nil, fmt.Errorf("%w: %s (file: %s)", err, errMsg, pathToVideo)
 	}
 
-	var info FFProbeOutput
-	err = json.Unmarshal(out.Bytes(), &info)
-	if err != nil {
-		return nil, err
-	}
-
-	return &info, nil
+	return out.Bytes(), nil
 }
 
 type FFProbeStreamVideo struct {
@@ -107,6 +129,11 @@ type FFProbeStreamVideo struct {
 	Height             int               `json:"height"`
 	DisplayAspectRatio string            `json:"display_aspect_ratio"`
 	PixFmt             string            `json:"pix_fmt"`
+	ColorTransfer      string            `json:"color_transfer"`
+	ColorPrimaries     string            `json:"color_primaries"`
+	FieldOrder         string            `json:"field_order"`
+	BitsPerRawSample   string            `json:"bits_per_raw_sample"`
+	SideDataList       []FFProbeSideData `json:"side_data_list"`
 	FrameRate          string            `json:"r_frame_rate"`
 	Duration           float64           `json:"duration"`
 	BitRate            int               `json:"bit_rate"`
@@ -125,6 +152,11 @@ func (output *FFProbeOutput) VideoStreams() ([]FFProbeStreamVideo, error) {
 				Height:             s.Height,
 				DisplayAspectRatio: s.DisplayAspectRatio,
 				PixFmt:             s.PixFmt,
+				ColorTransfer:      s.ColorTransfer,
+				ColorPrimaries:     s.ColorPrimaries,
+				FieldOrder:         s.FieldOrder,
+				BitsPerRawSample:   s.BitsPerRawSample,
+				SideDataList:       s.SideDataList,
 				FrameRate:          s.RFrameRate,
 				Profile:            s.Profile,
 				Tags:               s.Tags,
@@ -153,6 +185,7 @@ type FFProbeStreamAudio struct {
 	Index         int               `json:"index"`
 	CodecName     string            `json:"codec_name"`
 	CodecLongName string            `json:"codec_long_name"`
+	Profile       string            `json:"profile"`
 	SampleRate    string            `json:"sample_rate"`
 	Channels      int               `json:"channels"`
 	ChannelLayout string            `json:"channel_layout"`
@@ -160,6 +193,7 @@ type FFProbeStreamAudio struct {
 	BitRate       int               `json:"bit_rate"`
 	Tags          map[string]string `json:"tags"`
 	Language      string            `json:"language"`
+	Commentary    bool              `json:"commentary"`
 }
 
 func (output *FFProbeOutput) AudioStreams() ([]FFProbeStreamAudio, error) {
@@ -170,10 +204,12 @@ func (output *FFProbeOutput) AudioStreams() ([]FFProbeStreamAudio, error) {
 				Index:         s.Index,
 				CodecName:     s.CodecName,
 				CodecLongName: s.CodecLongName,
+				Profile:       s.Profile,
 				SampleRate:    s.SampleRate,
 				Channels:      s.Channels,
 				ChannelLayout: s.ChannelLayout,
 				Tags:          s.Tags,
+				Commentary:    isCommentary(s.Disposition, s.Tags["title"]),
 			}
 
 			var err error
@@ -0,0 +1,133 @@
+package content
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DynamicRange is the HDR format of a video stream, in order of preference
+type DynamicRange int
+
+const (
+	DynamicRangeSDR DynamicRange = iota
+	DynamicRangeHLG
+	DynamicRangeHDR10
+	DynamicRangeDolbyVision
+)
+
+func (r DynamicRange) String() string {
+	switch r {
+	case DynamicRangeHLG:
+		return "HLG"
+	case DynamicRangeHDR10:
+		return "HDR10"
+	case DynamicRangeDolbyVision:
+		return "DV"
+	case DynamicRangeSDR:
+		fallthrough
+	default:
+		return "SDR"
+	}
+}
+
+// ffprobe side data type carrying the dolby vision profile
+const sideDataDolbyVision = "DOVI configuration record"
+
+// dynamicRangeOf returns the HDR format of a stream and its dolby vision profile (0 if not dolby vision)
+func dynamicRangeOf(s FFProbeStreamVideo) (DynamicRange, int) {
+	for _, sd := range s.SideDataList {
+		if sd.SideDataType == sideDataDolbyVision {
+			return DynamicRangeDolbyVision, sd.DvProfile
+		}
+	}
+
+	switch s.ColorTransfer {
+	case "smpte2084": // PQ
+		return DynamicRangeHDR10, 0
+	case "arib-std-b67":
+		return DynamicRangeHLG, 0
+	}
+
+	return DynamicRangeSDR, 0
+}
+
+// dolbyVisionCompatibility returns what a dolby vision stream falls back to on players without it (ie HDR10 for profile 8.1)
+func dolbyVisionCompatibility(s FFProbeStreamVideo) string {
+	for _, sd := range s.SideDataList {
+		if sd.SideDataType != sideDataDolbyVision {
+			continue
+		}
+
+		switch sd.DvBlSignalCompatibilityID {
+		case 1, 6:
+			return "HDR10"
+		case 2:
+			return "SDR"
+		case 4:
+			return "HLG"
+		}
+	}
+
+	return ""
+}
+
+// bitDepthOf returns the bits per sample of a stream, 0 if it can't be worked out
+func bitDepthOf(s FFProbeStreamVideo) int {
+	if bits, err := strconv.Atoi(s.BitsPerRawSample); err == nil && bits > 0 {
+		return bits
+	}
+
+	// ie yuv420p10le, p010le, yuv444p12le
+	pixFmt := strings.TrimSuffix(strings.TrimSuffix(s.PixFmt, "le"), "be")
+	switch {
+	case pixFmt == "":
+		return 0
+	case strings.HasSuffix(pixFmt, "p16") || strings.HasSuffix(pixFmt, "016"):
+		return 16
+	case strings.HasSuffix(pixFmt, "p12") || strings.HasSuffix(pixFmt, "012"):
+		return 12
+	case strings.HasSuffix(pixFmt, "p10") || strings.HasSuffix(pixFmt, "010"):
+		return 10
+	default:
+		return 8
+	}
+}
+
+// isInterlaced returns true if the field order is one of the interlaced ones, unknown is treated as progressive
+func isInterlaced(fieldOrder string) bool {
+	switch fieldOrder {
+	case "tt", "bb", "tb", "bt":
+		return true
+	}
+	return false
+}
+
+// HDRFormat returns the HDR format including the dolby vision profile and fallback ie "DV P8 (HDR10)"
+func (v *VideoFile) HDRFormat() string {
+	if v.DynamicRange != DynamicRangeDolbyVision {
+		return v.DynamicRange.String()
+	}
+
+	s := fmt.Sprintf("DV P%d", v.DolbyVisionProfile)
+	if compat := dolbyVisionCompatibility(v.VideoStream); compat != "" {
+		s += " (" + compat + ")"
+	}
+	return s
+}
+
+// BitDepthString returns the bit depth ie "10-bit" or "unknown"
+func (v *VideoFile) BitDepthString() string {
+	if v.BitDepth == 0 {
+		return "unknown"
+	}
+	return fmt.Sprintf("%d-bit", v.BitDepth)
+}
+
+// ScanType returns "interlaced" (with the field order) or "progressive"
+func (v *VideoFile) ScanType() string {
+	if v.Interlaced {
+		return "interlaced (" + v.VideoStream.FieldOrder + ")"
+	}
+	return "progressive"
+}
@@ -0,0 +1,237 @@
+package content
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/katbyte/go-ingest-media/lib/ktio"
+)
+
+// ContentMatch is whether two videos are the same film or episode, judged by comparing frames sampled from each
+type ContentMatch int
+
+const (
+	ContentUnknown      ContentMatch = iota // frames could not be compared
+	ContentSame                             // the same content, ie two encodes of the same release
+	ContentDifferentCut                     // the same content but scenes were added or removed, ie an extended edition
+	ContentDifferent                        // different content, ie the wrong film in the folder
+)
+
+func (m ContentMatch) String() string {
+	switch m {
+	case ContentSame:
+		return "same content"
+	case ContentDifferentCut:
+		return "different cut"
+	case ContentDifferent:
+		return "different content"
+	case ContentUnknown:
+		fallthrough
+	default:
+		return "unknown"
+	}
+}
+
+// where frames are sampled as a fraction of the duration, the start and end are skipped as they are often black or
+// logos and credits
+var framePositions = []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9}
+
+// frames whose hashes differ by at most this many of the 64 bits are taken to be the same picture
+const frameHashThreshold = 10
+
+// how long ffmpeg gets to extract a single frame before it is killed, a seek on a slow mount or damaged file can hang
+const frameTimeout = 30 * time.Second
+
+// FrameHasher returns a perceptual hash of the frame at each timestamp (in seconds) of a video
+type FrameHasher interface {
+	FrameHashes(ctx context.Context, path string, timestamps []float64) ([]uint64, error)
+}
+
+var frameHasher FrameHasher = newCachedFrameHasher(FFmpegFrameHasher{})
+
+// SetFrameHasher replaces how frames are hashed, nil restores ffmpeg
+func SetFrameHasher(h FrameHasher) {
+	if h == nil {
+		h = FFmpegFrameHasher{}
+	}
+	frameHasher = newCachedFrameHasher(h)
+}
+
+// FFmpegFrameHasher extracts each frame with ffmpeg scaled down to 9x8 greyscale and returns its difference hash
+type FFmpegFrameHasher struct{}
+
+func (FFmpegFrameHasher) FrameHashes(ctx context.Context, path string, timestamps []float64) ([]uint64, error) {
+	hashes := make([]uint64, 0, len(timestamps))
+	for _, ts := range timestamps {
+		h, err := ffmpegFrameHash(ctx, path, ts)
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, h)
+	}
+
+	return hashes, nil
+}
+
+// ffmpegFrameHash extracts and hashes the frame at ts, giving up after frameTimeout
+func ffmpegFrameHash(ctx context.Context, path string, ts float64) (uint64, error) {
+	ctx, cancel := context.WithTimeout(ctx, frameTimeout)
+	defer cancel()
+
+	// seeking before the input is fast as it jumps to the nearest keyframe
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-v", "error", "-ss", strconv.FormatFloat(ts, 'f', 3, 64), "-i", path,
+		"-frames:v", "1", "-vf", "scale=9:8:flags=area,format=gray", "-f", "rawvideo", "-")
+
+	var out bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return 0, fmt.Errorf("frame at %.0fs of %s: %w", ts, path, ctxErr)
+		}
+		errMsg := stderr.String()
+		if errMsg == "" {
+			errMsg = "no stderr output"
+		}
+		return 0, fmt.Errorf("%w: %s (file: %s)", err, errMsg, path)
+	}
+
+	h, err := dHash(out.Bytes())
+	if err != nil {
+		return 0, fmt.Errorf("frame at %.0fs of %s: %w", ts, path, err)
+	}
+	return h, nil
+}
+
+// dHash returns the difference hash of a 9x8 greyscale image, each bit is whether a pixel is brighter than the one to
+// its right so the hash survives scaling, re-encoding and small colour changes
+func dHash(pixels []byte) (uint64, error) {
+	if len(pixels) != 9*8 {
+		return 0, fmt.Errorf("expected 72 pixels, got %d", len(pixels))
+	}
+
+	var h uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			h <<= 1
+			if pixels[y*9+x] > pixels[y*9+x+1] {
+				h |= 1
+			}
+		}
+	}
+	return h, nil
+}
+
+// cachedFrameHasher remembers hashes so a source compared to several destinations is only read once
+type cachedFrameHasher struct {
+	hasher FrameHasher
+
+	mu     sync.Mutex
+	hashes map[string]map[float64]uint64
+}
+
+func newCachedFrameHasher(h FrameHasher) *cachedFrameHasher {
+	return &cachedFrameHasher{hasher: h, hashes: map[string]map[float64]uint64{}}
+}
+
+func (c *cachedFrameHasher) FrameHashes(ctx context.Context, path string, timestamps []float64) ([]uint64, error) {
+	c.mu.Lock()
+	cached := c.hashes[path]
+	var missing []float64
+	for _, ts := range timestamps {
+		if _, ok := cached[ts]; !ok {
+			missing = append(missing, ts)
+		}
+	}
+	c.mu.Unlock()
+
+	if len(missing) > 0 {
+		hashes, err := c.hasher.FrameHashes(ctx, path, missing)
+		if err != nil {
+			return nil, err
+		}
+
+		c.mu.Lock()
+		if c.hashes[path] == nil {
+			c.hashes[path] = map[float64]uint64{}
+		}
+		for i, ts := range missing {
+			c.hashes[path][ts] = hashes[i]
+		}
+		c.mu.Unlock()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	hashes := make([]uint64, len(timestamps))
+	for i, ts := range timestamps {
+		hashes[i] = c.hashes[path][ts]
+	}
+	return hashes, nil
+}
+
+// CompareContent samples frames from both videos to work out if they are the same content. Frames are compared at the
+// same fraction of each video's duration, which lines up for re-encodes, and at the same time from the start, which
+// lines up for the scenes before a cut differs
+func CompareContent(ctx context.Context, v1, v2 VideoFile) (ContentMatch, error) {
+	if v1.Duration <= 0 || v2.Duration <= 0 {
+		return ContentUnknown, errors.New("the duration of both videos is needed to sample frames")
+	}
+
+	var t1, t2Fraction, t2Time []float64
+	for _, p := range framePositions {
+		t1 = append(t1, p*v1.Duration)
+		t2Fraction = append(t2Fraction, p*v2.Duration)
+		t2Time = append(t2Time, math.Min(p*v1.Duration, v2.Duration))
+	}
+
+	h1, err := frameHasher.FrameHashes(ctx, ktio.RealPath(v1.Path), t1)
+	if err != nil {
+		return ContentUnknown, err
+	}
+	h2Fraction, err := frameHasher.FrameHashes(ctx, ktio.RealPath(v2.Path), t2Fraction)
+	if err != nil {
+		return ContentUnknown, err
+	}
+	h2Time, err := frameHasher.FrameHashes(ctx, ktio.RealPath(v2.Path), t2Time)
+	if err != nil {
+		return ContentUnknown, err
+	}
+
+	return classifyFrames(matchingFrames(h1, h2Fraction), matchingFrames(h1, h2Time), len(framePositions)), nil
+}
+
+// matchingFrames counts the frames whose hashes are close enough to be the same picture
+func matchingFrames(h1, h2 []uint64) int {
+	n := 0
+	for i := range h1 {
+		if i < len(h2) && bits.OnesCount64(h1[i]^h2[i]) <= frameHashThreshold {
+			n++
+		}
+	}
+	return n
+}
+
+// classifyFrames decides the content match from the number of matching frames sampled by fraction and by time.
+// Most frames matching by fraction is the same content, some matching either way is a different cut
+func classifyFrames(byFraction, byTime, samples int) ContentMatch {
+	switch {
+	case samples == 0:
+		return ContentUnknown
+	case byFraction*3 >= samples*2:
+		return ContentSame
+	case byFraction*3 >= samples || byTime*3 >= samples:
+		return ContentDifferentCut
+	default:
+		return ContentDifferent
+	}
+}
@@ -0,0 +1,133 @@
+package content
+
+import (
+	"context"
+	"errors"
+	"math/bits"
+	"path/filepath"
+	"testing"
+)
+
+// sceneHasher fakes frame hashes, each video is a list of scenes that change every minute
+type sceneHasher map[string]func(ts float64) uint64
+
+func (h sceneHasher) FrameHashes(_ context.Context, path string, timestamps []float64) ([]uint64, error) {
+	scene := h[filepath.Base(path)]
+
+	hashes := make([]uint64, 0, len(timestamps))
+	for _, ts := range timestamps {
+		hashes = append(hashes, scene(ts))
+	}
+	return hashes, nil
+}
+
+// film returns the hash of a minute of a made up film, different seeds are different films
+func film(seed uint64) func(ts float64) uint64 {
+	return func(ts float64) uint64 {
+		// splitmix64 so neighbouring minutes look nothing alike
+		z := seed + uint64(ts/60)*0x9e3779b97f4a7c15
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		return z ^ (z >> 31)
+	}
+}
+
+func TestCompareContent(t *testing.T) {
+	original := film(1)
+
+	// 10 minutes of extra scenes added halfway through
+	extended := func(ts float64) uint64 {
+		switch {
+		case ts < 3000:
+			return original(ts)
+		case ts < 3600:
+			return film(2)(ts)
+		default:
+			return original(ts - 600)
+		}
+	}
+
+	// a re-encode flips a few bits of every frame
+	reencode := func(ts float64) uint64 {
+		return original(ts) ^ 0b1010_0001
+	}
+
+	SetFrameHasher(sceneHasher{
+		"original.mkv":    original,
+		"reencode.mp4":    reencode,
+		"extended.mkv":    extended,
+		"other.mkv":       film(3),
+		"no-duration.mkv": original,
+	})
+	t.Cleanup(func() { SetFrameHasher(nil) })
+
+	src := VideoFile{Path: "/src/original.mkv", Duration: 6000}
+	cases := []struct {
+		name string
+		dst  VideoFile
+		want ContentMatch
+	}{
+		{"same file", src, ContentSame},
+		{"re-encode", VideoFile{Path: "/dst/reencode.mp4", Duration: 6000.5}, ContentSame},
+		{"extended edition", VideoFile{Path: "/dst/extended.mkv", Duration: 6600}, ContentDifferentCut},
+		{"wrong film", VideoFile{Path: "/dst/other.mkv", Duration: 6000}, ContentDifferent},
+		{"no duration", VideoFile{Path: "/dst/no-duration.mkv"}, ContentUnknown},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := CompareContent(context.Background(), src, tc.dst)
+			if tc.want == ContentUnknown {
+				if err == nil {
+					t.Errorf("expected an error")
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("got %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDHash(t *testing.T) {
+	// a left to right gradient gets darker to the right so every pixel is brighter than its neighbour
+	gradient := make([]byte, 9*8)
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 9; x++ {
+			gradient[y*9+x] = byte(255 - x*20)
+		}
+	}
+
+	h, err := dHash(gradient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h != ^uint64(0) {
+		t.Errorf("gradient: got %064b, want all ones", h)
+	}
+
+	// brightening the whole frame keeps the hash
+	brighter := make([]byte, len(gradient))
+	for i, p := range gradient {
+		brighter[i] = p/2 + 100
+	}
+	if h2, _ := dHash(brighter); bits.OnesCount64(h^h2) > frameHashThreshold {
+		t.Errorf("brighter frame hash differs by %d bits", bits.OnesCount64(h^h2))
+	}
+
+	if _, err := dHash(gradient[:10]); err == nil {
+		t.Errorf("expected an error for the wrong number of pixels")
+	}
+}
+
+func TestFFmpegFrameHasherCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := FFmpegFrameHasher{}.FrameHashes(ctx, "/no/such/video.mkv", []float64{10})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want the context error", err)
+	}
+}
@@ -0,0 +1,55 @@
+package content
+
+import (
+	"strconv"
+
+	"github.com/katbyte/go-ingest-media/lib/container"
+)
+
+// NativeProbe reads the Matroska or MP4 headers of a file directly and returns them in the same form as ffprobe
+func NativeProbe(path string) (*FFProbeOutput, error) {
+	info, err := container.Read(path)
+	if err != nil {
+		return nil, err
+	}
+
+	out := FFProbeOutput{
+		Format: FFProbeFormat{
+			Filename:   path,
+			NumStreams: len(info.Tracks),
+			FormatName: info.Format,
+			Duration:   strconv.FormatFloat(info.Duration, 'f', 6, 64),
+			Size:       strconv.FormatInt(info.Size, 10),
+			BitRate:    strconv.FormatInt(info.BitRate(), 10),
+		},
+	}
+
+	for _, t := range info.Tracks {
+		s := FFProbeStream{
+			Index:       t.Index,
+			CodecName:   t.Codec,
+			CodecType:   string(t.Type),
+			Width:       t.Width,
+			Height:      t.Height,
+			Channels:    t.Channels,
+			Disposition: map[string]int{"default": 0},
+			Tags:        map[string]string{},
+		}
+		if t.Default {
+			s.Disposition["default"] = 1
+		}
+		if t.SampleRate > 0 {
+			s.SampleRate = strconv.Itoa(t.SampleRate)
+		}
+		if t.Language != "" && t.Language != "und" {
+			s.Tags["language"] = t.Language
+		}
+		if t.Name != "" {
+			s.Tags["title"] = t.Name
+		}
+
+		out.Streams = append(out.Streams, s)
+	}
+
+	return &out, nil
+}
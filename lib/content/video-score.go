@@ -0,0 +1,228 @@
+package content
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ScoreWeights are how much each part of the quality score counts towards the total, each part is rated from 0 to 1
+type ScoreWeights struct {
+	Resolution float64 `mapstructure:"resolution"` // pixels compared to 2160p, interlaced video counts as half the lines
+	Codec      float64 `mapstructure:"codec"`      // rank in videoCodecs
+	Efficiency float64 `mapstructure:"efficiency"` // bits per pixel compared to what the codec needs for good quality
+	HDR        float64 `mapstructure:"hdr"`        // dynamic range and bit depth
+	Audio      float64 `mapstructure:"audio"`      // the best audio track's codec, channels and object audio
+	Extension  float64 `mapstructure:"extension"`  // rank in videoExtensions
+}
+
+// DefaultScoreWeights are used unless score-weights is set in the config
+var DefaultScoreWeights = ScoreWeights{
+	Resolution: 35,
+	Codec:      15,
+	Efficiency: 20,
+	HDR:        10,
+	Audio:      15,
+	Extension:  5,
+}
+
+var scoreWeights = DefaultScoreWeights
+
+// SetScoreWeights replaces the weights used by Score
+func SetScoreWeights(w ScoreWeights) error {
+	for name, v := range map[string]float64{
+		"resolution": w.Resolution,
+		"codec":      w.Codec,
+		"efficiency": w.Efficiency,
+		"hdr":        w.HDR,
+		"audio":      w.Audio,
+		"extension":  w.Extension,
+	} {
+		if v < 0 {
+			return fmt.Errorf("%s weight must not be negative", name)
+		}
+	}
+	if w.total() == 0 {
+		return errors.New("at least one weight must be more than 0")
+	}
+
+	scoreWeights = w
+	return nil
+}
+
+func (w ScoreWeights) total() float64 {
+	return w.Resolution + w.Codec + w.Efficiency + w.HDR + w.Audio + w.Extension
+}
+
+// bits per pixel per frame h264 needs for good quality, other codecs scale it by their efficiency
+const targetBitsPerPixel = 0.1
+
+// how many bits other codecs need compared to h264 for the same quality
+var codecEfficiency = map[string]float64{
+	"hevc":       0.5,
+	"av1":        0.45,
+	"vp9":        0.55,
+	"mpeg2video": 2,
+	"mpeg4":      1.4,
+}
+
+// QualityScore is the weighted quality score of a video from 0 to 100 and the parts it is made of (each 0 to 1)
+type QualityScore struct {
+	Total float64
+
+	Resolution float64
+	Codec      float64
+	Efficiency float64
+	HDR        float64
+	Audio      float64
+	Extension  float64
+}
+
+func (s QualityScore) String() string {
+	return strconv.FormatFloat(s.Total, 'f', 1, 64)
+}
+
+// Score rates the overall quality of the video with the configured weights, a video that failed to probe scores 0
+func (v VideoFile) Score() QualityScore {
+	if v.FFProbeFailed {
+		return QualityScore{}
+	}
+
+	s := QualityScore{
+		Resolution: v.resolutionScore(),
+		Codec:      rankScore(VideoCodecIndex(v.VideoStream.CodecName), len(videoCodecs)),
+		Efficiency: v.efficiencyScore(),
+		HDR:        v.hdrScore(),
+		Audio:      v.audioScore(),
+		Extension:  rankScore(VideoExtensionIndex(v.Ext), len(videoExtensions)),
+	}
+
+	w := scoreWeights
+	s.Total = (s.Resolution*w.Resolution +
+		s.Codec*w.Codec +
+		s.Efficiency*w.Efficiency +
+		s.HDR*w.HDR +
+		s.Audio*w.Audio +
+		s.Extension*w.Extension) / w.total() * 100
+
+	return s
+}
+
+// rankScore turns an index into a preference list into a score, the first is 1 and unknown (-1) is 0
+func rankScore(index, count int) float64 {
+	if index < 0 || count == 0 {
+		return 0
+	}
+	return 1 - float64(index)/float64(count)
+}
+
+func (v VideoFile) resolutionScore() float64 {
+	// cropped widescreen video is short so use whichever side is closest to 2160p
+	s := math.Max(float64(v.ResolutionW)/3840, float64(v.ResolutionH)/2160)
+	if v.Interlaced {
+		s /= 2
+	}
+	return math.Min(s, 1)
+}
+
+// VideoBitRate returns the bitrate of the video stream alone, from the stream or its mkvmerge statistics tag, otherwise
+// the overall bitrate less the audio tracks. 0 if unknown
+func (v VideoFile) VideoBitRate() int {
+	if v.VideoStream.BitRate > 0 {
+		return v.VideoStream.BitRate
+	}
+	if bps := tagBitRate(v.VideoStream.Tags); bps > 0 {
+		return bps
+	}
+
+	rate := v.BitRate
+	for _, a := range v.AudioStreams {
+		if a.BitRate > 0 {
+			rate -= a.BitRate
+		} else {
+			rate -= tagBitRate(a.Tags)
+		}
+	}
+	return max(rate, 0)
+}
+
+// tagBitRate returns the BPS statistics tag mkvmerge writes for each track, 0 if there is none
+func tagBitRate(tags map[string]string) int {
+	for _, k := range []string{"BPS", "BPS-eng"} {
+		if bps, err := strconv.Atoi(tags[k]); err == nil && bps > 0 {
+			return bps
+		}
+	}
+	return 0
+}
+
+// BitsPerPixel returns the average video bits per pixel of each frame, 0 if unknown
+func (v VideoFile) BitsPerPixel() float64 {
+	pixels := float64(v.ResolutionW * v.ResolutionH)
+	rate := v.VideoBitRate()
+	if pixels == 0 || rate == 0 {
+		return 0
+	}
+
+	return float64(rate) / (pixels * frameRate(v.VideoStream.FrameRate))
+}
+
+func (v VideoFile) efficiencyScore() float64 {
+	target := targetBitsPerPixel
+	if e, ok := codecEfficiency[v.VideoStream.CodecName]; ok {
+		target *= e
+	}
+	return math.Min(v.BitsPerPixel()/target, 1)
+}
+
+func (v VideoFile) hdrScore() float64 {
+	s := float64(v.DynamicRange) / float64(DynamicRangeDolbyVision) * 0.75
+	if v.BitDepth >= 10 {
+		s += 0.25
+	}
+	return s
+}
+
+func (v VideoFile) audioScore() float64 {
+	best := v.BestAudioStream()
+	if best == nil {
+		return 0
+	}
+
+	s := float64(best.CodecRank())/5*0.6 + math.Min(float64(best.Channels)/8, 1)*0.3
+	if best.ObjectBased() != "" {
+		s += 0.1
+	}
+	return s
+}
+
+// frameRate parses an ffprobe frame rate ie 24000/1001, anything unknown is taken as 24
+func frameRate(r string) float64 {
+	num, den, found := strings.Cut(r, "/")
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil || n <= 0 {
+		return 24
+	}
+	if !found {
+		return n
+	}
+
+	d, err := strconv.ParseFloat(den, 64)
+	if err != nil || d <= 0 {
+		return 24
+	}
+	return n / d
+}
+
+// BestScore returns the index of the video with the highest score, the first wins a tie
+func BestScore(videos []VideoFile) int {
+	best, bestScore := -1, -1.0
+	for i, v := range videos {
+		if s := v.Score().Total; s > bestScore {
+			best, bestScore = i, s
+		}
+	}
+	return best
+}
@@ -0,0 +1,152 @@
+package content
+
+import (
+	"testing"
+
+	"github.com/katbyte/go-ingest-media/lib/content/contenttest"
+)
+
+func TestScore(t *testing.T) {
+	fv := contenttest.New(t, t.TempDir())
+
+	// best to worst
+	ranked := []VideoFile{
+		loadVideo(t, fv.Add("hevc-2160p-dv.mkv", "hevc-2160p-dv.mkv", 1024)),
+		loadVideo(t, fv.Add("hevc-2160p-hdr10.mkv", "hevc-2160p-hdr10.mkv", 1024)),
+		loadVideo(t, fv.Add("hevc-2160p.mkv", "hevc-2160p.mkv", 1024)),
+		loadVideo(t, fv.Add("hevc-1080p.mkv", "hevc-1080p.mkv", 1024)),
+		loadVideo(t, fv.Add("h264-1080p.mkv", "h264-1080p.mkv", 1024)),
+		loadVideo(t, fv.Add("h264-480p.avi", "h264-480p.avi", 1024)),
+		loadVideo(t, fv.Add("mpeg2-480i.mkv", "mpeg2-480i.mkv", 1024)),
+		loadVideo(t, fv.Add("broken.mkv", "", 1024)),
+	}
+
+	for i := 1; i < len(ranked); i++ {
+		better, worse := ranked[i-1], ranked[i]
+		if better.Score().Total <= worse.Score().Total {
+			t.Errorf("%s (%s) should score higher than %s (%s)", better.Path, better.Score(), worse.Path, worse.Score())
+		}
+	}
+
+	for _, v := range ranked {
+		if s := v.Score().Total; s < 0 || s > 100 {
+			t.Errorf("%s: score %s out of range", v.Path, v.Score())
+		}
+	}
+	if s := ranked[len(ranked)-1].Score().Total; s != 0 {
+		t.Errorf("a video that failed to probe should score 0, got %g", s)
+	}
+
+	if got := BestScore([]VideoFile{ranked[3], ranked[0], ranked[1]}); got != 1 {
+		t.Errorf("best score: got %d, want 1", got)
+	}
+}
+
+func TestScoreWeights(t *testing.T) {
+	t.Cleanup(func() { _ = SetScoreWeights(DefaultScoreWeights) })
+
+	fv := contenttest.New(t, t.TempDir())
+	uhd := loadVideo(t, fv.Add("hevc-2160p.mkv", "hevc-2160p.mkv", 1024))
+	sd := loadVideo(t, fv.Add("h264-480p.avi", "h264-480p.avi", 1024))
+
+	// only the audio counts, truehd 7.1 beats stereo mp3
+	if err := SetScoreWeights(ScoreWeights{Audio: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if uhd.Score().Total <= sd.Score().Total {
+		t.Errorf("truehd should score higher than mp3 on audio alone")
+	}
+	if got := uhd.Score().Total; got != uhd.Score().Audio*100 {
+		t.Errorf("audio only score: got %g, want %g", got, uhd.Score().Audio*100)
+	}
+
+	if err := SetScoreWeights(ScoreWeights{}); err == nil {
+		t.Errorf("expected an error when every weight is 0")
+	}
+	if err := SetScoreWeights(ScoreWeights{Codec: -1, Audio: 1}); err == nil {
+		t.Errorf("expected an error for a negative weight")
+	}
+}
+
+func TestPolicyScore(t *testing.T) {
+	fv := contenttest.New(t, t.TempDir())
+	uhd := loadVideo(t, fv.Add("hevc-2160p.mkv", "hevc-2160p.mkv", 1024))
+	hd := loadVideo(t, fv.Add("hevc-1080p.mkv", "hevc-1080p.mkv", 1024))
+
+	cases := []struct {
+		name     string
+		when     map[string]string
+		src, dst VideoFile
+		want     bool
+	}{
+		{"higher score", map[string]string{"score": ">"}, uhd, hd, true},
+		{"lower score", map[string]string{"score": ">"}, hd, uhd, false},
+		{"within margin", map[string]string{"score": ">", "score-margin": "100"}, uhd, hd, false},
+		{"outside margin", map[string]string{"score": ">", "score-margin": "1"}, uhd, hd, true},
+		{"margin either way", map[string]string{"score-margin": "1"}, hd, uhd, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewPolicyRule(tc.name, tc.when, "replace")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := r.Matches(tc.src, tc.dst); got != tc.want {
+				t.Errorf("got %t, want %t (src %s, dst %s)", got, tc.want, tc.src.Score(), tc.dst.Score())
+			}
+		})
+	}
+}
+
+func TestVideoBitRate(t *testing.T) {
+	tests := []struct {
+		name  string
+		video VideoFile
+		want  int
+	}{
+		{
+			name: "video stream bitrate",
+			video: VideoFile{
+				BitRate:      10000,
+				VideoStream:  FFProbeStreamVideo{BitRate: 8000},
+				AudioStreams: []FFProbeStreamAudio{{BitRate: 640}},
+			},
+			want: 8000,
+		},
+		{
+			name: "mkvmerge statistics tag",
+			video: VideoFile{
+				BitRate:     10000,
+				VideoStream: FFProbeStreamVideo{Tags: map[string]string{"BPS-eng": "7500"}},
+			},
+			want: 7500,
+		},
+		{
+			name: "overall less audio",
+			video: VideoFile{
+				BitRate: 10000,
+				AudioStreams: []FFProbeStreamAudio{
+					{BitRate: 640},
+					{Tags: map[string]string{"BPS": "1500"}},
+				},
+			},
+			want: 7860,
+		},
+		{
+			name: "unknown",
+			video: VideoFile{
+				AudioStreams: []FFProbeStreamAudio{{BitRate: 640}},
+			},
+			want: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.video.VideoBitRate(); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,8 +1,8 @@
 package content
 
 import (
+	"context"
 	"fmt"
-	"os"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -23,11 +23,20 @@ type VideoFile struct {
 	ResolutionW int
 	ResolutionH int
 
+	// picture quality
+	DynamicRange       DynamicRange
+	DolbyVisionProfile int
+	BitDepth           int
+	Interlaced         bool
+
 	VideoStream  FFProbeStreamVideo
 	AudioStreams []FFProbeStreamAudio
 	ImageStreams []FFProbeStreamImage
 	Subtitles    []FFProbeStreamSubtitle
 
+	// subtitle files next to the video, found when loading a folder or episode
+	SidecarSubtitles []string
+
 	// Set to true if ffprobe failed - only basic file info available
 	FFProbeFailed bool
 }
@@ -41,46 +50,57 @@ func (v VideoFile) IsBasicallyTheSameTo(v2 VideoFile) bool {
 		v.Resolution == v2.Resolution &&
 		v.VideoStream.CodecName == v2.VideoStream.CodecName &&
 		v.VideoStream.Profile == v2.VideoStream.Profile &&
+		v.DynamicRange == v2.DynamicRange &&
+		v.DolbyVisionProfile == v2.DolbyVisionProfile &&
+		v.BitDepth == v2.BitDepth &&
+		v.Interlaced == v2.Interlaced &&
 		len(v.AudioStreams) == len(v2.AudioStreams) &&
 		len(v.ImageStreams) == len(v2.ImageStreams) &&
 		len(v.Subtitles) == len(v2.Subtitles)
 }
 
-func VideosInPath(path string) ([]VideoFile, error) {
+func VideosInPath(ctx context.Context, path string) ([]VideoFile, error) {
 	files, err := ktio.ListFiles(path)
 	if err != nil {
 		return nil, fmt.Errorf("error listing content folders: %w", err)
 	}
 
-	videos := make([]VideoFile, 0)
+	var videoFiles []string
 	for _, f := range files {
 		if IsVideoFile(f) {
-			v, err := VideoFor(f)
-			if err != nil {
-				return nil, err
-			}
-
-			videos = append(videos, *v)
+			videoFiles = append(videoFiles, f)
 		}
 	}
 
+	videos, err := scheduler.ProbeAll(ctx, videoFiles)
+	if err != nil {
+		return nil, err
+	}
+	for i := range videos {
+		videos[i].SidecarSubtitles = sidecarsFor(videos[i].Path, files, len(videos) == 1)
+	}
+
 	return videos, nil
 }
 
-func VideoFor(path string) (*VideoFile, error) {
+// VideoFor reads the details of the video at path with the context's prober
+func VideoFor(ctx context.Context, path string) (*VideoFile, error) {
 	v := VideoFile{
 		Path: path,
 		Ext:  filepath.Ext(path),
 	}
 
-	fileInfo, err := os.Stat(path)
+	// when dry-running the file may only be at its new path in the plan
+	realPath := ktio.RealPath(path)
+
+	fileInfo, err := ktio.Stat(realPath)
 	if err != nil {
 		return nil, err
 	}
 	v.SizeBytes = fileInfo.Size()
 	v.SizeGb = float64(v.SizeBytes) / 1024 / 1024 / 1024
 
-	probe, err := FFProbe(path)
+	probe, err := ProberFrom(ctx).Probe(realPath, fileInfo)
 	if err != nil {
 		// FFProbe failed - return partial video info with what we have
 		v.FFProbeFailed = true
@@ -111,6 +131,9 @@ func VideoFor(path string) (*VideoFile, error) {
 	v.VideoStream = vStreams[0]
 	v.ResolutionW = v.VideoStream.Width
 	v.ResolutionH = v.VideoStream.Height
+	v.DynamicRange, v.DolbyVisionProfile = dynamicRangeOf(v.VideoStream)
+	v.BitDepth = bitDepthOf(v.VideoStream)
+	v.Interlaced = isInterlaced(v.VideoStream.FieldOrder)
 
 	v.AudioStreams, _ = probe.AudioStreams()
 	v.ImageStreams, _ = probe.ImageStreams()
@@ -0,0 +1,171 @@
+package content
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/katbyte/go-ingest-media/lib/content/contenttest"
+)
+
+// fixtureContext probes videos as the saved fixture named by their contents
+func fixtureContext() context.Context {
+	return WithProber(context.Background(), FixtureProber{Dir: contenttest.Fixtures})
+}
+
+// loadVideo reads a placeholder video created by a contenttest.Tree
+func loadVideo(t *testing.T, path string) VideoFile {
+	t.Helper()
+
+	v, err := VideoFor(fixtureContext(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *v
+}
+
+func TestVideoFor(t *testing.T) {
+	cases := []struct {
+		name       string
+		file       string
+		fixture    string
+		resolution string
+		codec      string
+		audio      int
+		subtitles  int
+		failed     bool
+		hdr        string
+		bitDepth   int
+		interlaced bool
+	}{
+		{"hevc", "hevc-1080p.mkv", "hevc-1080p.mkv", "1920x1080", "hevc", 2, 1, false, "SDR", 10, false},
+		{"uhd", "hevc-2160p.mkv", "hevc-2160p.mkv", "3840x2160", "hevc", 1, 0, false, "SDR", 10, false},
+		{"hdr10", "hevc-2160p-hdr10.mkv", "hevc-2160p-hdr10.mkv", "3840x2160", "hevc", 1, 0, false, "HDR10", 10, false},
+		{"dolby vision", "hevc-2160p-dv.mkv", "hevc-2160p-dv.mkv", "3840x2160", "hevc", 1, 0, false, "DV P8 (HDR10)", 10, false},
+		{"multiple audio", "h264-1080p-dts.mkv", "h264-1080p-dts.mkv", "1920x1080", "h264", 3, 1, false, "SDR", 8, false},
+		{"sd", "h264-480p.avi", "h264-480p.avi", "640x480", "h264", 1, 0, false, "SDR", 8, false},
+		{"interlaced", "mpeg2-480i.mkv", "mpeg2-480i.mkv", "720x480", "mpeg2video", 1, 0, false, "SDR", 8, true},
+		{"no video stream", "audio-only.mkv", "audio-only.mkv", "NO VIDEO", "", 0, 0, true, "SDR", 0, false},
+		{"probe failed", "broken.mkv", "", "UNKNOWN", "", 0, 0, true, "SDR", 0, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fv := contenttest.New(t, t.TempDir())
+			v := loadVideo(t, fv.Add(tc.file, tc.fixture, 1024))
+
+			if v.Resolution != tc.resolution {
+				t.Errorf("resolution: got %q, want %q", v.Resolution, tc.resolution)
+			}
+			if v.VideoStream.CodecName != tc.codec {
+				t.Errorf("codec: got %q, want %q", v.VideoStream.CodecName, tc.codec)
+			}
+			if len(v.AudioStreams) != tc.audio {
+				t.Errorf("audio streams: got %d, want %d", len(v.AudioStreams), tc.audio)
+			}
+			if len(v.Subtitles) != tc.subtitles {
+				t.Errorf("subtitles: got %d, want %d", len(v.Subtitles), tc.subtitles)
+			}
+			if v.FFProbeFailed != tc.failed {
+				t.Errorf("probe failed: got %t, want %t", v.FFProbeFailed, tc.failed)
+			}
+			if got := v.HDRFormat(); got != tc.hdr {
+				t.Errorf("hdr: got %q, want %q", got, tc.hdr)
+			}
+			if v.BitDepth != tc.bitDepth {
+				t.Errorf("bit depth: got %d, want %d", v.BitDepth, tc.bitDepth)
+			}
+			if v.Interlaced != tc.interlaced {
+				t.Errorf("interlaced: got %t, want %t", v.Interlaced, tc.interlaced)
+			}
+			if v.SizeBytes != 1024 {
+				t.Errorf("size: got %d, want 1024", v.SizeBytes)
+			}
+		})
+	}
+}
+
+func TestIsBasicallyTheSameTo(t *testing.T) {
+	type file struct {
+		name    string
+		fixture string
+		size    int64
+	}
+
+	cases := []struct {
+		name string
+		a, b file
+		same bool
+	}{
+		{
+			name: "identical",
+			a:    file{"hevc-1080p.mkv", "hevc-1080p.mkv", 2048},
+			b:    file{"hevc-1080p.mkv", "hevc-1080p.mkv", 2048},
+			same: true,
+		},
+		{
+			name: "different size",
+			a:    file{"hevc-1080p.mkv", "hevc-1080p.mkv", 2048},
+			b:    file{"hevc-1080p.mkv", "hevc-1080p.mkv", 2049},
+			same: false,
+		},
+		{
+			name: "different extension",
+			a:    file{"movie.mkv", "hevc-1080p.mkv", 2048},
+			b:    file{"movie.mp4", "hevc-1080p.mkv", 2048},
+			same: false,
+		},
+		{
+			name: "different codec",
+			a:    file{"hevc-1080p.mkv", "hevc-1080p.mkv", 2048},
+			b:    file{"h264-1080p.mkv", "h264-1080p.mkv", 2048},
+			same: false,
+		},
+		{
+			name: "different resolution",
+			a:    file{"hevc-1080p.mkv", "hevc-1080p.mkv", 2048},
+			b:    file{"hevc-2160p.mkv", "hevc-2160p.mkv", 2048},
+			same: false,
+		},
+		{
+			name: "sdr vs hdr10",
+			a:    file{"hevc-2160p.mkv", "hevc-2160p.mkv", 2048},
+			b:    file{"hevc-2160p-hdr10.mkv", "hevc-2160p-hdr10.mkv", 2048},
+			same: false,
+		},
+		{
+			name: "hdr10 vs dolby vision",
+			a:    file{"hevc-2160p-hdr10.mkv", "hevc-2160p-hdr10.mkv", 2048},
+			b:    file{"hevc-2160p-dv.mkv", "hevc-2160p-dv.mkv", 2048},
+			same: false,
+		},
+		{
+			name: "one not probed",
+			a:    file{"hevc-1080p.mkv", "hevc-1080p.mkv", 2048},
+			b:    file{"broken.mkv", "", 2048},
+			same: false,
+		},
+		{
+			// with no probe data only the extension and size can be compared
+			name: "neither probed",
+			a:    file{"broken.mkv", "", 2048},
+			b:    file{"broken.mkv", "", 2048},
+			same: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fv := contenttest.New(t, t.TempDir())
+			a := loadVideo(t, fv.Add(filepath.Join("a", tc.a.name), tc.a.fixture, tc.a.size))
+			b := loadVideo(t, fv.Add(filepath.Join("b", tc.b.name), tc.b.fixture, tc.b.size))
+
+			if got := a.IsBasicallyTheSameTo(b); got != tc.same {
+				t.Errorf("a.IsBasicallyTheSameTo(b): got %t, want %t", got, tc.same)
+			}
+			if got := b.IsBasicallyTheSameTo(a); got != tc.same {
+				t.Errorf("b.IsBasicallyTheSameTo(a): got %t, want %t", got, tc.same)
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+package journal
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Journal is a persistent sqlite log of every file operation performed, grouped by session
+type Journal struct {
+	db *sql.DB
+}
+
+// Session is a single run of the tool
+type Session struct {
+	ID         string
+	Started    time.Time
+	Command    string
+	Operations int
+	Undone     int
+	Decisions  int
+}
+
+// Entry is a single logged file operation
+type Entry struct {
+	ID      int64
+	Session string
+	Time    time.Time
+	Op      string
+	Src     string
+	Dst     string
+	Trash   string // where a deleted item was moved to, empty if it was permanently deleted
+	Undone  bool
+}
+
+// Decision is an automatic import decision made by a policy rule
+type Decision struct {
+	ID       int64
+	Session  string
+	Time     time.Time
+	Item     string
+	Decision string
+	Rule     string
+
+	SourceScore float64 // quality score of the incoming video
+	DestScore   float64 // best quality score of the videos already in the library
+}
+
+const schema = `
+CREATE TABLE IF NOT EXISTS sessions (
+	id      TEXT PRIMARY KEY,
+	started TEXT NOT NULL,
+	command TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS operations (
+	id      INTEGER PRIMARY KEY AUTOINCREMENT,
+	session TEXT NOT NULL REFERENCES sessions(id),
+	time    TEXT NOT NULL,
+	op      TEXT NOT NULL,
+	src     TEXT NOT NULL,
+	dst     TEXT NOT NULL DEFAULT '',
+	trash   TEXT NOT NULL DEFAULT '',
+	undone  INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS operations_session ON operations(session);
+
+CREATE TABLE IF NOT EXISTS decisions (
+	id        INTEGER PRIMARY KEY AUTOINCREMENT,
+	session   TEXT NOT NULL REFERENCES sessions(id),
+	time      TEXT NOT NULL,
+	item      TEXT NOT NULL,
+	decision  TEXT NOT NULL,
+	rule      TEXT NOT NULL,
+	src_score REAL NOT NULL DEFAULT 0,
+	dst_score REAL NOT NULL DEFAULT 0
+);
+`
+
+// DefaultPath returns the default journal location in the users config folder
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("error finding home directory: %w", err)
+	}
+
+	return filepath.Join(home, ".config", "go-ingest-media", "journal.db"), nil
+}
+
+// Open opens (creating if needed) the journal database at path
+func Open(path string) (*Journal, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("error creating journal folder: %w", err)
+	}
+
+	db, err := sql.Open("sqlite3", path+"?_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		return nil, fmt.Errorf("error opening journal %s: %w", path, err)
+	}
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error creating journal tables: %w", err)
+	}
+
+	return &Journal{db: db}, nil
+}
+
+// Close closes the journal database
+func (j *Journal) Close() error {
+	return j.db.Close()
+}
+
+// NewSessionID returns a new sortable session id ie 20240131-154501-a1b2
+func NewSessionID() string {
+	b := make([]byte, 2)
+	_, _ = rand.Read(b)
+	return time.Now().Format("20060102-150405") + "-" + hex.EncodeToString(b)
+}
+
+// StartSession records a new session, it is safe to call more than once for the same id
+func (j *Journal) StartSession(id, command string) error {
+	_, err := j.db.Exec(`INSERT OR IGNORE INTO sessions (id, started, command) VALUES (?, ?, ?)`,
+		id, time.Now().Format(time.RFC3339), command)
+	if err != nil {
+		return fmt.Errorf("error recording session: %w", err)
+	}
+
+	return nil
+}
+
+// Record logs a completed operation for a session
+func (j *Journal) Record(session, op, src, dst, trash string) error {
+	_, err := j.db.Exec(`INSERT INTO operations (session, time, op, src, dst, trash) VALUES (?, ?, ?, ?, ?, ?)`,
+		session, time.Now().Format(time.RFC3339Nano), op, src, dst, trash)
+	if err != nil {
+		return fmt.Errorf("error recording operation: %w", err)
+	}
+
+	return nil
+}
+
+// Sessions returns the most recent sessions that performed at least one operation or made a policy decision, newest
+// first
+func (j *Journal) Sessions(limit int) ([]Session, error) {
+	rows, err := j.db.Query(`
+		SELECT s.id, s.started, s.command, COUNT(o.id), COALESCE(SUM(o.undone), 0),
+			(SELECT COUNT(*) FROM decisions d WHERE d.session = s.id) AS decisions
+		FROM sessions s LEFT JOIN operations o ON o.session = s.id
+		GROUP BY s.id
+		HAVING COUNT(o.id) > 0 OR decisions > 0
+		ORDER BY s.started DESC, s.id DESC
+		LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error listing sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []Session
+	for rows.Next() {
+		var s Session
+		var started string
+		if err := rows.Scan(&s.ID, &started, &s.Command, &s.Operations, &s.Undone, &s.Decisions); err != nil {
+			return nil, fmt.Errorf("error reading session: %w", err)
+		}
+		s.Started, _ = time.Parse(time.RFC3339, started)
+		sessions = append(sessions, s)
+	}
+
+	return sessions, rows.Err()
+}
+
+// LastUndoable returns the most recent session other than exclude with operations that have not been undone
+func (j *Journal) LastUndoable(exclude string) (string, error) {
+	var id string
+	err := j.db.QueryRow(`
+		SELECT s.id FROM sessions s JOIN operations o ON o.session = s.id
+		WHERE s.id != ? AND o.undone = 0
+		ORDER BY s.started DESC, s.id DESC
+		LIMIT 1`, exclude).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", errors.New("no sessions with operations to undo")
+	}
+	if err != nil {
+		return "", fmt.Errorf("error finding last session: %w", err)
+	}
+
+	return id, nil
+}
+
+// Entries returns all operations for a session in the order they were performed
+func (j *Journal) Entries(session string) ([]Entry, error) {
+	rows, err := j.db.Query(`
+		SELECT id, session, time, op, src, dst, trash, undone
+		FROM operations WHERE session = ? ORDER BY id`, session)
+	if err != nil {
+		return nil, fmt.Errorf("error listing operations: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var e Entry
+		var t string
+		if err := rows.Scan(&e.ID, &e.Session, &t, &e.Op, &e.Src, &e.Dst, &e.Trash, &e.Undone); err != nil {
+			return nil, fmt.Errorf("error reading operation: %w", err)
+		}
+		e.Time, _ = time.Parse(time.RFC3339Nano, t)
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// MarkUndone flags an operation as having been undone
+func (j *Journal) MarkUndone(id int64) error {
+	if _, err := j.db.Exec(`UPDATE operations SET undone = 1 WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("error marking operation %d undone: %w", id, err)
+	}
+
+	return nil
+}
+
+// RecordDecision logs an automatic decision, the rule that made it and the quality scores it was made between
+func (j *Journal) RecordDecision(session, item, decision, rule string, srcScore, dstScore float64) error {
+	_, err := j.db.Exec(`INSERT INTO decisions (session, time, item, decision, rule, src_score, dst_score) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		session, time.Now().Format(time.RFC3339Nano), item, decision, rule, srcScore, dstScore)
+	if err != nil {
+		return fmt.Errorf("error recording decision: %w", err)
+	}
+
+	return nil
+}
+
+// Decisions returns all automatic decisions made in a session in order
+func (j *Journal) Decisions(session string) ([]Decision, error) {
+	rows, err := j.db.Query(`
+		SELECT id, session, time, item, decision, rule, src_score, dst_score
+		FROM decisions WHERE session = ? ORDER BY id`, session)
+	if err != nil {
+		return nil, fmt.Errorf("error listing decisions: %w", err)
+	}
+	defer rows.Close()
+
+	var decisions []Decision
+	for rows.Next() {
+		var d Decision
+		var t string
+		if err := rows.Scan(&d.ID, &d.Session, &t, &d.Item, &d.Decision, &d.Rule, &d.SourceScore, &d.DestScore); err != nil {
+			return nil, fmt.Errorf("error reading decision: %w", err)
+		}
+		d.Time, _ = time.Parse(time.RFC3339Nano, t)
+		decisions = append(decisions, d)
+	}
+
+	return decisions, rows.Err()
+}
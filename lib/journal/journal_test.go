@@ -0,0 +1,60 @@
+package journal
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func openJournal(t *testing.T) *Journal {
+	t.Helper()
+
+	j, err := Open(filepath.Join(t.TempDir(), "journal.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { j.Close() })
+	return j
+}
+
+func TestSessions(t *testing.T) {
+	j := openJournal(t)
+
+	for _, id := range []string{"1-moves", "2-decisions", "3-empty"} {
+		if err := j.StartSession(id, "import"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := j.Record("1-moves", "move", "/a", "/b", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.RecordDecision("2-decisions", "Movie (2000)", "keep", "score < 50", 42.5, 80); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, err := j.Sessions(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]Session{}
+	for _, s := range sessions {
+		got[s.ID] = s
+	}
+	if len(got) != 2 {
+		t.Fatalf("got sessions %+v, want the two that did something", sessions)
+	}
+	if s := got["1-moves"]; s.Operations != 1 || s.Decisions != 0 {
+		t.Errorf("moves: got %d ops %d decisions", s.Operations, s.Decisions)
+	}
+	if s := got["2-decisions"]; s.Operations != 0 || s.Decisions != 1 {
+		t.Errorf("decisions: got %d ops %d decisions", s.Operations, s.Decisions)
+	}
+
+	decisions, err := j.Decisions("2-decisions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decisions) != 1 || decisions[0].SourceScore != 42.5 || decisions[0].DestScore != 80 {
+		t.Errorf("got decisions %+v", decisions)
+	}
+}
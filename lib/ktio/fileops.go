@@ -0,0 +1,434 @@
+package ktio
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/spf13/afero"
+	"golang.org/x/sys/unix"
+)
+
+var (
+	// ErrDestinationExists is returned when a move would replace an existing folder or replace a file with a folder
+	ErrDestinationExists = errors.New("destination already exists")
+
+	// ErrCrossDevice is returned when a rename crosses filesystems and the data would have to be copied
+	ErrCrossDevice = errors.New("source and destination are on different filesystems")
+
+	// ErrPermissionDenied is returned when the operation is not permitted
+	ErrPermissionDenied = errors.New("permission denied")
+
+	// ErrChecksumMismatch is returned when a verified copy does not match the source, the source is kept
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+)
+
+// verify enables hashing the source and destination of cross device copies before the source is removed
+var verify bool
+
+// verifyHash is the name and constructor of the hash verified copies are compared with
+var (
+	verifyHashName = "sha256"
+	verifyHash     = sha256.New
+)
+
+// verifyHashes are the hashes verification can use, xxhash is much faster but not cryptographic
+var verifyHashes = map[string]func() hash.Hash{
+	"sha256": sha256.New,
+	"xxhash": func() hash.Hash { return xxhash.New() },
+}
+
+// SetVerify enables or disables checksum verification of cross device moves
+func SetVerify(enabled bool) {
+	verify = enabled
+}
+
+// SetVerifyHash sets the hash used to verify copies, sha256 or xxhash
+func SetVerifyHash(name string) error {
+	h, ok := verifyHashes[name]
+	if !ok {
+		return fmt.Errorf("unknown hash %q (expected sha256 or xxhash)", name)
+	}
+
+	verifyHashName, verifyHash = name, h
+	return nil
+}
+
+// ProgressFunc is called as bytes are copied during a cross device move
+type ProgressFunc func(path string, done, total int64)
+
+// classify wraps an error from the os package with the matching typed error so callers can use errors.Is
+func classify(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, syscall.EXDEV):
+		return fmt.Errorf("%w: %w", ErrCrossDevice, err)
+	case errors.Is(err, fs.ErrExist):
+		return fmt.Errorf("%w: %w", ErrDestinationExists, err)
+	case errors.Is(err, fs.ErrPermission):
+		return fmt.Errorf("%w: %w", ErrPermissionDenied, err)
+	default:
+		return err
+	}
+}
+
+// MovePath moves src to the exact path dst. Files replace an existing file like mv does, folders never replace anything.
+// A rename is used when both are on the same filesystem, otherwise the data is copied (with fsync and preserving
+// permissions and modification times) and the source removed once the copy is complete.
+// mv -v style output is written to w
+func MovePath(src, dst string, w io.Writer, progress ProgressFunc) error {
+	if w == nil {
+		w = io.Discard
+	}
+
+	srcInfo, err := lstat(src)
+	if err != nil {
+		return classify(err)
+	}
+
+	if dstInfo, err := lstat(dst); err == nil && (srcInfo.IsDir() || dstInfo.IsDir()) {
+		return fmt.Errorf("%w: %s", ErrDestinationExists, dst)
+	}
+
+	err = fsys.Rename(src, dst)
+	if err == nil {
+		fmt.Fprintf(w, "renamed '%s' -> '%s'\n", src, dst)
+		return nil
+	}
+	if !errors.Is(err, syscall.EXDEV) {
+		return classify(err)
+	}
+
+	// different filesystems, copy then remove
+	total, err := treeSize(src)
+	if err != nil {
+		return classify(err)
+	}
+
+	c := copier{w: w, progress: progress, total: total, verify: verify}
+	if srcInfo.IsDir() {
+		err = c.copyDir(src, dst, srcInfo)
+	} else {
+		err = c.copyFileAtomic(src, dst, srcInfo)
+	}
+	if err != nil {
+		if srcInfo.IsDir() {
+			_ = fsys.RemoveAll(dst)
+		}
+		return classify(err)
+	}
+
+	if err := syncDir(filepath.Dir(dst)); err != nil {
+		return classify(err)
+	}
+
+	return RemoveTree(src, w)
+}
+
+// RemovePath removes a single file, writing rm -v style output to w
+func RemovePath(path string, w io.Writer) error {
+	if info, err := lstat(path); err == nil && info.IsDir() {
+		return fmt.Errorf("cannot remove %s: is a directory", path)
+	}
+
+	if err := remove(path); err != nil {
+		return classify(err)
+	}
+
+	fmt.Fprintf(orDiscard(w), "removed '%s'\n", path)
+	return nil
+}
+
+// RemoveTree removes a file or folder and everything in it, writing rm -rfv style output to w
+func RemoveTree(path string, w io.Writer) error {
+	w = orDiscard(w)
+
+	info, err := lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return classify(err)
+	}
+
+	if info.IsDir() {
+		entries, err := readDirEntries(path)
+		if err != nil {
+			return classify(err)
+		}
+		for _, e := range entries {
+			if err := RemoveTree(filepath.Join(path, e.name), w); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := remove(path); err != nil {
+		return classify(err)
+	}
+
+	if info.IsDir() {
+		fmt.Fprintf(w, "removed directory '%s'\n", path)
+	} else {
+		fmt.Fprintf(w, "removed '%s'\n", path)
+	}
+	return nil
+}
+
+// RemoveEmptyDir removes a folder only if it is empty, writing rmdir -v style output to w
+func RemoveEmptyDir(path string, w io.Writer) error {
+	info, err := lstat(path)
+	if err != nil {
+		return classify(err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("failed to remove '%s': not a directory", path)
+	}
+
+	if err := remove(path); err != nil {
+		return classify(err)
+	}
+
+	fmt.Fprintf(orDiscard(w), "rmdir: removing directory, '%s'\n", path)
+	return nil
+}
+
+func orDiscard(w io.Writer) io.Writer {
+	if w == nil {
+		return io.Discard
+	}
+	return w
+}
+
+// treeSize returns the total size of all files under path
+func treeSize(path string) (int64, error) {
+	var total int64
+	err := afero.Walk(fsys, path, func(_ string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total, err
+}
+
+// copier copies files and folders across filesystems reporting progress over the whole tree
+type copier struct {
+	w        io.Writer
+	progress ProgressFunc
+	total    int64
+	done     int64
+	verify   bool
+}
+
+func (c *copier) copyDir(src, dst string, info os.FileInfo) error {
+	if err := fsys.Mkdir(dst, info.Mode().Perm()|0o700); err != nil {
+		return err
+	}
+
+	entries, err := readDirEntries(src)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		s := filepath.Join(src, e.name)
+		d := filepath.Join(dst, e.name)
+
+		ei, err := lstat(s)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case ei.IsDir():
+			err = c.copyDir(s, d, ei)
+		case ei.Mode()&fs.ModeSymlink != 0:
+			err = copySymlink(s, d)
+		default:
+			err = c.copyFile(s, d, d, ei)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := syncDir(dst); err != nil {
+		return err
+	}
+
+	// permissions and times last so adding the contents doesn't change them
+	if err := fsys.Chmod(dst, info.Mode().Perm()); err != nil {
+		return err
+	}
+	return fsys.Chtimes(dst, info.ModTime(), info.ModTime())
+}
+
+// copyFileAtomic copies to a temporary file next to dst and renames it into place so dst is never partially written
+func (c *copier) copyFileAtomic(src, dst string, info os.FileInfo) error {
+	if info.Mode()&fs.ModeSymlink != 0 {
+		return copySymlink(src, dst)
+	}
+
+	tmp := filepath.Join(filepath.Dir(dst), "."+filepath.Base(dst)+".ingest-tmp")
+	if err := c.copyFile(src, tmp, dst, info); err != nil {
+		_ = fsys.Remove(tmp)
+		return err
+	}
+
+	if err := fsys.Rename(tmp, dst); err != nil {
+		_ = fsys.Remove(tmp)
+		return err
+	}
+
+	return nil
+}
+
+// copyFile copies a single file to dst, name is the path shown in the output
+func (c *copier) copyFile(src, dst, name string, info os.FileInfo) error {
+	in, err := fsys.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := fsys.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	// when verifying the source is hashed as it is copied so it is only read once
+	var srcHash hash.Hash
+	var w io.Writer = out
+	if c.verify {
+		srcHash = verifyHash()
+		w = io.MultiWriter(out, srcHash)
+	}
+	pw := &progressWriter{w: w, c: c, path: src}
+	if _, err := io.Copy(pw, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	// the umask may have changed the permissions when creating
+	if err := fsys.Chmod(dst, info.Mode().Perm()); err != nil {
+		return err
+	}
+	if err := fsys.Chtimes(dst, info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.w, "copied '%s' -> '%s'\n", src, name)
+
+	if c.verify {
+		sum, err := hashFile(dst)
+		if err != nil {
+			return fmt.Errorf("error verifying %s: %w", name, err)
+		}
+		if want := hex.EncodeToString(srcHash.Sum(nil)); sum != want {
+			return fmt.Errorf("%w: %s (%s %s) != %s (%s %s)", ErrChecksumMismatch, src, verifyHashName, short(want), name, verifyHashName, short(sum))
+		}
+		fmt.Fprintf(c.w, "verified '%s' (%s %s)\n", name, verifyHashName, short(sum))
+	}
+
+	return nil
+}
+
+// hashFile returns the hex hash of a file streamed from disk. The file's pages are dropped from the page cache first
+// so what was written is read back from the disk rather than memory
+func hashFile(path string) (string, error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if osf, ok := f.(*os.File); ok {
+		// the file has been synced so its pages are clean and can be dropped
+		if err := unix.Fadvise(int(osf.Fd()), 0, 0, unix.FADV_DONTNEED); err != nil {
+			return "", fmt.Errorf("error dropping cached pages: %w", err)
+		}
+	}
+
+	h := verifyHash()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// short returns the start of a hex hash for output
+func short(sum string) string {
+	if len(sum) > 16 {
+		return sum[:16]
+	}
+	return sum
+}
+
+func copySymlink(src, dst string) error {
+	r, ok := fsys.(afero.LinkReader)
+	l, ok2 := fsys.(afero.Linker)
+	if !ok || !ok2 {
+		return fmt.Errorf("cannot copy symlink %s: %w", src, afero.ErrNoSymlink)
+	}
+
+	target, err := r.ReadlinkIfPossible(src)
+	if err != nil {
+		return err
+	}
+	return l.SymlinkIfPossible(target, dst)
+}
+
+// syncDir fsyncs a folder so new entries in it are durable
+func syncDir(path string) error {
+	if !realFS() {
+		return nil
+	}
+
+	d, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	if err := d.Sync(); err != nil && !errors.Is(err, syscall.EINVAL) {
+		return err
+	}
+	return nil
+}
+
+// progressWriter reports progress as data is written
+type progressWriter struct {
+	w    io.Writer
+	c    *copier
+	path string
+}
+
+func (pw *progressWriter) Write(p []byte) (int, error) {
+	n, err := pw.w.Write(p)
+	pw.c.done += int64(n)
+	if pw.c.progress != nil {
+		pw.c.progress(pw.path, pw.c.done, pw.c.total)
+	}
+	return n, err
+}
@@ -0,0 +1,405 @@
+package ktio
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// devicesFs is an in memory filesystem where each top level folder is a separate device, renames between them fail
+// with EXDEV like they would across mounts
+type devicesFs struct {
+	afero.Fs
+}
+
+func (d devicesFs) Rename(oldname, newname string) error {
+	if device(oldname) != device(newname) {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: syscall.EXDEV}
+	}
+	return d.Fs.Rename(oldname, newname)
+}
+
+func device(path string) string {
+	return strings.SplitN(strings.TrimPrefix(filepath.Clean(path), "/"), "/", 2)[0]
+}
+
+// useFS replaces the filesystem for the test
+func useFS(t *testing.T, f afero.Fs) afero.Fs {
+	t.Helper()
+
+	SetFS(f)
+	t.Cleanup(func() { SetFS(nil) })
+
+	return f
+}
+
+// writeFile creates a file and its parents with the given mode and modification time
+func writeFile(t *testing.T, f afero.Fs, path, data string, mode os.FileMode, mtime time.Time) {
+	t.Helper()
+
+	if err := f.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := afero.WriteFile(f, path, []byte(data), mode); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Chmod(path, mode); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMovePathRename(t *testing.T) {
+	f := useFS(t, devicesFs{afero.NewMemMapFs()})
+	writeFile(t, f, "/a/src/movie.mkv", "movie", 0o644, time.Now())
+	if err := f.MkdirAll("/a/dst", 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	if err := MovePath("/a/src/movie.mkv", "/a/dst/movie.mkv", &out, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "renamed '/a/src/movie.mkv' -> '/a/dst/movie.mkv'\n"; out.String() != want {
+		t.Errorf("got output %q, want %q", out.String(), want)
+	}
+	if ok, _ := afero.Exists(f, "/a/src/movie.mkv"); ok {
+		t.Error("source still exists")
+	}
+	if b, _ := afero.ReadFile(f, "/a/dst/movie.mkv"); string(b) != "movie" {
+		t.Errorf("got destination %q, want movie", b)
+	}
+}
+
+func TestMovePathCrossDeviceFile(t *testing.T) {
+	f := useFS(t, devicesFs{afero.NewMemMapFs()})
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	writeFile(t, f, "/a/movie.mkv", "movie", 0o640, mtime)
+	if err := f.MkdirAll("/b", 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	var done, total int64
+	progress := func(_ string, d, t int64) { done, total = d, t }
+	if err := MovePath("/a/movie.mkv", "/b/movie.mkv", &out, progress); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "copied '/a/movie.mkv' -> '/b/movie.mkv'\nremoved '/a/movie.mkv'\n"; out.String() != want {
+		t.Errorf("got output %q, want %q", out.String(), want)
+	}
+	if done != 5 || total != 5 {
+		t.Errorf("got progress %d/%d, want 5/5", done, total)
+	}
+	if ok, _ := afero.Exists(f, "/a/movie.mkv"); ok {
+		t.Error("source still exists")
+	}
+	if ok, _ := afero.Exists(f, "/b/.movie.mkv.ingest-tmp"); ok {
+		t.Error("temporary file was left behind")
+	}
+
+	info, err := f.Stat("/b/movie.mkv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o640 {
+		t.Errorf("got mode %v, want %v", info.Mode().Perm(), os.FileMode(0o640))
+	}
+	if !info.ModTime().Equal(mtime) {
+		t.Errorf("got mtime %v, want %v", info.ModTime(), mtime)
+	}
+}
+
+func TestMovePathCrossDeviceDir(t *testing.T) {
+	f := useFS(t, devicesFs{afero.NewMemMapFs()})
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	writeFile(t, f, "/a/Alien (1979)/Alien (1979).mkv", "alien", 0o600, mtime)
+	writeFile(t, f, "/a/Alien (1979)/extras/trailer.mkv", "trailer", 0o644, mtime)
+	if err := f.Chmod("/a/Alien (1979)", 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Chtimes("/a/Alien (1979)", mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.MkdirAll("/b", 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := MovePath("/a/Alien (1979)", "/b/Alien (1979)", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, _ := afero.Exists(f, "/a/Alien (1979)"); ok {
+		t.Error("source still exists")
+	}
+
+	cases := map[string]os.FileMode{
+		"/b/Alien (1979)":                    0o750,
+		"/b/Alien (1979)/Alien (1979).mkv":   0o600,
+		"/b/Alien (1979)/extras/trailer.mkv": 0o644,
+	}
+	for path, mode := range cases {
+		info, err := f.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != mode {
+			t.Errorf("%s: got mode %v, want %v", path, info.Mode().Perm(), mode)
+		}
+		if !info.ModTime().Equal(mtime) {
+			t.Errorf("%s: got mtime %v, want %v", path, info.ModTime(), mtime)
+		}
+	}
+	if b, _ := afero.ReadFile(f, "/b/Alien (1979)/extras/trailer.mkv"); string(b) != "trailer" {
+		t.Errorf("got trailer %q, want trailer", b)
+	}
+}
+
+func TestMovePathExistingDestination(t *testing.T) {
+	cases := []struct {
+		name string
+		src  string
+		dst  string
+		keep string // what is already at the destination
+	}{
+		{name: "folder onto a folder", src: "/a/src", dst: "/a/dst", keep: "/a/dst/other.mkv"},
+		{name: "folder onto a file", src: "/a/src", dst: "/a/file.mkv", keep: "/a/file.mkv"},
+		{name: "file onto a folder", src: "/a/src/movie.mkv", dst: "/a/dst", keep: "/a/dst/other.mkv"},
+		{name: "folder onto a folder on another device", src: "/a/src", dst: "/b/dst", keep: "/b/dst/other.mkv"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := useFS(t, devicesFs{afero.NewMemMapFs()})
+			writeFile(t, f, "/a/src/movie.mkv", "movie", 0o644, time.Now())
+			writeFile(t, f, "/a/file.mkv", "file", 0o644, time.Now())
+			writeFile(t, f, "/a/dst/other.mkv", "other", 0o644, time.Now())
+			writeFile(t, f, "/b/dst/other.mkv", "other", 0o644, time.Now())
+
+			err := MovePath(tc.src, tc.dst, nil, nil)
+			if !errors.Is(err, ErrDestinationExists) {
+				t.Fatalf("got %v, want ErrDestinationExists", err)
+			}
+			if ok, _ := afero.Exists(f, "/a/src/movie.mkv"); !ok {
+				t.Error("source was removed")
+			}
+			if ok, _ := afero.Exists(f, tc.keep); !ok {
+				t.Errorf("%s was removed", tc.keep)
+			}
+		})
+	}
+}
+
+func TestMovePathReplacesFile(t *testing.T) {
+	f := useFS(t, devicesFs{afero.NewMemMapFs()})
+	writeFile(t, f, "/a/movie.mkv", "new", 0o644, time.Now())
+	writeFile(t, f, "/b/movie.mkv", "old", 0o644, time.Now())
+
+	if err := MovePath("/a/movie.mkv", "/b/movie.mkv", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if b, _ := afero.ReadFile(f, "/b/movie.mkv"); string(b) != "new" {
+		t.Errorf("got destination %q, want new", b)
+	}
+}
+
+func TestMovePathMissingSource(t *testing.T) {
+	useFS(t, devicesFs{afero.NewMemMapFs()})
+
+	if err := MovePath("/a/missing.mkv", "/b/missing.mkv", nil, nil); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("got %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestCopyFileAtomic(t *testing.T) {
+	f := useFS(t, afero.NewMemMapFs())
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	writeFile(t, f, "/a/movie.mkv", "movie", 0o600, mtime)
+	if err := f.MkdirAll("/b", 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := f.Stat("/a/movie.mkv")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	c := copier{w: &out}
+	if err := c.copyFileAtomic("/a/movie.mkv", "/b/movie.mkv", info); err != nil {
+		t.Fatal(err)
+	}
+
+	// the output names the destination not the temporary file it was written to
+	if want := "copied '/a/movie.mkv' -> '/b/movie.mkv'\n"; out.String() != want {
+		t.Errorf("got output %q, want %q", out.String(), want)
+	}
+	if ok, _ := afero.Exists(f, "/a/movie.mkv"); !ok {
+		t.Error("source was removed")
+	}
+	if names, _ := afero.ReadDir(f, "/b"); len(names) != 1 {
+		t.Errorf("got %d files in the destination, want 1", len(names))
+	}
+
+	dst, err := f.Stat("/b/movie.mkv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dst.Mode().Perm() != 0o600 || !dst.ModTime().Equal(mtime) {
+		t.Errorf("got mode %v mtime %v, want %v %v", dst.Mode().Perm(), dst.ModTime(), os.FileMode(0o600), mtime)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	other := errors.New("something else")
+
+	cases := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "cross device", err: &os.LinkError{Op: "rename", Old: "/a", New: "/b", Err: syscall.EXDEV}, want: ErrCrossDevice},
+		{name: "exists", err: &fs.PathError{Op: "mkdir", Path: "/a", Err: syscall.EEXIST}, want: ErrDestinationExists},
+		{name: "permission", err: &fs.PathError{Op: "open", Path: "/a", Err: syscall.EACCES}, want: ErrPermissionDenied},
+		{name: "other", err: other, want: other},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := classify(tc.err)
+			if !errors.Is(err, tc.want) {
+				t.Errorf("got %v, want it to be %v", err, tc.want)
+			}
+			if !errors.Is(err, tc.err) {
+				t.Errorf("got %v, want it to wrap %v", err, tc.err)
+			}
+		})
+	}
+
+	if err := classify(nil); err != nil {
+		t.Errorf("got %v for nil, want nil", err)
+	}
+	if err := classify(fmt.Errorf("wrapped: %w", other)); errors.Is(err, ErrCrossDevice) || errors.Is(err, ErrDestinationExists) || errors.Is(err, ErrPermissionDenied) {
+		t.Errorf("got %v, want it to be untyped", err)
+	}
+}
+
+// corruptFs is a devicesFs that flips the first byte of everything written to a new file, like a bad disk or cable
+type corruptFs struct {
+	devicesFs
+}
+
+func (c corruptFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	f, err := c.devicesFs.OpenFile(name, flag, perm)
+	if err != nil || flag&os.O_CREATE == 0 {
+		return f, err
+	}
+	return &corruptFile{File: f}, nil
+}
+
+type corruptFile struct {
+	afero.File
+	written bool
+}
+
+func (f *corruptFile) Write(p []byte) (int, error) {
+	if !f.written && len(p) > 0 {
+		f.written = true
+		b := append([]byte{}, p...)
+		b[0] ^= 0xff
+		return f.File.Write(b)
+	}
+	return f.File.Write(p)
+}
+
+// useVerify enables verified copies with the given hash for the test
+func useVerify(t *testing.T, hash string) {
+	t.Helper()
+
+	SetVerify(true)
+	if err := SetVerifyHash(hash); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		SetVerify(false)
+		_ = SetVerifyHash("sha256")
+	})
+}
+
+func TestMovePathVerify(t *testing.T) {
+	for _, hash := range []string{"sha256", "xxhash"} {
+		t.Run(hash, func(t *testing.T) {
+			f := useFS(t, devicesFs{afero.NewMemMapFs()})
+			useVerify(t, hash)
+			writeFile(t, f, "/a/movie.mkv", "movie", 0o644, time.Now())
+			if err := f.MkdirAll("/b", 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			var out strings.Builder
+			if err := MovePath("/a/movie.mkv", "/b/movie.mkv", &out, nil); err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.Contains(out.String(), "verified '/b/movie.mkv' ("+hash+" ") {
+				t.Errorf("got output %q, want it verified with %s", out.String(), hash)
+			}
+			if ok, _ := afero.Exists(f, "/a/movie.mkv"); ok {
+				t.Error("source still exists")
+			}
+		})
+	}
+}
+
+func TestMovePathVerifyMismatch(t *testing.T) {
+	cases := []struct {
+		name string
+		src  string
+		dst  string
+	}{
+		{name: "file", src: "/a/movie.mkv", dst: "/b/movie.mkv"},
+		{name: "folder", src: "/a/Alien (1979)", dst: "/b/Alien (1979)"},
+	}
+
+	for _, hash := range []string{"sha256", "xxhash"} {
+		for _, tc := range cases {
+			t.Run(hash+"/"+tc.name, func(t *testing.T) {
+				f := useFS(t, corruptFs{devicesFs{afero.NewMemMapFs()}})
+				useVerify(t, hash)
+				writeFile(t, f, "/a/movie.mkv", "movie", 0o644, time.Now())
+				writeFile(t, f, "/a/Alien (1979)/Alien (1979).mkv", "alien", 0o644, time.Now())
+				if err := f.MkdirAll("/b", 0o755); err != nil {
+					t.Fatal(err)
+				}
+
+				err := MovePath(tc.src, tc.dst, nil, nil)
+				if !errors.Is(err, ErrChecksumMismatch) {
+					t.Fatalf("got %v, want ErrChecksumMismatch", err)
+				}
+				if !strings.Contains(err.Error(), "("+hash+" ") {
+					t.Errorf("got %v, want it to name %s", err, hash)
+				}
+
+				if ok, _ := afero.Exists(f, tc.src); !ok {
+					t.Error("source was removed")
+				}
+				if entries, _ := afero.ReadDir(f, "/b"); len(entries) != 0 {
+					t.Errorf("got %d entries in the destination, want the partial copy removed", len(entries))
+				}
+			})
+		}
+	}
+}
@@ -1,9 +1,7 @@
 package ktio
 
 import (
-	"errors"
 	"fmt"
-	"os"
 	"path/filepath"
 	"strings"
 )
@@ -11,14 +9,14 @@ import (
 func ListFolders(ipath string) ([]string, error) {
 	paths := []string{}
 
-	files, err := os.ReadDir(ipath)
+	files, err := readDir(ipath)
 	if err != nil {
 		return nil, fmt.Errorf("error reading the path %v: %w", ipath, err)
 	}
 
 	for _, file := range files {
-		if file.IsDir() {
-			fullPath := filepath.Join(ipath, file.Name())
+		if file.dir {
+			fullPath := filepath.Join(ipath, file.name)
 			paths = append(paths, fullPath)
 		}
 	}
@@ -29,14 +27,14 @@ func ListFolders(ipath string) ([]string, error) {
 func ListFiles(ipath string) ([]string, error) {
 	var paths []string
 
-	files, err := os.ReadDir(ipath)
+	files, err := readDir(ipath)
 	if err != nil {
 		return nil, fmt.Errorf("error reading the path %v: %w", ipath, err)
 	}
 
 	for _, file := range files {
-		if !file.IsDir() {
-			fullPath := filepath.Join(ipath, file.Name())
+		if !file.dir {
+			fullPath := filepath.Join(ipath, file.name)
 			paths = append(paths, fullPath)
 		}
 	}
@@ -45,7 +43,7 @@ func ListFiles(ipath string) ([]string, error) {
 }
 
 func ListFilesAndFolders(ipath string) ([]string, error) {
-	files, err := os.ReadDir(ipath)
+	files, err := readDir(ipath)
 	if err != nil {
 		return nil, fmt.Errorf("error reading the path %v: %w", ipath, err)
 	}
@@ -53,7 +51,7 @@ func ListFilesAndFolders(ipath string) ([]string, error) {
 	paths := make([]string, 0, len(files))
 
 	for _, file := range files {
-		fullPath := filepath.Join(ipath, file.Name())
+		fullPath := filepath.Join(ipath, file.name)
 		paths = append(paths, fullPath)
 	}
 
@@ -61,27 +59,18 @@ func ListFilesAndFolders(ipath string) ([]string, error) {
 }
 
 func PathExists(path string) bool {
-	_, err := os.Stat(path)
-	return err == nil
-}
-
-func Move(source, destination string) error {
-	// Check if destination already exists
-	if _, err := os.Stat(destination); !os.IsNotExist(err) {
-		return errors.New("destination folder already exists")
-	}
-
-	// MoveFolder the folder
-	err := os.Rename(source, destination)
-	if err != nil {
-		return fmt.Errorf("error while moving folder: %w", err)
+	if planning() {
+		plan.mu.Lock()
+		defer plan.mu.Unlock()
+		return plan.exists(path)
 	}
 
-	return nil
+	_, err := fsys.Stat(path)
+	return err == nil
 }
 
 func FolderEmpty(dirPath string) (bool, error) {
-	entries, err := os.ReadDir(dirPath)
+	entries, err := readDir(dirPath)
 	if err != nil {
 		return false, err
 	}
@@ -96,7 +85,7 @@ func DeleteIfEmpty(path string, prompt bool, indent int) error {
 	}
 
 	if empty {
-		if err := RunCommand(indent, prompt, "rmdir", "-v", path); err != nil {
+		if err := RemoveDir(indent, prompt, path); err != nil {
 			return fmt.Errorf("error deleting empty folder: %w", err)
 		}
 	}
@@ -127,7 +116,7 @@ func DeleteIfEmptyOrOnlyJunk(path string, prompt bool, indent int) error {
 	for _, contentPath := range srcContents {
 		ext := strings.ToLower(filepath.Ext(contentPath))
 		if junkExtensions[ext] {
-			if err := RunCommand(indent, prompt, "rm", "-v", contentPath); err != nil {
+			if err := Remove(indent, prompt, contentPath); err != nil {
 				return fmt.Errorf("error deleting junk file: %w", err)
 			}
 		}
@@ -0,0 +1,92 @@
+package ktio
+
+import (
+	"io/fs"
+	"os"
+	"syscall"
+
+	"github.com/spf13/afero"
+)
+
+// fsys is the filesystem all operations go through, the real one unless replaced (ie with an in memory tree for tests)
+var fsys afero.Fs = afero.NewOsFs()
+
+// SetFS replaces the filesystem used by all operations, nil restores the real filesystem
+func SetFS(f afero.Fs) {
+	if f == nil {
+		f = afero.NewOsFs()
+	}
+	fsys = f
+}
+
+// FS returns the filesystem used by all operations
+func FS() afero.Fs {
+	return fsys
+}
+
+// realFS returns true when operating on the real filesystem, devices, free space and fsync only exist there
+func realFS() bool {
+	_, ok := fsys.(*afero.OsFs)
+	return ok
+}
+
+// Stat returns the file info of a path on disk, it does not take planned actions into account (see RealPath)
+func Stat(path string) (os.FileInfo, error) {
+	return fsys.Stat(path)
+}
+
+// ReadFile returns the contents of a file on disk, it does not take planned actions into account (see RealPath)
+func ReadFile(path string) ([]byte, error) {
+	return afero.ReadFile(fsys, path)
+}
+
+// lstat does not follow a final symlink if the filesystem supports them
+func lstat(path string) (os.FileInfo, error) {
+	if l, ok := fsys.(afero.Lstater); ok {
+		info, _, err := l.LstatIfPossible(path)
+		return info, err
+	}
+	return fsys.Stat(path)
+}
+
+// remove removes a file or empty folder, the memory filesystem happily removes a folder with contents so that is
+// checked first to behave like os.Remove
+func remove(path string) error {
+	if !realFS() {
+		if info, err := fsys.Stat(path); err == nil && info.IsDir() {
+			if empty, err := afero.IsEmpty(fsys, path); err == nil && !empty {
+				return &fs.PathError{Op: "remove", Path: path, Err: syscall.ENOTEMPTY}
+			}
+		}
+	}
+
+	return fsys.Remove(path)
+}
+
+// readDirEntries lists a folder on disk
+func readDirEntries(path string) ([]dirEntry, error) {
+	var entries []dirEntry
+
+	// os.ReadDir avoids a stat per entry which is slow on network mounts
+	if realFS() {
+		files, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		entries = make([]dirEntry, 0, len(files))
+		for _, f := range files {
+			entries = append(entries, dirEntry{name: f.Name(), dir: f.IsDir()})
+		}
+		return entries, nil
+	}
+
+	files, err := afero.ReadDir(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+	entries = make([]dirEntry, 0, len(files))
+	for _, f := range files {
+		entries = append(entries, dirEntry{name: f.Name(), dir: f.IsDir()})
+	}
+	return entries, nil
+}
@@ -3,6 +3,7 @@ package ktio
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 
@@ -10,7 +11,19 @@ import (
 	"golang.org/x/sys/unix"
 )
 
+// keyInput replaces the keyboard when set so prompts can be answered from a script (ie in tests)
+var keyInput io.RuneReader
+
+// SetKeyInput reads key presses from r instead of the keyboard, nil restores the keyboard
+func SetKeyInput(r io.RuneReader) {
+	keyInput = r
+}
+
 func DiscardBufferedInput() {
+	if keyInput != nil {
+		return
+	}
+
 	if err := keyboard.Open(); err != nil {
 		return
 	}
@@ -79,6 +92,14 @@ func GetSelection(options ...rune) (rune, error) {
 }
 
 func GetKey() (*rune, error) {
+	if keyInput != nil {
+		char, _, err := keyInput.ReadRune()
+		if err != nil {
+			return nil, fmt.Errorf("no more key input: %w", err)
+		}
+		return &char, nil
+	}
+
 	err := keyboard.Open()
 	if err != nil {
 		return nil, err
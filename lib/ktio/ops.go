@@ -0,0 +1,297 @@
+package ktio
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gookit/color"
+	"github.com/spf13/afero"
+)
+
+// OpType is the kind of filesystem operation an Action performs
+type OpType string
+
+const (
+	OpMove      OpType = "mv"
+	OpRemove    OpType = "rm"
+	OpRemoveAll OpType = "rm -rf"
+	OpRemoveDir OpType = "rmdir"
+	OpRename    OpType = "rename"
+	OpMkdir     OpType = "mkdir"
+	OpWrite     OpType = "write"
+)
+
+// Action is a single filesystem operation, all destructive operations are described by one
+type Action struct {
+	Op    OpType `json:"op"`
+	Src   string `json:"src"`
+	Dst   string `json:"dst,omitempty"`
+	Trash string `json:"trash,omitempty"` // where a deleted path is moved to instead of being removed
+}
+
+func (a Action) String() string {
+	if a.Trash != "" {
+		return fmt.Sprintf("%s %s (trash: %s)", a.Op, a.Src, a.Trash)
+	}
+	if a.Dst == "" {
+		return fmt.Sprintf("%s %s", a.Op, a.Src)
+	}
+	return fmt.Sprintf("%s %s %s", a.Op, a.Src, a.Dst)
+}
+
+// command returns the shell command equivalent of the action, used to render it
+func (a Action) command() (string, []string) {
+	if a.Trash != "" {
+		return "mv", []string{"-v", a.Src, a.Trash}
+	}
+
+	switch a.Op {
+	case OpMove:
+		return "mv", []string{"-v", a.Src, a.Dst}
+	case OpRemove:
+		return "rm", []string{"-v", a.Src}
+	case OpRemoveAll:
+		return "rm", []string{"-rfv", a.Src}
+	case OpRemoveDir:
+		return "rmdir", []string{"-v", a.Src}
+	case OpRename:
+		return "mv", []string{a.Src, a.Dst}
+	case OpMkdir:
+		return "mkdir", []string{"-p", a.Src}
+	case OpWrite:
+		fallthrough
+	default:
+		return string(a.Op), []string{a.Src}
+	}
+}
+
+// run carries out a resolved action writing -v style output to w
+func (a Action) run(w io.Writer, progress ProgressFunc) error {
+	if a.Trash != "" {
+		return MovePath(a.Src, a.Trash, w, progress)
+	}
+
+	switch a.Op {
+	case OpMove:
+		return MovePath(a.Src, a.Dst, w, progress)
+	case OpRemove:
+		return RemovePath(a.Src, w)
+	case OpRemoveAll:
+		return RemoveTree(a.Src, w)
+	case OpRemoveDir:
+		return RemoveEmptyDir(a.Src, w)
+	case OpRename, OpMkdir, OpWrite:
+		fallthrough
+	default:
+		return fmt.Errorf("unsupported operation %q", a.Op)
+	}
+}
+
+// progress is called as data is copied by foreground operations
+var progress ProgressFunc
+
+// SetProgress sets a function called with copy progress during cross device moves
+func SetProgress(f ProgressFunc) {
+	progress = f
+}
+
+// recorder is called with every action that has been carried out
+var recorder func(Action)
+
+// SetRecorder sets a function called after each action successfully completes, for moves the destination is the
+// final path of the moved file or folder. It may be called from multiple goroutines
+func SetRecorder(f func(Action)) {
+	recorder = f
+}
+
+func record(a Action) {
+	if recorder != nil {
+		recorder(a)
+	}
+}
+
+// Move moves src to dst with the same semantics as `mv` (a trailing / or existing folder moves into it)
+func Move(indent int, prompt bool, src, dst string) error {
+	return perform(indent, prompt, Action{Op: OpMove, Src: src, Dst: dst})
+}
+
+// Remove deletes a single file, or moves it to the trash if enabled
+func Remove(indent int, prompt bool, path string) error {
+	a, err := deleteAction(OpRemove, path)
+	if err != nil {
+		return err
+	}
+	return perform(indent, prompt, a)
+}
+
+// RemoveAll deletes a file or folder and everything in it, or moves it to the trash if enabled
+func RemoveAll(indent int, prompt bool, path string) error {
+	a, err := deleteAction(OpRemoveAll, path)
+	if err != nil {
+		return err
+	}
+	return perform(indent, prompt, a)
+}
+
+func deleteAction(op OpType, path string) (Action, error) {
+	a := Action{Op: op, Src: path}
+	if TrashEnabled() {
+		trash, err := newTrashPath(path)
+		if err != nil {
+			return a, err
+		}
+		a.Trash = trash
+	}
+	return a, nil
+}
+
+// RemoveDir deletes an empty folder
+func RemoveDir(indent int, prompt bool, path string) error {
+	return perform(indent, prompt, Action{Op: OpRemoveDir, Src: path})
+}
+
+// Rename renames a path in place without prompting or output
+func Rename(oldPath, newPath string) error {
+	a := Action{Op: OpRename, Src: oldPath, Dst: newPath}
+	if planning() {
+		plan.record(a)
+		return nil
+	}
+
+	if err := fsys.Rename(oldPath, newPath); err != nil {
+		return classify(err)
+	}
+
+	record(a)
+	return nil
+}
+
+// MkdirAll creates a folder and any missing parents
+func MkdirAll(path string, perm os.FileMode) error {
+	if PathExists(path) {
+		return nil
+	}
+
+	a := Action{Op: OpMkdir, Src: path}
+	if planning() {
+		plan.record(a)
+		return nil
+	}
+
+	if err := fsys.MkdirAll(path, perm); err != nil {
+		return classify(err)
+	}
+
+	record(a)
+	return nil
+}
+
+// WriteFile replaces the contents of a file
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	a := Action{Op: OpWrite, Src: path}
+	if planning() {
+		plan.record(a)
+		return nil
+	}
+
+	if err := afero.WriteFile(fsys, path, data, perm); err != nil {
+		return classify(err)
+	}
+
+	record(a)
+	return nil
+}
+
+// MoveOutput moves src to dst without prompting and returns the -v style output instead of printing it
+// for use from background workers
+func MoveOutput(src, dst string, progress ProgressFunc) (string, error) {
+	a := Action{Op: OpMove, Src: src, Dst: dst}
+	if err := a.checkSpace(); err != nil {
+		return "", err
+	}
+
+	if planning() {
+		plan.record(a)
+		return fmt.Sprintf("(dry-run) %s", a), nil
+	}
+
+	done := a.resolved()
+	var output bytes.Buffer
+	if err := done.run(&output, progress); err != nil {
+		return output.String(), err
+	}
+
+	record(done)
+	return output.String(), nil
+}
+
+// perform prints, optionally confirms and then runs an action, or records it when dry-running
+func perform(indent int, prompt bool, a Action) error {
+	if err := a.checkSpace(); err != nil {
+		return err
+	}
+
+	command, args := a.command()
+
+	suffix := ""
+	if planning() {
+		suffix = color.Sprintf(" <yellow>(dry-run)</>")
+	}
+
+	y, err := announce(prompt, suffix, command, args...)
+	if err != nil || !y {
+		return err
+	}
+
+	if planning() {
+		plan.record(a)
+		return nil
+	}
+
+	if a.Trash != "" {
+		if err := prepareTrash(a.Src, a.Trash); err != nil {
+			return err
+		}
+	}
+
+	done := a.resolved()
+	iw := IndentWriter{W: os.Stdout, Indent: strings.Repeat(" ", indent)}
+	if err := done.run(iw, progress); err != nil {
+		return err
+	}
+
+	record(done)
+	return nil
+}
+
+// checkSpace makes sure anything the action moves will fit on the destination filesystem
+func (a Action) checkSpace() error {
+	switch {
+	case a.Trash != "":
+		return CheckSpace(a.Src, a.Trash)
+	case a.Op == OpMove:
+		return CheckSpace(a.Src, a.Dst)
+	default:
+		return nil
+	}
+}
+
+// resolved returns the action with the destination of a move set to the final path using mv semantics
+// (a trailing / or existing folder moves into it), must be called before the action runs
+func (a Action) resolved() Action {
+	if a.Op != OpMove {
+		return a
+	}
+
+	if info, err := fsys.Stat(a.Dst); strings.HasSuffix(a.Dst, "/") || (err == nil && info.IsDir()) {
+		a.Dst = filepath.Join(a.Dst, filepath.Base(a.Src))
+	} else {
+		a.Dst = filepath.Clean(a.Dst)
+	}
+
+	return a
+}
@@ -0,0 +1,287 @@
+package ktio
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gookit/color"
+)
+
+// planner records actions instead of running them and keeps an overlay of the planned changes so later
+// lookups (PathExists, ListFolders, etc) see the filesystem as it would be after the actions ran
+type planner struct {
+	mu      sync.Mutex
+	actions []Action
+	nodes   map[string]planNode // planned path -> what is there now
+}
+
+// planNode is a path changed by a planned action
+type planNode struct {
+	removed bool
+	real    string // real path backing this one ("" for a planned mkdir)
+	dir     bool
+}
+
+// plan is the active planner, nil unless dry-run has been enabled
+var plan *planner
+
+// EnableDryRun routes all filesystem operations through the planner instead of running them
+func EnableDryRun() {
+	plan = &planner{nodes: map[string]planNode{}}
+}
+
+// DisableDryRun runs filesystem operations again, dropping anything that was planned
+func DisableDryRun() {
+	plan = nil
+}
+
+// DryRun returns true if filesystem operations are being planned rather than run
+func DryRun() bool {
+	return planning()
+}
+
+func planning() bool {
+	return plan != nil
+}
+
+// PlannedActions returns the ordered list of actions recorded while dry-running
+func PlannedActions() []Action {
+	if !planning() {
+		return nil
+	}
+
+	plan.mu.Lock()
+	defer plan.mu.Unlock()
+
+	return append([]Action{}, plan.actions...)
+}
+
+// PrintPlan prints the ordered list of planned actions
+func PrintPlan() {
+	actions := PlannedActions()
+
+	fmt.Println()
+	color.Printf("<cyan>DRY RUN PLAN:</> <white>%d</> actions\n", len(actions))
+	for i, a := range actions {
+		command, args := a.command()
+		color.Printf("  <darkGray>%4d</> <yellow>%s</> %s\n", i+1, command, strings.Join(args, " "))
+	}
+}
+
+// WritePlan writes the planned actions to a file as json
+func WritePlan(path string) error {
+	data, err := json.MarshalIndent(PlannedActions(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding plan: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("error writing plan file: %w", err)
+	}
+
+	return nil
+}
+
+func (p *planner) record(a Action) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.actions = append(p.actions, a)
+
+	switch a.Op {
+	case OpMove:
+		p.move(a.Src, p.target(a.Src, a.Dst))
+	case OpRename:
+		p.move(a.Src, filepath.Clean(a.Dst))
+	case OpRemove, OpRemoveAll, OpRemoveDir:
+		p.set(filepath.Clean(a.Src), planNode{removed: true})
+	case OpMkdir:
+		// parents first as setting a node clears everything planned below it
+		var missing []string
+		for dir := filepath.Clean(a.Src); !p.exists(dir); dir = filepath.Dir(dir) {
+			missing = append(missing, dir)
+		}
+		for i := len(missing) - 1; i >= 0; i-- {
+			p.set(missing[i], planNode{dir: true})
+		}
+	case OpWrite:
+		// contents only, nothing changes in the tree
+	}
+}
+
+// target is moveTarget without taking the lock again
+func (p *planner) target(src, dst string) string {
+	if strings.HasSuffix(dst, "/") {
+		return filepath.Join(dst, filepath.Base(src))
+	}
+	if info, err := p.stat(dst); err == nil && info.dir {
+		return filepath.Join(dst, filepath.Base(src))
+	}
+	return filepath.Clean(dst)
+}
+
+func (p *planner) move(src, dst string) {
+	src = filepath.Clean(src)
+
+	info, err := p.stat(src)
+	if err != nil {
+		// moving something that doesn't exist will fail for real, nothing to track
+		return
+	}
+
+	p.set(src, planNode{removed: true})
+	p.set(dst, planNode{real: info.real, dir: info.dir})
+}
+
+// set records a node, replacing anything planned below it
+func (p *planner) set(path string, n planNode) {
+	prefix := path + string(filepath.Separator)
+	for k := range p.nodes {
+		if strings.HasPrefix(k, prefix) {
+			delete(p.nodes, k)
+		}
+	}
+	p.nodes[path] = n
+}
+
+// resolve returns the real path backing a planned path, ok is false if it has been removed
+// virtual is true if the path is inside a planned mkdir and has no real path
+func (p *planner) resolve(path string) (backing string, virtual bool, ok bool) {
+	path = filepath.Clean(path)
+
+	// the deepest planned node wins as planning a node clears everything below it
+	for cur := path; ; cur = filepath.Dir(cur) {
+		if n, found := p.nodes[cur]; found {
+			switch {
+			case n.removed:
+				return "", false, false
+			case n.real == "":
+				return "", true, cur == path
+			default:
+				return filepath.Join(n.real, strings.TrimPrefix(path, cur)), false, true
+			}
+		}
+
+		if parent := filepath.Dir(cur); parent == cur {
+			return path, false, true
+		}
+	}
+}
+
+// planStat is the planned state of a single path
+type planStat struct {
+	real string
+	dir  bool
+}
+
+func (p *planner) stat(path string) (*planStat, error) {
+	backing, virtual, ok := p.resolve(path)
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	if virtual {
+		return &planStat{dir: true}, nil
+	}
+
+	info, err := fsys.Stat(backing)
+	if err != nil {
+		return nil, err
+	}
+
+	return &planStat{real: backing, dir: info.IsDir()}, nil
+}
+
+func (p *planner) exists(path string) bool {
+	_, err := p.stat(path)
+	return err == nil
+}
+
+// dirEntry is a minimal directory entry that works for both real and planned paths
+type dirEntry struct {
+	name string
+	dir  bool
+}
+
+func (p *planner) readDir(path string) ([]dirEntry, error) {
+	path = filepath.Clean(path)
+
+	info, err := p.stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.dir {
+		return nil, fmt.Errorf("%s: not a directory", path)
+	}
+
+	entries := map[string]dirEntry{}
+	if info.real != "" {
+		files, err := readDirEntries(info.real)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			entries[f.name] = f
+		}
+	}
+
+	// drop removed and moved away entries, add planned ones
+	for k, n := range p.nodes {
+		if filepath.Dir(k) != path {
+			continue
+		}
+
+		name := filepath.Base(k)
+		if n.removed {
+			delete(entries, name)
+		} else {
+			entries[name] = dirEntry{name: name, dir: n.dir}
+		}
+	}
+
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]dirEntry, 0, len(names))
+	for _, name := range names {
+		result = append(result, entries[name])
+	}
+
+	return result, nil
+}
+
+// readDir lists a folder, taking any planned actions into account when dry-running
+func readDir(path string) ([]dirEntry, error) {
+	if planning() {
+		plan.mu.Lock()
+		defer plan.mu.Unlock()
+		return plan.readDir(path)
+	}
+
+	return readDirEntries(path)
+}
+
+// RealPath returns the path on disk that currently holds the given path, when dry-running a planned move
+// means the content is still at its original location
+func RealPath(path string) string {
+	if !planning() {
+		return path
+	}
+
+	plan.mu.Lock()
+	defer plan.mu.Unlock()
+
+	backing, _, ok := plan.resolve(path)
+	if !ok || backing == "" {
+		return path
+	}
+	return backing
+}
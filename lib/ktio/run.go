@@ -2,41 +2,27 @@ package ktio
 
 import (
 	"fmt"
-	"os"
-	"os/exec"
 	"strings"
 
 	"github.com/gookit/color"
 )
 
-func RunCommand(indent int, prompt bool, command string, args ...string) error {
-	color.Printf("  <darkGray>%s %s</>", command, strings.Join(args, " "))
+// announce prints the command about to be run and asks for confirmation if prompt is set
+// returns false if the user declined
+func announce(prompt bool, suffix string, command string, args ...string) (bool, error) {
+	color.Printf("  <darkGray>%s %s</>%s", command, strings.Join(args, " "), suffix)
 
-	cmd := exec.Command(command, args...) //nolint:gosec
-
-	if prompt {
-		color.Printf(" <lightYellow> CONFIRM y/n: </>")
-		y, err := Confirm()
-		fmt.Println()
-		if err != nil {
-			return err
-		}
-		if !y {
-			return nil
-		}
-	} else {
+	if !prompt {
 		fmt.Println()
+		return true, nil
 	}
 
-	iw := IndentWriter{W: os.Stdout, Indent: strings.Repeat(" ", indent)}
-	cmd.Stdout = iw
-	cmd.Stderr = iw
-
-	// Run the command
-	err := cmd.Run()
+	color.Printf(" <lightYellow> CONFIRM y/n: </>")
+	y, err := Confirm()
+	fmt.Println()
 	if err != nil {
-		return fmt.Errorf("error running command: %w", err)
+		return false, err
 	}
 
-	return nil
+	return y, nil
 }
@@ -0,0 +1,115 @@
+package ktio
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"golang.org/x/sys/unix"
+)
+
+// ErrInsufficientSpace is returned when a move would not fit on the destination filesystem
+var ErrInsufficientSpace = errors.New("not enough free space on the destination")
+
+// spaceMargin is the number of bytes that must remain free on the destination after a move
+var spaceMargin int64
+
+// SetSpaceMargin sets the number of bytes that must remain free on the destination filesystem after a move
+func SetSpaceMargin(bytes int64) {
+	spaceMargin = bytes
+}
+
+// FreeSpace returns the bytes available on the filesystem holding path (or its nearest existing parent)
+func FreeSpace(path string) (int64, error) {
+	if !realFS() {
+		return math.MaxInt64, nil // nothing to run out of
+	}
+
+	var st unix.Statfs_t
+	if err := unix.Statfs(closestExisting(path), &st); err != nil {
+		return 0, fmt.Errorf("error getting free space for %s: %w", path, err)
+	}
+
+	return int64(st.Bavail) * st.Bsize, nil //nolint:gosec
+}
+
+// DeviceID returns the id of the filesystem holding path (or its nearest existing parent)
+func DeviceID(path string) (uint64, error) {
+	if !realFS() {
+		return 0, nil // a single device
+	}
+
+	var st unix.Stat_t
+	if err := unix.Stat(closestExisting(path), &st); err != nil {
+		return 0, fmt.Errorf("error getting filesystem for %s: %w", path, err)
+	}
+
+	return st.Dev, nil
+}
+
+// SameDevice returns true if both paths (or their nearest existing parents) are on the same filesystem
+func SameDevice(a, b string) bool {
+	da, err := DeviceID(a)
+	if err != nil {
+		return false
+	}
+	db, err := DeviceID(b)
+	if err != nil {
+		return false
+	}
+
+	return da == db
+}
+
+// MoveCost returns the bytes that will land on the destination filesystem when moving src to dst,
+// a same device rename costs nothing while a cross device move costs the full size of src
+func MoveCost(src, dst string) (int64, error) {
+	src = RealPath(src)
+	if SameDevice(src, dst) {
+		return 0, nil
+	}
+
+	size, err := treeSize(src)
+	if err != nil {
+		return 0, fmt.Errorf("error calculating size of %s: %w", src, err)
+	}
+
+	return size, nil
+}
+
+// CheckSpace returns ErrInsufficientSpace if moving src to dst would leave less than the margin free
+func CheckSpace(src, dst string) error {
+	need, err := MoveCost(src, dst)
+	if err != nil {
+		return err
+	}
+	if need == 0 {
+		return nil
+	}
+
+	return CheckFree(dst, need)
+}
+
+// CheckFree returns ErrInsufficientSpace if writing need bytes to the filesystem of path would leave less than the margin free
+func CheckFree(path string, need int64) error {
+	free, err := FreeSpace(path)
+	if err != nil {
+		return err
+	}
+
+	if need+spaceMargin > free {
+		return fmt.Errorf("%w: need %s (+%s margin), %s free", ErrInsufficientSpace, FormatBytes(need), FormatBytes(spaceMargin), FormatBytes(free))
+	}
+
+	return nil
+}
+
+// FormatBytes formats a byte count as GB or MB
+func FormatBytes(bytes int64) string {
+	gb := float64(bytes) / 1024 / 1024 / 1024
+	if gb >= 1 {
+		return fmt.Sprintf("%.2f GB", gb)
+	}
+	mb := float64(bytes) / 1024 / 1024
+	return fmt.Sprintf("%.0f MB", mb)
+}
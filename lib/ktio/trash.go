@@ -0,0 +1,229 @@
+package ktio
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+	"golang.org/x/sys/unix"
+)
+
+// TrashInfo is the metadata written next to every trashed item
+type TrashInfo struct {
+	ID       string    `json:"id"`
+	Original string    `json:"original"` // where the item was deleted from
+	Deleted  time.Time `json:"deleted"`
+	Session  string    `json:"session,omitempty"`
+
+	Trash string `json:"-"` // the trash folder the item is in
+	Path  string `json:"-"` // where the item is now
+}
+
+// trashDirs are the configured trash folders, deletes go to the one on the same filesystem as the deleted path
+var trashDirs []string
+
+// trashSession is recorded in the metadata of trashed items
+var trashSession string
+
+// EnableTrash makes Remove and RemoveAll move items into a trash folder instead of deleting them
+func EnableTrash(dirs []string, session string) {
+	trashDirs = dirs
+	trashSession = session
+}
+
+// TrashEnabled returns true if deletes are moved to the trash
+func TrashEnabled() bool {
+	return len(trashDirs) > 0
+}
+
+// TrashDirs returns the configured trash folders
+func TrashDirs() []string {
+	return trashDirs
+}
+
+// trashDirFor returns the trash folder on the same filesystem as path, moving across filesystems would turn a delete
+// into a full copy so it is an error if there is none
+func trashDirFor(path string) (string, error) {
+	if !realFS() {
+		return trashDirs[0], nil
+	}
+
+	var st unix.Stat_t
+	if err := unix.Stat(RealPath(path), &st); err != nil {
+		return "", fmt.Errorf("error checking the filesystem of %s: %w", path, err)
+	}
+
+	for _, dir := range trashDirs {
+		var tst unix.Stat_t
+		if err := unix.Stat(closestExisting(dir), &tst); err == nil && tst.Dev == st.Dev {
+			return dir, nil
+		}
+	}
+
+	return "", fmt.Errorf("no trash folder on the same filesystem as %s (add one to trash in the config or pass --no-trash)", path)
+}
+
+// closestExisting returns the path or its nearest existing parent so a trash folder that has not been created yet
+// can still be matched to a filesystem
+func closestExisting(path string) string {
+	for {
+		if _, err := fsys.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
+
+// newTrashPath returns where a path will be moved to in the trash
+func newTrashPath(path string) (string, error) {
+	dir, err := trashDirFor(path)
+	if err != nil {
+		return "", err
+	}
+
+	b := make([]byte, 3)
+	_, _ = rand.Read(b)
+	id := time.Now().Format("20060102-150405") + "-" + hex.EncodeToString(b)
+
+	return filepath.Join(dir, id, filepath.Base(path)), nil
+}
+
+// prepareTrash creates the trash item folder and writes its metadata
+func prepareTrash(original, trashPath string) error {
+	itemDir := filepath.Dir(trashPath)
+	if err := fsys.MkdirAll(itemDir, 0o750); err != nil {
+		return fmt.Errorf("error creating trash folder: %w", err)
+	}
+
+	info := TrashInfo{
+		ID:       filepath.Base(itemDir),
+		Original: original,
+		Deleted:  time.Now(),
+		Session:  trashSession,
+	}
+
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding trash metadata: %w", err)
+	}
+
+	if err := afero.WriteFile(fsys, itemDir+".json", data, 0o600); err != nil {
+		return fmt.Errorf("error writing trash metadata: %w", err)
+	}
+
+	return nil
+}
+
+// ListTrash returns all items in the configured trash folders, oldest first
+func ListTrash() ([]TrashInfo, error) {
+	var items []TrashInfo
+
+	for _, dir := range trashDirs {
+		files, err := afero.Glob(fsys, filepath.Join(dir, "*.json"))
+		if err != nil {
+			return nil, fmt.Errorf("error listing trash %s: %w", dir, err)
+		}
+
+		for _, file := range files {
+			data, err := afero.ReadFile(fsys, file)
+			if err != nil {
+				return nil, fmt.Errorf("error reading trash metadata: %w", err)
+			}
+
+			var info TrashInfo
+			if err := json.Unmarshal(data, &info); err != nil {
+				return nil, fmt.Errorf("error parsing trash metadata %s: %w", file, err)
+			}
+
+			info.Trash = dir
+			info.Path = filepath.Join(strings.TrimSuffix(file, ".json"), filepath.Base(info.Original))
+			items = append(items, info)
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Deleted.Before(items[j].Deleted)
+	})
+
+	return items, nil
+}
+
+// FindTrash returns the trash item with the given id
+func FindTrash(id string) (*TrashInfo, error) {
+	items, err := ListTrash()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		if item.ID == id {
+			return &item, nil
+		}
+	}
+
+	return nil, fmt.Errorf("trash item %q not found", id)
+}
+
+// RestoreFromTrash moves a trashed item back to dst and cleans up its trash folder and metadata
+func RestoreFromTrash(indent int, prompt bool, trashPath, dst string) error {
+	if !PathExists(trashPath) {
+		return fmt.Errorf("%s is no longer in the trash", trashPath)
+	}
+	if PathExists(dst) {
+		return fmt.Errorf("%s already exists", dst)
+	}
+
+	if err := MkdirAll(filepath.Dir(dst), 0o750); err != nil {
+		return fmt.Errorf("error recreating folder: %w", err)
+	}
+
+	if err := Move(indent, prompt, trashPath, dst); err != nil {
+		return err
+	}
+	if !PathExists(dst) {
+		return nil // declined
+	}
+
+	return removeTrashItem(filepath.Dir(trashPath))
+}
+
+// PurgeTrash permanently deletes a trashed item and its metadata
+func PurgeTrash(indent int, prompt bool, item TrashInfo) error {
+	itemDir := filepath.Dir(item.Path)
+
+	if err := perform(indent, prompt, Action{Op: OpRemoveAll, Src: itemDir}); err != nil {
+		return err
+	}
+	if PathExists(itemDir) {
+		return nil // declined
+	}
+
+	return removeTrashItem(itemDir)
+}
+
+// removeTrashItem removes the metadata and (now empty) folder of a trash item
+func removeTrashItem(itemDir string) error {
+	if planning() {
+		return nil
+	}
+
+	if err := remove(itemDir + ".json"); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error removing trash metadata: %w", err)
+	}
+	if err := remove(itemDir); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error removing trash folder: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,272 @@
+package probecache
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Cache is a persistent sqlite store of ffprobe output so unchanged files are not probed again. The database is only
+// opened the first time it is used
+type Cache struct {
+	db   *sql.DB
+	path string
+
+	once    sync.Once
+	openErr error
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// Key identifies a file's contents, an entry is only used while the file still has the same size, mtime and inode
+type Key struct {
+	Path    string
+	Size    int64
+	ModTime int64 // unix nanoseconds
+	Inode   uint64
+}
+
+// Stats summarises the cache contents
+type Stats struct {
+	Path      string
+	Entries   int
+	FileBytes int64 // total size of the probed files
+	DBBytes   int64 // size of the cache database on disk
+	Oldest    time.Time
+	Newest    time.Time
+}
+
+const schema = `
+CREATE TABLE IF NOT EXISTS probes (
+	path    TEXT PRIMARY KEY,
+	size    INTEGER NOT NULL,
+	mtime   INTEGER NOT NULL,
+	inode   INTEGER NOT NULL,
+	probed  TEXT NOT NULL,
+	output  BLOB NOT NULL
+);
+`
+
+// DefaultPath returns the default cache location in the users cache folder
+func DefaultPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("error finding cache directory: %w", err)
+	}
+
+	return filepath.Join(dir, "go-ingest-media", "ffprobe.db"), nil
+}
+
+// New returns a cache for the database at path without opening it, it is opened (and created if needed) on first use
+func New(path string) *Cache {
+	return &Cache{path: path}
+}
+
+// Open opens (creating if needed) the cache database at path
+func Open(path string) (*Cache, error) {
+	c := New(path)
+	if err := c.Open(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Open opens the database if it has not been already, returning the error from the first attempt on every call
+func (c *Cache) Open() error {
+	c.once.Do(func() {
+		c.openErr = c.open()
+	})
+
+	return c.openErr
+}
+
+func (c *Cache) open() error {
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o750); err != nil {
+		return fmt.Errorf("error creating cache folder: %w", err)
+	}
+
+	db, err := sql.Open("sqlite3", c.path+"?_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		return fmt.Errorf("error opening probe cache %s: %w", c.path, err)
+	}
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return fmt.Errorf("error creating probe cache tables: %w", err)
+	}
+
+	c.db = db
+	return nil
+}
+
+// Close closes the cache database if it was opened
+func (c *Cache) Close() error {
+	if c.db == nil {
+		return nil
+	}
+	return c.db.Close()
+}
+
+// KeyFor returns the cache key for a file from its stat info
+func KeyFor(path string, info os.FileInfo) Key {
+	k := Key{
+		Path:    path,
+		Size:    info.Size(),
+		ModTime: info.ModTime().UnixNano(),
+	}
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		k.Inode = st.Ino
+	}
+
+	return k
+}
+
+// Matches reports if a stored key still describes the file at cur. An inode of 0 is unknown (ie some network mounts)
+// and matches any inode
+func (k Key) Matches(cur Key) bool {
+	if k.Size != cur.Size || k.ModTime != cur.ModTime {
+		return false
+	}
+	return k.Inode == 0 || cur.Inode == 0 || k.Inode == cur.Inode
+}
+
+// Get returns the cached output for key, ok is false if there is no entry or the file has changed since it was probed
+func (c *Cache) Get(key Key) ([]byte, bool, error) {
+	if err := c.Open(); err != nil {
+		return nil, false, err
+	}
+
+	stored := Key{Path: key.Path}
+	var output []byte
+
+	err := c.db.QueryRow(`SELECT size, mtime, inode, output FROM probes WHERE path = ?`, key.Path).Scan(&stored.Size, &stored.ModTime, &stored.Inode, &output)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.misses.Add(1)
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("error reading probe cache for %s: %w", key.Path, err)
+	}
+
+	if !stored.Matches(key) {
+		c.misses.Add(1)
+		return nil, false, nil
+	}
+
+	c.hits.Add(1)
+	return output, true, nil
+}
+
+// Put stores the output for key replacing any previous entry for the path
+func (c *Cache) Put(key Key, output []byte) error {
+	if err := c.Open(); err != nil {
+		return err
+	}
+
+	_, err := c.db.Exec(`INSERT OR REPLACE INTO probes (path, size, mtime, inode, probed, output) VALUES (?, ?, ?, ?, ?, ?)`,
+		key.Path, key.Size, key.ModTime, key.Inode, time.Now().Format(time.RFC3339), output)
+	if err != nil {
+		return fmt.Errorf("error writing probe cache for %s: %w", key.Path, err)
+	}
+
+	return nil
+}
+
+// Hits returns the number of lookups answered from the cache since it was opened
+func (c *Cache) Hits() int64 {
+	return c.hits.Load()
+}
+
+// Misses returns the number of lookups that had to be probed since it was opened
+func (c *Cache) Misses() int64 {
+	return c.misses.Load()
+}
+
+// Stats returns a summary of the cache contents
+func (c *Cache) Stats() (*Stats, error) {
+	if err := c.Open(); err != nil {
+		return nil, err
+	}
+
+	s := Stats{Path: c.path}
+
+	var oldest, newest sql.NullString
+	err := c.db.QueryRow(`SELECT COUNT(*), COALESCE(SUM(size), 0), MIN(probed), MAX(probed) FROM probes`).Scan(&s.Entries, &s.FileBytes, &oldest, &newest)
+	if err != nil {
+		return nil, fmt.Errorf("error reading probe cache stats: %w", err)
+	}
+	s.Oldest, _ = time.Parse(time.RFC3339, oldest.String)
+	s.Newest, _ = time.Parse(time.RFC3339, newest.String)
+
+	// the wal holds recent writes until it is checkpointed
+	for _, p := range []string{c.path, c.path + "-wal"} {
+		if info, err := os.Stat(p); err == nil {
+			s.DBBytes += info.Size()
+		}
+	}
+
+	return &s, nil
+}
+
+// Prune removes entries for files that no longer exist or have changed since they were probed, returning how many were removed
+func (c *Cache) Prune() (int, error) {
+	if err := c.Open(); err != nil {
+		return 0, err
+	}
+
+	rows, err := c.db.Query(`SELECT path, size, mtime, inode FROM probes`)
+	if err != nil {
+		return 0, fmt.Errorf("error listing probe cache: %w", err)
+	}
+
+	var stale []string
+	for rows.Next() {
+		var k Key
+		if err := rows.Scan(&k.Path, &k.Size, &k.ModTime, &k.Inode); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("error reading probe cache: %w", err)
+		}
+
+		info, err := os.Stat(k.Path)
+		if errors.Is(err, fs.ErrNotExist) {
+			stale = append(stale, k.Path)
+			continue
+		}
+		if err != nil {
+			// unreadable right now (ie an nfs hiccup) is not the same as gone
+			continue
+		}
+		if !k.Matches(KeyFor(k.Path, info)) {
+			stale = append(stale, k.Path)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error reading probe cache: %w", err)
+	}
+
+	for _, p := range stale {
+		if _, err := c.db.Exec(`DELETE FROM probes WHERE path = ?`, p); err != nil {
+			return 0, fmt.Errorf("error pruning probe cache entry %s: %w", p, err)
+		}
+	}
+
+	if len(stale) > 0 {
+		if _, err := c.db.Exec(`VACUUM`); err != nil {
+			return len(stale), fmt.Errorf("error compacting probe cache: %w", err)
+		}
+	}
+
+	return len(stale), nil
+}
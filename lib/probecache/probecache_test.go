@@ -0,0 +1,89 @@
+package probecache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestKeyMatches(t *testing.T) {
+	stored := Key{Size: 10, ModTime: 20, Inode: 30}
+
+	cases := []struct {
+		name string
+		cur  Key
+		want bool
+	}{
+		{"same", Key{Size: 10, ModTime: 20, Inode: 30}, true},
+		{"size changed", Key{Size: 11, ModTime: 20, Inode: 30}, false},
+		{"mtime changed", Key{Size: 10, ModTime: 21, Inode: 30}, false},
+		{"inode changed", Key{Size: 10, ModTime: 20, Inode: 31}, false},
+		{"inode unknown", Key{Size: 10, ModTime: 20}, true},
+	}
+
+	for _, tc := range cases {
+		if got := stored.Matches(tc.cur); got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+
+	if !(Key{Size: 10, ModTime: 20}).Matches(stored) {
+		t.Error("a stored inode of 0 should match any inode")
+	}
+}
+
+func TestPruneKeepsUnknownInodes(t *testing.T) {
+	dir := t.TempDir()
+	c := New(filepath.Join(dir, "cache", "ffprobe.db"))
+	t.Cleanup(func() { c.Close() })
+
+	video := filepath.Join(dir, "video.mkv")
+	if err := os.WriteFile(video, []byte("video"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(video)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// stored from a mount that doesn't report inodes
+	key := KeyFor(video, info)
+	key.Inode = 0
+	if err := c.Put(key, []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Put(Key{Path: filepath.Join(dir, "gone.mkv"), Size: 1}, []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok, err := c.Get(KeyFor(video, info)); err != nil || !ok {
+		t.Fatalf("get: ok %v err %v, want a hit", ok, err)
+	}
+
+	n, err := c.Prune()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("pruned %d, want only the missing file", n)
+	}
+	if _, ok, _ := c.Get(KeyFor(video, info)); !ok {
+		t.Error("the entry with an unknown inode was pruned")
+	}
+}
+
+func TestOpenIsLazy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "ffprobe.db")
+	c := New(path)
+	defer c.Close()
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("database exists before first use: %v", err)
+	}
+	if _, _, err := c.Get(Key{Path: "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("database not created on first use: %v", err)
+	}
+}